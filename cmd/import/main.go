@@ -0,0 +1,123 @@
+// Command import creates user accounts from a CSV or XLSX file through the
+// BulkImportUsers RPC.
+//
+//	import -addr localhost:44044 -file students.xlsx -dry-run
+//
+// The access token of an admin is read from -token or the AUTH_TOKEN
+// environment variable. The command exits with status 1 when any row is
+// invalid.
+package main
+
+import (
+	"AuthService/internal/pb"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// chunkSize is the size of the file chunks sent to the server.
+const chunkSize = 64 << 10
+
+func main() {
+	var (
+		addr    = flag.String("addr", "localhost:44044", "address of the gRPC server")
+		token   = flag.String("token", os.Getenv("AUTH_TOKEN"), "access token of an admin")
+		file    = flag.String("file", "", "CSV or XLSX file to import")
+		format  = flag.String("format", "", "file format, csv or xlsx; taken from the file extension by default")
+		dryRun  = flag.Bool("dry-run", false, "only check the rows")
+		school  = flag.Int64("school", 0, "school to import into; for super admins")
+		timeout = flag.Duration("timeout", 5*time.Minute, "timeout of the import")
+	)
+	flag.Parse()
+
+	if *file == "" || *token == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	resp, err := run(*addr, *token, *file, *format, *dryRun, *school, *timeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, r := range resp.Results {
+		if r.Status == "invalid" {
+			fmt.Printf("line %d\t%s\t%s\t%s\n", r.Line, r.Email, r.Status, strings.Join(r.Errors, "; "))
+			continue
+		}
+		fmt.Printf("line %d\t%s\t%s\t%d\n", r.Line, r.Email, r.Status, r.UserId)
+	}
+
+	if resp.DryRun {
+		fmt.Printf("dry run: %d valid, %d invalid\n", int64(len(resp.Results))-resp.Invalid, resp.Invalid)
+	} else {
+		fmt.Printf("%d created, %d invalid\n", resp.Created, resp.Invalid)
+	}
+
+	if resp.Invalid > 0 {
+		os.Exit(1)
+	}
+}
+
+func run(addr, token, file, format string, dryRun bool, school int64, timeout time.Duration) (*pb.BulkImportUsersResponse, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect due to error: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	if school != 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-school-id", fmt.Sprint(school))
+	}
+
+	stream, err := pb.NewUserServiceClient(conn).BulkImportUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pb.BulkImportUsersRequest{Format: format, DryRun: dryRun}
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			req.Data = buf[:n]
+			if err := stream.Send(req); err != nil {
+				break // CloseAndRecv returns the error of the server.
+			}
+			req = &pb.BulkImportUsersRequest{}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
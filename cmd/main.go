@@ -14,23 +14,39 @@ import (
 func main() {
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		fmt.Errorf("failed to load config due to error: %w", err)
+		panic(fmt.Errorf("failed to load config due to error: %w", err))
 	}
 
 	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	jwt := jwt.JwtWrapper{
-		SecretKey:       cfg.JWTSecretKey,
-		Issuer:          "go-grpc-auth-svc",
-		ExpirationHours: 24 * 180,
+	wrapper := jwt.JwtWrapper{
+		SecretKey:         cfg.JWTSecretKey,
+		Issuer:            cfg.JWTIssuer,
+		Audience:          cfg.JWTAudience,
+		AllowedAudiences:  cfg.JWTAllowedAuds,
+		AllowedAlgorithms: cfg.JWTAllowedAlgs,
+		Leeway:            cfg.JWTLeeway,
+		TokenTTL:          cfg.AccessTokenTTL,
 	}
 
-	application := app.New(log, jwt, cfg.Port, cfg.DBUrl)
+	if cfg.JWTKeysDir != "" {
+		keys, err := jwt.LoadKeyRing(cfg.JWTKeysDir, cfg.JWTKeyOverlap)
+		if err != nil {
+			panic(fmt.Errorf("failed to load jwt keys due to error: %w", err))
+		}
+		wrapper.Keys = keys
+	}
+
+	application := app.New(log, wrapper, cfg)
 
 	go func() {
 		application.GRPCServer.MustRun()
 	}()
 
+	go func() {
+		application.HTTPServer.MustRun()
+	}()
+
 	log.Info("server is running")
 
 	stop := make(chan os.Signal, 1)
@@ -38,5 +54,5 @@ func main() {
 
 	<-stop
 
-	application.GRPCServer.Stop()
+	application.Stop()
 }
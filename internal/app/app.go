@@ -2,28 +2,169 @@ package app
 
 import (
 	"AuthService/internal/app/grpc"
+	"AuthService/internal/app/http"
+	"AuthService/internal/config"
+	"AuthService/internal/services/agepolicy"
 	"AuthService/internal/services/auth"
+	"AuthService/internal/services/class"
+	"AuthService/internal/services/exports"
+	"AuthService/internal/services/guardian"
+	"AuthService/internal/services/imports"
+	"AuthService/internal/services/lockout"
+	"AuthService/internal/services/rbac"
+	"AuthService/internal/services/school"
 	"AuthService/internal/services/user"
+	"AuthService/internal/storage/cache"
 	"AuthService/internal/storage/mysql"
+	"AuthService/pkg/tools/hash"
 	"AuthService/pkg/tools/jwt"
+	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/mailer"
+	"AuthService/pkg/tools/passpolicy"
+	"context"
+	"fmt"
 	"log/slog"
+	"time"
 )
 
 type App struct {
 	GRPCServer *grpc.GRPCApp
+	HTTPServer *http.HTTPApp
+	storage    *mysql.StDb
+	cancel     context.CancelFunc
 }
 
-func New(log *slog.Logger, wrapper jwt.JwtWrapper, port int, storagePath string) *App {
-	storage, err := mysql.New(storagePath)
+func New(log *slog.Logger, wrapper jwt.JwtWrapper, cfg *config.Config) *App {
+	storage, err := mysql.New(cfg.DBUrl)
 
 	if err != nil {
 		panic(err)
 	}
 
-	authService := auth.New(wrapper, storage, storage, storage, storage, log)
-	userService := user.New(log, storage, storage, storage)
+	ctx, cancel := context.WithCancel(context.Background())
 
-	grpcApp := grpc.NewGRPCApp(log, authService, userService, port)
+	revocations := cache.NewRevocations(log, storage, cfg.AccessTokenTTL+cfg.JWTLeeway)
+	if err = revocations.Load(ctx); err != nil {
+		panic(err)
+	}
+	go revocations.Run(ctx, cfg.RevocationSync)
+
+	if wrapper.Keys != nil {
+		go wrapper.Keys.Run(ctx, cfg.JWTKeyReload, func(err error) {
+			log.Error("failed to reload jwt keys", sl.Err(err))
+		})
+	}
+
+	authCfg := auth.Config{
+		RefreshTokenTTL:      cfg.RefreshTokenTTL,
+		PasswordResetTTL:     cfg.PasswordResetTTL,
+		PasswordResetURL:     cfg.PasswordResetURL,
+		EmailVerificationTTL: cfg.EmailVerificationTTL,
+		EmailVerificationURL: cfg.EmailVerificationURL,
+		AllowUnverifiedLogin: cfg.AllowUnverifiedLogin,
+		MFAIssuer:            cfg.MFAIssuer,
+		MFAChallengeTTL:      cfg.MFAChallengeTTL,
+		PasswordHistorySize:  cfg.PasswordHistorySize,
+	}
+
+	hasher, err := hash.New(hash.Params{
+		Algorithm:         cfg.PasswordHashAlgorithm,
+		BcryptCost:        cfg.BcryptCost,
+		Argon2Memory:      cfg.Argon2Memory,
+		Argon2Time:        cfg.Argon2Time,
+		Argon2Parallelism: cfg.Argon2Parallelism,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	commonPasswords, err := passpolicy.LoadCommonPasswords(cfg.PasswordBlocklist)
+	if err != nil {
+		panic(err)
+	}
+	policy := passpolicy.New(cfg.PasswordMinLength, cfg.PasswordMinClasses, commonPasswords)
+
+	limiter := lockout.New(log, newAttemptStore(ctx, cfg, storage), lockout.Config{
+		Account: lockout.Policy{
+			Threshold:   cfg.LockoutThreshold,
+			Lockout:     cfg.LockoutDuration,
+			BackoffBase: cfg.LoginBackoffBase,
+			BackoffMax:  cfg.LoginBackoffMax,
+		},
+		// Addresses are often shared by a whole school, so they are only
+		// throttled after many failures and never backed off.
+		IP: lockout.Policy{
+			Threshold: cfg.IPThrottleLimit,
+			Lockout:   cfg.IPThrottleDuration,
+		},
+		Mail: lockout.Quota{
+			Limit:  cfg.MailRateLimit,
+			Window: cfg.MailRateWindow,
+		},
+	})
+
+	go limiter.Run(ctx, time.Hour)
+
+	rbacService := rbac.New(log, storage)
+
+	mailSender := newMailer(cfg)
+
+	agePolicy := agepolicy.New(cfg.GuardianConsentAge)
+
+	authService := auth.New(wrapper, authCfg, hasher, policy, storage, storage, storage, storage, rbacService, storage, revocations, storage, storage, storage, storage, storage, limiter, mailSender, log)
+	userService := user.New(log, storage, storage, storage, rbacService, agePolicy)
+	classService := class.New(log, storage, rbacService, storage)
+	guardianService := guardian.New(log, storage, rbacService, cfg.GuardianInviteTTL, agePolicy)
+	schoolService := school.New(log, storage, rbacService)
+	importService := imports.New(log, storage, hasher, rbacService, mailSender, imports.Config{
+		InviteTTL: cfg.ImportInviteTTL,
+		InviteURL: cfg.PasswordResetURL,
+	}, agePolicy)
+	exportService := exports.New(log, storage, rbacService)
+
+	grpcApp := grpc.NewGRPCApp(log, authService, userService, rbacService, classService, guardianService, schoolService, importService, exportService, cfg.Port)
+	httpApp := http.NewHTTPApp(log, authService, cfg.HTTPPort)
+
+	return &App{GRPCServer: grpcApp, HTTPServer: httpApp, storage: storage, cancel: cancel}
+}
+
+func newMailer(cfg *config.Config) mailer.Mailer {
+	switch cfg.Mailer {
+	case "smtp":
+		return &mailer.SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}
+	case "memory":
+		return &mailer.MemoryMailer{}
+	case "file":
+		return &mailer.FileMailer{Dir: cfg.MailDir, From: cfg.MailFrom}
+	}
+
+	panic(fmt.Sprintf("unknown mailer %q", cfg.Mailer))
+}
+
+func newAttemptStore(ctx context.Context, cfg *config.Config, storage *mysql.StDb) lockout.Store {
+	switch cfg.LockoutStore {
+	case "mysql":
+		return storage
+	case "memory":
+		attempts := cache.NewLoginAttempts()
+		go attempts.Run(ctx, time.Minute)
+
+		return attempts
+	}
+
+	panic(fmt.Sprintf("unknown lockout store %q", cfg.LockoutStore))
+}
 
-	return &App{GRPCServer: grpcApp}
+// Stop gracefully stops the servers and then the background jobs.
+func (a *App) Stop() {
+	a.GRPCServer.Stop()
+	a.HTTPServer.Stop()
+	a.cancel()
+	a.storage.Stop()
 }
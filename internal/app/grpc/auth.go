@@ -0,0 +1,280 @@
+package grpc
+
+import (
+	usergrpc "AuthService/internal/grpc"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/tenant"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type PermissionChecker interface {
+	Require(ctx context.Context, userID int64, permission string) error
+}
+
+type access int
+
+const (
+	// public methods are served without a token.
+	public access = iota
+	// optional methods authenticate the caller when a token is sent.
+	optional
+	// authenticated methods need a valid token.
+	authenticated
+)
+
+type policy struct {
+	access access
+	// permission, when set, must be granted to the caller.
+	permission string
+}
+
+const servicePrefix = "/user.UserService/"
+
+// schoolHeader is the metadata naming the school a call acts in. Public
+// calls use it to pick the school to register or log in to, and super
+// admins to act in a school other than their own. Other callers always act
+// in the school of their token.
+const schoolHeader = "x-school-id"
+
+// policies tells how each RPC is authorized. Methods missing from the table
+// are refused, so a new RPC cannot be exposed by accident. Permissions that
+// roles may grant over a single class are checked by the services, which
+// know the class of the target user.
+var policies = map[string]policy{
+	servicePrefix + "Register":               {access: public},
+	servicePrefix + "Login":                  {access: public},
+	servicePrefix + "CompleteMFALogin":       {access: public},
+	servicePrefix + "RefreshToken":           {access: public},
+	servicePrefix + "RequestPasswordReset":   {access: public},
+	servicePrefix + "ConfirmPasswordReset":   {access: public},
+	servicePrefix + "VerifyEmail":            {access: public},
+	servicePrefix + "ResendVerification":     {access: public},
+	servicePrefix + "Validate":               {access: public},
+	servicePrefix + "Introspect":             {access: public},
+	servicePrefix + "GetJWKS":                {access: public},
+	servicePrefix + "BeginTOTPEnrollment":    {access: optional},
+	servicePrefix + "ConfirmTOTPEnrollment":  {access: optional},
+	servicePrefix + "Logout":                 {access: authenticated},
+	servicePrefix + "RevokeAllSessions":      {access: authenticated},
+	servicePrefix + "ListSessions":           {access: authenticated},
+	servicePrefix + "TerminateSession":       {access: authenticated},
+	servicePrefix + "UpdatePassword":         {access: authenticated},
+	servicePrefix + "ListRoles":              {access: authenticated},
+	servicePrefix + "CheckPermission":        {access: authenticated},
+	servicePrefix + "ListScopedRoles":        {access: authenticated},
+	servicePrefix + "GetPermissionLevel":     {access: authenticated},
+	servicePrefix + "FillUserProfile":        {access: authenticated},
+	servicePrefix + "GetUserProfile":         {access: authenticated},
+	servicePrefix + "UpdateUserProfile":      {access: authenticated},
+	servicePrefix + "IsUserActive":           {access: authenticated},
+	servicePrefix + "GetStudentsByClassname": {access: authenticated},
+	servicePrefix + "ListUsers":              {access: authenticated},
+	servicePrefix + "ExportUsers":            {access: authenticated},
+	servicePrefix + "GetClass":               {access: authenticated},
+	servicePrefix + "GetClassHistory":        {access: authenticated},
+	servicePrefix + "ListClasses":            {access: authenticated},
+	servicePrefix + "ChangeUserStatus":       {access: authenticated},
+	servicePrefix + "UnlockAccount":          {access: authenticated},
+	servicePrefix + "CreateGuardianInvite":   {access: authenticated},
+	servicePrefix + "LinkGuardian":           {access: authenticated},
+	servicePrefix + "UnlinkGuardian":         {access: authenticated},
+	servicePrefix + "ListGuardians":          {access: authenticated},
+	servicePrefix + "ListChildren":           {access: authenticated},
+	servicePrefix + "SetParentalConsent":     {access: authenticated},
+	servicePrefix + "SetPermissionLevel":     {access: authenticated, permission: rbac.PermUserPermissionSet},
+	servicePrefix + "LockUserProfile":        {access: authenticated, permission: rbac.PermUserProfileEdit},
+	servicePrefix + "DeleteUser":             {access: authenticated, permission: rbac.PermUserDelete},
+	servicePrefix + "SetMFARequirement":      {access: authenticated, permission: rbac.PermMFAManage},
+	servicePrefix + "AssignRole":             {access: authenticated, permission: rbac.PermRolesAssign},
+	servicePrefix + "RevokeRole":             {access: authenticated, permission: rbac.PermRolesAssign},
+	servicePrefix + "GrantScopedRole":        {access: authenticated, permission: rbac.PermRolesAssign},
+	servicePrefix + "RevokeScopedRole":       {access: authenticated, permission: rbac.PermRolesAssign},
+	servicePrefix + "CreateClass":            {access: authenticated, permission: rbac.PermClassesManage},
+	servicePrefix + "UpdateClass":            {access: authenticated, permission: rbac.PermClassesManage},
+	servicePrefix + "ArchiveClass":           {access: authenticated, permission: rbac.PermClassesManage},
+	servicePrefix + "PromoteAcademicYear":    {access: authenticated, permission: rbac.PermClassesManage},
+	servicePrefix + "TransferStudent":        {access: authenticated, permission: rbac.PermClassesManage},
+	servicePrefix + "CreateSchool":           {access: authenticated, permission: rbac.PermSchoolsManage},
+	servicePrefix + "ListSchools":            {access: authenticated, permission: rbac.PermSchoolsManage},
+	servicePrefix + "BulkImportUsers":        {access: authenticated, permission: rbac.PermUsersImport},
+}
+
+// AuthInterceptor authenticates the caller of every unary RPC according to
+// policies and stores the principal and the school the call acts in in the
+// context for the handlers.
+//
+// The token is read from the "authorization: Bearer <token>" metadata. For
+// compatibility with older clients, the token field of the request is used
+// when the metadata carries none.
+func AuthInterceptor(log *slog.Logger, authService usergrpc.AuthRepo, permissions PermissionChecker) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var requestToken string
+		if r, ok := req.(interface{ GetToken() string }); ok {
+			requestToken = r.GetToken()
+		}
+
+		ctx, err := authenticate(ctx, log, authService, permissions, info.FullMethod, requestToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor applies the policies of AuthInterceptor to
+// streaming RPCs. Streams are authenticated by the metadata only.
+func AuthStreamInterceptor(log *slog.Logger, authService usergrpc.AuthRepo, permissions PermissionChecker) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), log, authService, permissions, info.FullMethod, "")
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream replaces the context of a stream with the one carrying
+// the principal.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate checks the call of method against its policy and returns the
+// context for the handler.
+func authenticate(ctx context.Context, log *slog.Logger, authService usergrpc.AuthRepo, permissions PermissionChecker, method, requestToken string) (context.Context, error) {
+	p, ok := policies[method]
+	if !ok {
+		log.Warn("refused call to method without access policy", slog.String("Method", method))
+
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	requested, err := requestedSchool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.access == public {
+		return withRequestedSchool(ctx, requested), nil
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		token = requestToken
+	}
+
+	if token == "" {
+		if p.access == optional {
+			return withRequestedSchool(ctx, requested), nil
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "authentication is required")
+	}
+
+	user, err := authService.Validate(ctx, token)
+	if err != nil {
+		return nil, usergrpc.TokenStatus(err)
+	}
+
+	if p.permission != "" {
+		if err = permissions.Require(ctx, user.ID, p.permission); err != nil {
+			if errors.Is(err, serviceerrors.ErrAccessDenied) {
+				return nil, status.Error(codes.PermissionDenied, "permission denied")
+			}
+
+			return nil, status.Error(codes.Internal, "failed to check permission")
+		}
+	}
+
+	ctx, err = schoolContext(ctx, permissions, user.ID, user.SchoolID, requested)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to check permission")
+	}
+
+	return usergrpc.WithPrincipal(ctx, usergrpc.Principal{UserID: user.ID, Token: token}), nil
+}
+
+// requestedSchool returns the school named by the schoolHeader metadata of
+// ctx, or 0 when there is none.
+func requestedSchool(ctx context.Context) (int64, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, nil
+	}
+
+	values := md.Get(schoolHeader)
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(values[0]), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, status.Error(codes.InvalidArgument, "invalid school id")
+	}
+
+	return id, nil
+}
+
+// withRequestedSchool limits an anonymous call to the school it named.
+// Calls naming none reach users by ID in every school and by email in the
+// default one.
+func withRequestedSchool(ctx context.Context, requested int64) context.Context {
+	if requested == 0 {
+		return ctx
+	}
+
+	return tenant.WithSchool(ctx, requested)
+}
+
+// schoolContext limits ctx to the school of the caller. Super admins act in
+// the school they requested, or in every school when they named none.
+func schoolContext(ctx context.Context, permissions PermissionChecker, userID, schoolID, requested int64) (context.Context, error) {
+	err := permissions.Require(ctx, userID, rbac.PermSchoolsManage)
+	if err == nil {
+		if requested == 0 {
+			return tenant.WithAllSchools(ctx), nil
+		}
+
+		return tenant.WithSchool(ctx, requested), nil
+	}
+
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return nil, err
+	}
+
+	return tenant.WithSchool(ctx, schoolID), nil
+}
+
+// bearerToken returns the token from the authorization metadata of ctx.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, v := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(v, " ")
+		if found && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+
+	return ""
+}
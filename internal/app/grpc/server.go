@@ -14,13 +14,18 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+type RBACService interface {
+	usergrpc.RBACRepo
+	PermissionChecker
+}
+
 type GRPCApp struct {
 	log        *slog.Logger
 	gRPCServer *grpc.Server
 	port       int
 }
 
-func NewGRPCApp(log *slog.Logger, authService usergrpc.AuthRepo, userService usergrpc.UserRepo, port int) *GRPCApp {
+func NewGRPCApp(log *slog.Logger, authService usergrpc.AuthRepo, userService usergrpc.UserRepo, rbacService RBACService, classService usergrpc.ClassRepo, guardianService usergrpc.GuardianRepo, schoolService usergrpc.SchoolRepo, importService usergrpc.ImportRepo, exportService usergrpc.ExportRepo, port int) *GRPCApp {
 	loggingOpts := []logging.Option{
 		logging.WithLogOnEvents(
 			//logging.StartCall, logging.FinishCall,
@@ -37,12 +42,20 @@ func NewGRPCApp(log *slog.Logger, authService usergrpc.AuthRepo, userService use
 		}),
 	}
 
-	gRPCServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
-		recovery.UnaryServerInterceptor(recoveryOpts...),
-		logging.UnaryServerInterceptor(InterceptorLogger(log), loggingOpts...),
-	))
+	gRPCServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			recovery.UnaryServerInterceptor(recoveryOpts...),
+			logging.UnaryServerInterceptor(InterceptorLogger(log), loggingOpts...),
+			AuthInterceptor(log, authService, rbacService),
+		),
+		grpc.ChainStreamInterceptor(
+			recovery.StreamServerInterceptor(recoveryOpts...),
+			logging.StreamServerInterceptor(InterceptorLogger(log), loggingOpts...),
+			AuthStreamInterceptor(log, authService, rbacService),
+		),
+	)
 
-	usergrpc.Register(gRPCServer, authService, userService)
+	usergrpc.Register(gRPCServer, authService, userService, rbacService, classService, guardianService, schoolService, importService, exportService)
 
 	return &GRPCApp{gRPCServer: gRPCServer, port: port, log: log}
 }
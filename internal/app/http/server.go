@@ -0,0 +1,77 @@
+package http
+
+import (
+	"AuthService/pkg/tools/jwt"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type JWKSProvider interface {
+	GetJWKS(ctx context.Context) jwt.JWKSet
+}
+
+// HTTPApp serves the endpoints that have to be reachable without a gRPC
+// client, such as the JWKS document used by other services to verify tokens.
+type HTTPApp struct {
+	log    *slog.Logger
+	server *http.Server
+}
+
+func NewHTTPApp(log *slog.Logger, jwks JWKSProvider, port int) *HTTPApp {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/jwks.json", jwksHandler(log, jwks))
+
+	return &HTTPApp{
+		log: log,
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+func jwksHandler(log *slog.Logger, jwks JWKSProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+
+		if err := json.NewEncoder(w).Encode(jwks.GetJWKS(r.Context())); err != nil {
+			log.Error("failed to write jwks", sl.Err(err))
+		}
+	}
+}
+
+func (a *HTTPApp) Run() error {
+	if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve http due to error: %w", err)
+	}
+
+	return nil
+}
+
+func (a *HTTPApp) MustRun() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+func (a *HTTPApp) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := a.server.Shutdown(ctx); err != nil {
+		a.log.Error("failed to stop http server", sl.Err(err))
+	}
+}
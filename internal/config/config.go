@@ -1,11 +1,84 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	Port         int    `mapstructure:"PORT"`
-	DBUrl        string `mapstructure:"DB_URL"`
-	JWTSecretKey string `mapstructure:"JWT_SECRET_KEY"`
+	Port            int           `mapstructure:"PORT"`
+	HTTPPort        int           `mapstructure:"HTTP_PORT"`
+	DBUrl           string        `mapstructure:"DB_URL"`
+	JWTSecretKey    string        `mapstructure:"JWT_SECRET_KEY"`
+	JWTKeysDir      string        `mapstructure:"JWT_KEYS_DIR"`
+	JWTKeyReload    time.Duration `mapstructure:"JWT_KEY_RELOAD_INTERVAL"`
+	JWTKeyOverlap   time.Duration `mapstructure:"JWT_KEY_OVERLAP"`
+	JWTIssuer       string        `mapstructure:"JWT_ISSUER"`
+	JWTAudience     []string      `mapstructure:"JWT_AUDIENCE"`
+	JWTAllowedAuds  []string      `mapstructure:"JWT_ALLOWED_AUDIENCES"`
+	JWTAllowedAlgs  []string      `mapstructure:"JWT_ALLOWED_ALGORITHMS"`
+	JWTLeeway       time.Duration `mapstructure:"JWT_LEEWAY"`
+	AccessTokenTTL  time.Duration `mapstructure:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `mapstructure:"REFRESH_TOKEN_TTL"`
+	RevocationSync  time.Duration `mapstructure:"REVOCATION_SYNC_INTERVAL"`
+
+	PasswordResetTTL time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+	PasswordResetURL string        `mapstructure:"PASSWORD_RESET_URL"`
+
+	EmailVerificationTTL time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+	EmailVerificationURL string        `mapstructure:"EMAIL_VERIFICATION_URL"`
+	// AllowUnverifiedLogin lets users log in before they verify their email.
+	// It is meant for development only.
+	AllowUnverifiedLogin bool `mapstructure:"ALLOW_UNVERIFIED_LOGIN"`
+
+	GuardianInviteTTL time.Duration `mapstructure:"GUARDIAN_INVITE_TTL"`
+	ImportInviteTTL   time.Duration `mapstructure:"IMPORT_INVITE_TTL"`
+	// GuardianConsentAge is the age under which students need the consent
+	// of a parent or an approved guardian before activation. 0 turns the
+	// rule off.
+	GuardianConsentAge int `mapstructure:"GUARDIAN_CONSENT_AGE"`
+
+	MFAIssuer       string        `mapstructure:"MFA_ISSUER"`
+	MFAChallengeTTL time.Duration `mapstructure:"MFA_CHALLENGE_TTL"`
+
+	// PasswordHashAlgorithm is bcrypt or argon2id. Stored hashes of another
+	// algorithm or cost are upgraded on the next login.
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost            int    `mapstructure:"BCRYPT_COST"`
+	Argon2Memory          uint32 `mapstructure:"ARGON2_MEMORY_KIB"`
+	Argon2Time            uint32 `mapstructure:"ARGON2_TIME"`
+	Argon2Parallelism     uint8  `mapstructure:"ARGON2_PARALLELISM"`
+
+	PasswordMinLength   int `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinClasses  int `mapstructure:"PASSWORD_MIN_CLASSES"`
+	PasswordHistorySize int `mapstructure:"PASSWORD_HISTORY_SIZE"`
+	// PasswordBlocklist is a file of common passwords, one per line. The
+	// list bundled with the service is used when it is empty.
+	PasswordBlocklist string `mapstructure:"PASSWORD_BLOCKLIST_FILE"`
+
+	// LockoutStore selects where failed logins are counted: memory or
+	// mysql. Use mysql when several replicas run.
+	LockoutStore       string        `mapstructure:"LOCKOUT_STORE"`
+	LockoutThreshold   int           `mapstructure:"LOCKOUT_THRESHOLD"`
+	LockoutDuration    time.Duration `mapstructure:"LOCKOUT_DURATION"`
+	LoginBackoffBase   time.Duration `mapstructure:"LOGIN_BACKOFF_BASE"`
+	LoginBackoffMax    time.Duration `mapstructure:"LOGIN_BACKOFF_MAX"`
+	IPThrottleLimit    int           `mapstructure:"IP_THROTTLE_LIMIT"`
+	IPThrottleDuration time.Duration `mapstructure:"IP_THROTTLE_DURATION"`
+	// MailRateLimit is the number of password reset and verification
+	// emails one account gets per MailRateWindow. 0 turns the limit off.
+	MailRateLimit  int           `mapstructure:"MAIL_RATE_LIMIT"`
+	MailRateWindow time.Duration `mapstructure:"MAIL_RATE_WINDOW"`
+
+	// Mailer selects how emails are delivered: smtp, file or memory.
+	Mailer       string `mapstructure:"MAILER"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	MailDir      string `mapstructure:"MAIL_DIR"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
 }
 
 func LoadConfig() (cfg *Config, err error) {
@@ -13,6 +86,53 @@ func LoadConfig() (cfg *Config, err error) {
 	viper.SetConfigName("dev")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("HTTP_PORT", 8080)
+	viper.SetDefault("JWT_KEYS_DIR", "")
+	viper.SetDefault("JWT_KEY_RELOAD_INTERVAL", time.Hour)
+	viper.SetDefault("JWT_KEY_OVERLAP", 24*time.Hour)
+	viper.SetDefault("JWT_ISSUER", "go-grpc-auth-svc")
+	viper.SetDefault("JWT_AUDIENCE", []string{"eeducation"})
+	viper.SetDefault("JWT_ALLOWED_AUDIENCES", []string{})
+	viper.SetDefault("JWT_ALLOWED_ALGORITHMS", []string{})
+	viper.SetDefault("JWT_LEEWAY", 30*time.Second)
+	viper.SetDefault("ACCESS_TOKEN_TTL", 15*time.Minute)
+	viper.SetDefault("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	viper.SetDefault("REVOCATION_SYNC_INTERVAL", time.Minute)
+	viper.SetDefault("PASSWORD_RESET_TTL", time.Hour)
+	viper.SetDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+	viper.SetDefault("EMAIL_VERIFICATION_TTL", 48*time.Hour)
+	viper.SetDefault("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email")
+	viper.SetDefault("ALLOW_UNVERIFIED_LOGIN", false)
+	viper.SetDefault("GUARDIAN_INVITE_TTL", 7*24*time.Hour)
+	viper.SetDefault("IMPORT_INVITE_TTL", 14*24*time.Hour)
+	viper.SetDefault("GUARDIAN_CONSENT_AGE", 14)
+	viper.SetDefault("MFA_ISSUER", "EEducation")
+	viper.SetDefault("MFA_CHALLENGE_TTL", 5*time.Minute)
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("BCRYPT_COST", 12)
+	viper.SetDefault("ARGON2_MEMORY_KIB", 64*1024)
+	viper.SetDefault("ARGON2_TIME", 3)
+	viper.SetDefault("ARGON2_PARALLELISM", 2)
+	viper.SetDefault("PASSWORD_MIN_LENGTH", 10)
+	viper.SetDefault("PASSWORD_MIN_CLASSES", 3)
+	viper.SetDefault("PASSWORD_HISTORY_SIZE", 5)
+	viper.SetDefault("LOCKOUT_STORE", "memory")
+	viper.SetDefault("LOCKOUT_THRESHOLD", 10)
+	viper.SetDefault("LOCKOUT_DURATION", 15*time.Minute)
+	viper.SetDefault("LOGIN_BACKOFF_BASE", time.Second)
+	viper.SetDefault("LOGIN_BACKOFF_MAX", 30*time.Second)
+	viper.SetDefault("IP_THROTTLE_LIMIT", 100)
+	viper.SetDefault("IP_THROTTLE_DURATION", 15*time.Minute)
+	viper.SetDefault("MAIL_RATE_LIMIT", 3)
+	viper.SetDefault("MAIL_RATE_WINDOW", time.Hour)
+	viper.SetDefault("MAILER", "file")
+	viper.SetDefault("MAIL_FROM", "no-reply@eeducation.local")
+	viper.SetDefault("MAIL_DIR", "./mail")
+	viper.SetDefault("SMTP_HOST", "localhost")
+	viper.SetDefault("SMTP_PORT", 25)
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
@@ -0,0 +1,226 @@
+package grpc
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/utils"
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *api) CreateClass(ctx context.Context, req *pb.CreateClassRequest) (*pb.CreateClassResponse, error) {
+	if req.Grade == 0 {
+		return nil, status.Error(codes.InvalidArgument, "grade is required")
+	}
+
+	if req.Letter == "" {
+		return nil, status.Error(codes.InvalidArgument, "letter is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	class, err := a.classRepo.CreateClass(ctx, initiatorID, models.Class{
+		Grade:             req.Grade,
+		Letter:            req.Letter,
+		AcademicYear:      req.AcademicYear,
+		HomeroomTeacherID: req.HomeroomTeacherId,
+		Capacity:          req.Capacity,
+	})
+	if err != nil {
+		return nil, classStatus(err, "failed to create class")
+	}
+
+	return &pb.CreateClassResponse{
+		Class: utils.ConvertClass(class),
+	}, nil
+}
+
+func (a *api) UpdateClass(ctx context.Context, req *pb.UpdateClassRequest) (*pb.UpdateClassResponse, error) {
+	if req.ClassId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "class id is required")
+	}
+
+	// Without a mask, zero fields are taken as not set.
+	fields := req.GetUpdateMask().GetPaths()
+	if len(fields) == 0 {
+		if req.HomeroomTeacherId != 0 {
+			fields = append(fields, models.ClassHomeroomTeacher)
+		}
+		if req.Capacity != 0 {
+			fields = append(fields, models.ClassCapacity)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "nothing to update")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	class, err := a.classRepo.UpdateClass(ctx, initiatorID, req.ClassId, models.Class{
+		HomeroomTeacherID: req.HomeroomTeacherId,
+		Capacity:          req.Capacity,
+	}, fields)
+	if err != nil {
+		return nil, classStatus(err, "failed to update class")
+	}
+
+	return &pb.UpdateClassResponse{
+		Class: utils.ConvertClass(class),
+	}, nil
+}
+
+func (a *api) GetClass(ctx context.Context, req *pb.GetClassRequest) (*pb.GetClassResponse, error) {
+	if req.ClassId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "class id is required")
+	}
+
+	class, err := a.classRepo.GetClass(ctx, req.ClassId)
+	if err != nil {
+		return nil, classStatus(err, "failed to get class")
+	}
+
+	return &pb.GetClassResponse{
+		Class: utils.ConvertClass(class),
+	}, nil
+}
+
+func (a *api) ListClasses(ctx context.Context, req *pb.ListClassesRequest) (*pb.ListClassesResponse, error) {
+	classes, err := a.classRepo.ListClasses(ctx, models.ClassFilter{
+		AcademicYear:    req.AcademicYear,
+		Grade:           req.Grade,
+		IncludeArchived: req.IncludeArchived,
+	})
+	if err != nil {
+		return nil, classStatus(err, "failed to list classes")
+	}
+
+	return &pb.ListClassesResponse{
+		Classes: utils.ConvertClasses(classes),
+	}, nil
+}
+
+func (a *api) ArchiveClass(ctx context.Context, req *pb.ArchiveClassRequest) (*pb.ArchiveClassResponse, error) {
+	if req.ClassId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "class id is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.classRepo.ArchiveClass(ctx, initiatorID, req.ClassId); err != nil {
+		return nil, classStatus(err, "failed to archive class")
+	}
+
+	return &pb.ArchiveClassResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) PromoteAcademicYear(ctx context.Context, req *pb.PromoteAcademicYearRequest) (*pb.PromoteAcademicYearResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := a.classRepo.PromoteAcademicYear(ctx, initiatorID, req.AcademicYear, req.DeactivateGraduates, req.DryRun)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrClassFull) {
+			return nil, status.Error(codes.FailedPrecondition, "a class of the next year is full")
+		}
+		return nil, classStatus(err, "failed to promote academic year")
+	}
+
+	return &pb.PromoteAcademicYearResponse{
+		Students: utils.ConvertStudentPromotions(report),
+		DryRun:   req.DryRun,
+	}, nil
+}
+
+func (a *api) TransferStudent(ctx context.Context, req *pb.TransferStudentRequest) (*pb.TransferStudentResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+
+	if req.ClassId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "class id is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.classRepo.TransferStudent(ctx, initiatorID, req.UserId, req.ClassId); err != nil {
+		if errors.Is(err, serviceerrors.ErrClassFull) {
+			return nil, status.Error(codes.FailedPrecondition, "class is full")
+		}
+		if errors.Is(err, serviceerrors.ErrNotStudent) {
+			return nil, status.Error(codes.FailedPrecondition, "user is not a student")
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, classStatus(err, "failed to transfer student")
+	}
+
+	return &pb.TransferStudentResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) GetClassHistory(ctx context.Context, req *pb.GetClassHistoryRequest) (*pb.GetClassHistoryResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := req.UserId
+	if userID == 0 {
+		userID = initiatorID
+	}
+
+	history, err := a.classRepo.GetClassHistory(ctx, initiatorID, userID)
+	if err != nil {
+		return nil, classStatus(err, "failed to get class history")
+	}
+
+	return &pb.GetClassHistoryResponse{
+		Memberships: utils.ConvertClassHistory(history),
+	}, nil
+}
+
+func classStatus(err error, fallback string) error {
+	switch {
+	case errors.Is(err, serviceerrors.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, serviceerrors.ErrInvalidClass):
+		return status.Error(codes.InvalidArgument, "invalid class")
+	case errors.Is(err, serviceerrors.ErrClassArchived):
+		return status.Error(codes.FailedPrecondition, "class is archived")
+	case errors.Is(err, serviceerrors.ErrClassFull):
+		return status.Error(codes.FailedPrecondition, "capacity is below the number of students")
+	case errors.Is(err, storage.ErrClassExists):
+		return status.Error(codes.AlreadyExists, "class already exists")
+	case errors.Is(err, storage.ErrClassNotFound):
+		return status.Error(codes.NotFound, "class not found")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "homeroom teacher not found")
+	}
+
+	return status.Error(codes.Internal, fallback)
+}
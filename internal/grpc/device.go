@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"AuthService/internal/models"
+	"context"
+	"net"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const maxDeviceFieldLen = 255
+
+// deviceFromContext describes the client of the current call using the
+// label it sent, its user agent and the address of the connection.
+func deviceFromContext(ctx context.Context, label string) models.Device {
+	device := models.Device{Label: truncate(label, 100)}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		device.UserAgent = truncate(strings.Join(md.Get("user-agent"), " "), maxDeviceFieldLen)
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		device.IP = host
+	}
+
+	return device
+}
+
+// truncate cuts s to at most n characters. The columns count characters,
+// and cutting inside a multi-byte character would store invalid UTF-8.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n])
+}
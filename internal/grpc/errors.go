@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/pkg/tools/jwt"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const errorDomain = "auth.eeducation"
+
+var jwtReasons = []struct {
+	err    error
+	reason string
+	msg    string
+}{
+	{jwt.ErrJWTExpired, "TOKEN_EXPIRED", "invalid JWT: token is expired"},
+	{jwt.ErrJWTNotValidYet, "TOKEN_NOT_VALID_YET", "invalid JWT: token is not valid yet"},
+	{jwt.ErrJWTBadIssuer, "TOKEN_BAD_ISSUER", "invalid JWT: issuer is not trusted"},
+	{jwt.ErrJWTBadAudience, "TOKEN_BAD_AUDIENCE", "invalid JWT: audience is not allowed"},
+	{jwt.ErrJWTBadAlgorithm, "TOKEN_BAD_ALGORITHM", "invalid JWT: signing algorithm is not allowed"},
+	{jwt.ErrJWTBadSignature, "TOKEN_BAD_SIGNATURE", "invalid JWT: signature is invalid"},
+	{jwt.ErrJWTMissingClaim, "TOKEN_MISSING_CLAIM", "invalid JWT: required claim is missing"},
+	{jwt.ErrJWTMalformed, "TOKEN_MALFORMED", "invalid JWT: token is malformed"},
+}
+
+// jwtStatus converts a token validation error into an Unauthenticated
+// status. The message and the ErrorInfo reason tell the client why the
+// token was rejected.
+func jwtStatus(err error) error {
+	msg, reason := "invalid JWT", "TOKEN_INVALID"
+	for _, r := range jwtReasons {
+		if errors.Is(err, r.err) {
+			msg, reason = r.msg, r.reason
+			break
+		}
+	}
+
+	st, detailsErr := status.New(codes.Unauthenticated, msg).WithDetails(&errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: errorDomain,
+	})
+	if detailsErr != nil {
+		return status.Error(codes.Unauthenticated, msg)
+	}
+
+	return st.Err()
+}
+
+// retryStatus builds a status that carries a RetryInfo detail when err
+// tells how long the client has to wait.
+func retryStatus(code codes.Code, reason, msg string, err error) error {
+	var retryErr *serviceerrors.RetryAfterError
+	if !errors.As(err, &retryErr) {
+		return status.Error(code, msg)
+	}
+
+	// Clients commonly work with whole seconds.
+	delay := time.Duration(math.Ceil(retryErr.RetryAfter.Seconds())) * time.Second
+
+	st, detailsErr := status.New(code, fmt.Sprintf("%s, retry in %s", msg, delay)).WithDetails(
+		&errdetails.ErrorInfo{
+			Reason: reason,
+			Domain: errorDomain,
+		},
+		&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(delay),
+		},
+	)
+	if detailsErr != nil {
+		return status.Error(code, msg)
+	}
+
+	return st.Err()
+}
+
+// passwordPolicyStatus converts a password policy error into an
+// InvalidArgument status with one field violation per broken rule. The
+// ErrorInfo metadata carries the rule names for clients to translate.
+func passwordPolicyStatus(err error, field string) error {
+	const msg = "password does not meet the policy"
+
+	var policyErr *serviceerrors.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	rules := make([]string, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: v.Message,
+		})
+		rules = append(rules, string(v.Rule))
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, msg).WithDetails(
+		&errdetails.ErrorInfo{
+			Reason:   "WEAK_PASSWORD",
+			Domain:   errorDomain,
+			Metadata: map[string]string{"rules": strings.Join(rules, ",")},
+		},
+		badRequest,
+	)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+
+	return st.Err()
+}
+
+// profileStatus converts a profile validation error into an InvalidArgument
+// status with a field violation per rejected field.
+func profileStatus(err error) error {
+	const msg = "invalid profile"
+
+	var profileErr *serviceerrors.ProfileError
+	if !errors.As(err, &profileErr) {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	fields := make([]string, 0, len(profileErr.Violations))
+	for _, v := range profileErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Message,
+		})
+		fields = append(fields, v.Field+" "+v.Message)
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, msg+": "+strings.Join(fields, "; ")).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, msg)
+	}
+
+	return st.Err()
+}
+
+// activationStatus converts an activation error into a FailedPrecondition
+// status. The ErrorInfo metadata lists the requirements the account lacks.
+func activationStatus(err error) error {
+	const msg = "account does not meet the activation requirements"
+
+	var activationErr *serviceerrors.ActivationError
+	if !errors.As(err, &activationErr) {
+		return status.Error(codes.FailedPrecondition, msg)
+	}
+
+	requirements := strings.Join(activationErr.Requirements, ",")
+	st, detailsErr := status.New(codes.FailedPrecondition, msg+": "+requirements).WithDetails(&errdetails.ErrorInfo{
+		Reason:   "ACTIVATION_REQUIREMENTS",
+		Domain:   errorDomain,
+		Metadata: map[string]string{"requirements": requirements},
+	})
+	if detailsErr != nil {
+		return status.Error(codes.FailedPrecondition, msg)
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+	"AuthService/internal/services/exports"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"bufio"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportChunkSize is the size of the file chunks sent to the client.
+const exportChunkSize = 64 << 10
+
+func (a *api) ExportUsers(req *pb.ExportUsersRequest, stream pb.UserService_ExportUsersServer) error {
+	if req.Format == "" {
+		return status.Error(codes.InvalidArgument, "format is required")
+	}
+
+	ctx := stream.Context()
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return err
+	}
+
+	active, err := activeFilter(req.Status)
+	if err != nil {
+		return err
+	}
+
+	q := exports.Query{
+		Format:    req.Format,
+		Columns:   req.Columns,
+		Classname: req.Classname,
+		Filter: models.UserFilter{
+			Role:     req.Role,
+			Active:   active,
+			SchoolID: req.SchoolId,
+		},
+	}
+
+	w := bufio.NewWriterSize(chunkWriter{stream: stream}, exportChunkSize)
+
+	rows, err := a.exportRepo.ExportUsers(ctx, initiatorID, q, w)
+	if err != nil {
+		return exportStatus(err, "failed to export users")
+	}
+
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	return stream.Send(&pb.ExportUsersResponse{Rows: rows})
+}
+
+// chunkWriter sends every write as a message of an export stream.
+type chunkWriter struct {
+	stream pb.UserService_ExportUsersServer
+}
+
+func (c chunkWriter) Write(p []byte) (int, error) {
+	// Send marshals the message before it returns, so p may be reused.
+	if err := c.stream.Send(&pb.ExportUsersResponse{Data: p}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func exportStatus(err error, fallback string) error {
+	switch {
+	case errors.Is(err, serviceerrors.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, serviceerrors.ErrInvalidExport):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, serviceerrors.ErrClassNotFound):
+		return status.Error(codes.NotFound, "class not found")
+	}
+
+	if s, ok := status.FromError(err); ok {
+		// The client went away while the file was sent.
+		return s.Err()
+	}
+
+	return status.Error(codes.Internal, fallback)
+}
@@ -0,0 +1,171 @@
+package grpc
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/utils"
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *api) CreateGuardianInvite(ctx context.Context, req *pb.CreateGuardianInviteRequest) (*pb.CreateGuardianInviteResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	studentID := req.StudentId
+	if studentID == 0 {
+		studentID = initiatorID
+	}
+
+	code, expiresAt, err := a.guardianRepo.CreateInvite(ctx, initiatorID, studentID)
+	if err != nil {
+		return nil, guardianStatus(err, "failed to create guardian invite")
+	}
+
+	return &pb.CreateGuardianInviteResponse{
+		Code:      code,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+func (a *api) LinkGuardian(ctx context.Context, req *pb.LinkGuardianRequest) (*pb.LinkGuardianResponse, error) {
+	if req.InviteCode == "" {
+		if req.GuardianId == 0 {
+			return nil, status.Error(codes.InvalidArgument, "guardian id is required")
+		}
+
+		if req.StudentId == 0 {
+			return nil, status.Error(codes.InvalidArgument, "student id is required")
+		}
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	studentID, err := a.guardianRepo.LinkGuardian(ctx, initiatorID, req.InviteCode, models.GuardianLink{
+		GuardianID:   req.GuardianId,
+		StudentID:    req.StudentId,
+		Relationship: req.Relationship,
+		Primary:      req.IsPrimary,
+	})
+	if err != nil {
+		return nil, guardianStatus(err, "failed to link guardian")
+	}
+
+	return &pb.LinkGuardianResponse{
+		Status:    http.StatusOK,
+		StudentId: studentID,
+	}, nil
+}
+
+func (a *api) UnlinkGuardian(ctx context.Context, req *pb.UnlinkGuardianRequest) (*pb.UnlinkGuardianResponse, error) {
+	if req.StudentId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "student id is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	guardianID := req.GuardianId
+	if guardianID == 0 {
+		guardianID = initiatorID
+	}
+
+	if err = a.guardianRepo.UnlinkGuardian(ctx, initiatorID, guardianID, req.StudentId); err != nil {
+		return nil, guardianStatus(err, "failed to unlink guardian")
+	}
+
+	return &pb.UnlinkGuardianResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) ListGuardians(ctx context.Context, req *pb.ListGuardiansRequest) (*pb.ListGuardiansResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	studentID := req.StudentId
+	if studentID == 0 {
+		studentID = initiatorID
+	}
+
+	links, err := a.guardianRepo.ListGuardians(ctx, initiatorID, studentID)
+	if err != nil {
+		return nil, guardianStatus(err, "failed to list guardians")
+	}
+
+	return &pb.ListGuardiansResponse{
+		Guardians: utils.ConvertGuardianLinks(links),
+	}, nil
+}
+
+func (a *api) ListChildren(ctx context.Context, req *pb.ListChildrenRequest) (*pb.ListChildrenResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	guardianID := req.GuardianId
+	if guardianID == 0 {
+		guardianID = initiatorID
+	}
+
+	links, err := a.guardianRepo.ListChildren(ctx, initiatorID, guardianID)
+	if err != nil {
+		return nil, guardianStatus(err, "failed to list children")
+	}
+
+	return &pb.ListChildrenResponse{
+		Children: utils.ConvertGuardianLinks(links),
+	}, nil
+}
+
+func (a *api) SetParentalConsent(ctx context.Context, req *pb.SetParentalConsentRequest) (*pb.SetParentalConsentResponse, error) {
+	if req.StudentId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "student id is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.guardianRepo.SetParentalConsent(ctx, initiatorID, req.StudentId, req.Consent); err != nil {
+		return nil, guardianStatus(err, "failed to set parental consent")
+	}
+
+	return &pb.SetParentalConsentResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func guardianStatus(err error, fallback string) error {
+	switch {
+	case errors.Is(err, serviceerrors.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, serviceerrors.ErrInvalidGuardianLink):
+		return status.Error(codes.InvalidArgument, "invalid guardian link")
+	case errors.Is(err, serviceerrors.ErrInvalidInvite):
+		return status.Error(codes.InvalidArgument, "invalid or expired invite code")
+	case errors.Is(err, storage.ErrGuardianNotFound):
+		return status.Error(codes.NotFound, "guardian link not found")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	}
+
+	return status.Error(codes.Internal, fallback)
+}
@@ -1,14 +1,22 @@
 package grpc
 
 import (
+	"AuthService/internal/models"
 	"AuthService/internal/pb"
+	"AuthService/internal/services/exports"
 	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/services/user"
 	"AuthService/internal/storage/storage"
+	"AuthService/internal/utils"
+	"AuthService/pkg/tools/birthdate"
 	"AuthService/pkg/tools/jwt"
 	"context"
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
+	"google.golang.org/genproto/googleapis/type/date"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -19,7 +27,39 @@ type AuthRepo interface {
 		ctx context.Context,
 		email string,
 		password string,
-	) (string, error)
+		device models.Device,
+	) (models.TokenPair, *models.MFAChallenge, error)
+	CompleteMFALogin(
+		ctx context.Context,
+		challengeID,
+		code string,
+	) (models.TokenPair, error)
+	BeginTOTPEnrollment(
+		ctx context.Context,
+		token,
+		challengeID string,
+	) (string, string, error)
+	ConfirmTOTPEnrollment(
+		ctx context.Context,
+		token,
+		challengeID,
+		code string,
+	) ([]string, error)
+	SetMFARequirement(
+		ctx context.Context,
+		token string,
+		permissionLevel int64,
+		required bool,
+	) error
+	UnlockAccount(
+		ctx context.Context,
+		token,
+		email string,
+	) error
+	RefreshToken(
+		ctx context.Context,
+		refreshToken string,
+	) (models.TokenPair, error)
 	RegisterUser(
 		ctx context.Context,
 		email string,
@@ -28,7 +68,33 @@ type AuthRepo interface {
 	Validate(
 		ctx context.Context,
 		token string,
-	) (int64, error)
+	) (models.User, error)
+	Introspect(
+		ctx context.Context,
+		token string,
+	) (models.Introspection, error)
+	Logout(
+		ctx context.Context,
+		token,
+		refreshToken string,
+	) error
+	RevokeAllSessions(
+		ctx context.Context,
+		token string,
+	) error
+	ListSessions(
+		ctx context.Context,
+		token string,
+		userID int64,
+	) ([]models.Session, string, error)
+	TerminateSession(
+		ctx context.Context,
+		token,
+		sessionID string,
+	) error
+	GetJWKS(
+		ctx context.Context,
+	) jwt.JWKSet
 	ChangePassword(
 		ctx context.Context,
 		email,
@@ -36,6 +102,23 @@ type AuthRepo interface {
 		newPassword,
 		token string,
 	) error
+	RequestPasswordReset(
+		ctx context.Context,
+		email string,
+	) error
+	ConfirmPasswordReset(
+		ctx context.Context,
+		token,
+		newPassword string,
+	) error
+	VerifyEmail(
+		ctx context.Context,
+		token string,
+	) error
+	ResendVerification(
+		ctx context.Context,
+		email string,
+	) error
 	SetPermissionLevel(
 		ctx context.Context,
 		userID,
@@ -51,13 +134,33 @@ type AuthRepo interface {
 type UserRepo interface {
 	FillUserProfile(
 		ctx context.Context,
+		initiatorID int64,
 		name,
 		lastname,
-		middlename,
-		dateOfBirth,
+		middlename string,
+		dateOfBirth time.Time,
 		classname string,
 		userID int64,
 	) error
+	GetUserProfile(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+	) (models.UserRecord, error)
+	UpdateUserProfile(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+		update models.UserInfo,
+		fields []string,
+		overrideLock bool,
+	) (models.UserRecord, error)
+	LockUserProfile(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+		locked bool,
+	) error
 	ChangeUserStatus(
 		ctx context.Context,
 		userID int64,
@@ -70,22 +173,199 @@ type UserRepo interface {
 	) (bool, error)
 	GetStudentsByClassname(
 		ctx context.Context,
+		initiatorID int64,
 		classname string,
 	) ([]*pb.Student, error)
 	DeleteUser(
 		ctx context.Context,
+		initiatorID,
+		userID int64,
+	) error
+	ListUsers(
+		ctx context.Context,
+		initiatorID int64,
+		q user.ListQuery,
+	) ([]models.UserRecord, string, int64, error)
+}
+
+type RBACRepo interface {
+	AssignRole(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+		role string,
+	) error
+	RevokeRole(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+		role string,
+	) error
+	ListRoles(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+	) ([]models.Role, error)
+	CheckPermission(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+		permission string,
+	) (bool, error)
+	GrantScopedRole(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+		role models.ScopedRole,
+	) error
+	RevokeScopedRole(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+		role models.ScopedRole,
+	) error
+	ListScopedRoles(
+		ctx context.Context,
+		initiatorID,
+		userID int64,
+	) ([]models.ScopedRole, error)
+}
+
+type ClassRepo interface {
+	CreateClass(
+		ctx context.Context,
+		initiatorID int64,
+		class models.Class,
+	) (models.Class, error)
+	UpdateClass(
+		ctx context.Context,
+		initiatorID,
+		classID int64,
+		update models.Class,
+		fields []string,
+	) (models.Class, error)
+	GetClass(
+		ctx context.Context,
+		classID int64,
+	) (models.Class, error)
+	ListClasses(
+		ctx context.Context,
+		filter models.ClassFilter,
+	) ([]models.Class, error)
+	ArchiveClass(
+		ctx context.Context,
+		initiatorID,
+		classID int64,
+	) error
+	PromoteAcademicYear(
+		ctx context.Context,
+		initiatorID,
+		academicYear int64,
+		deactivate,
+		dryRun bool,
+	) ([]models.StudentPromotion, error)
+	TransferStudent(
+		ctx context.Context,
+		initiatorID,
+		userID,
+		classID int64,
+	) error
+	GetClassHistory(
+		ctx context.Context,
+		initiatorID,
 		userID int64,
+	) ([]models.ClassMembership, error)
+}
+
+type SchoolRepo interface {
+	CreateSchool(
+		ctx context.Context,
+		initiatorID int64,
+		name string,
+	) (models.School, error)
+	ListSchools(
+		ctx context.Context,
+		initiatorID int64,
+	) ([]models.School, error)
+}
+
+type GuardianRepo interface {
+	CreateInvite(
+		ctx context.Context,
+		initiatorID,
+		studentID int64,
+	) (string, time.Time, error)
+	LinkGuardian(
+		ctx context.Context,
+		initiatorID int64,
+		inviteCode string,
+		link models.GuardianLink,
+	) (int64, error)
+	UnlinkGuardian(
+		ctx context.Context,
+		initiatorID,
+		guardianID,
+		studentID int64,
 	) error
+	ListGuardians(
+		ctx context.Context,
+		initiatorID,
+		studentID int64,
+	) ([]models.GuardianLink, error)
+	ListChildren(
+		ctx context.Context,
+		initiatorID,
+		guardianID int64,
+	) ([]models.GuardianLink, error)
+	SetParentalConsent(
+		ctx context.Context,
+		initiatorID,
+		studentID int64,
+		consent bool,
+	) error
+}
+
+type ImportRepo interface {
+	ImportUsers(
+		ctx context.Context,
+		initiatorID int64,
+		rows []models.ImportRow,
+		dryRun bool,
+	) ([]models.ImportResult, error)
+}
+
+type ExportRepo interface {
+	ExportUsers(
+		ctx context.Context,
+		initiatorID int64,
+		q exports.Query,
+		w io.Writer,
+	) (int64, error)
 }
 
 type api struct {
 	pb.UnimplementedUserServiceServer
-	authRepo AuthRepo
-	userRepo UserRepo
+	authRepo     AuthRepo
+	userRepo     UserRepo
+	rbacRepo     RBACRepo
+	classRepo    ClassRepo
+	guardianRepo GuardianRepo
+	schoolRepo   SchoolRepo
+	importRepo   ImportRepo
+	exportRepo   ExportRepo
 }
 
-func Register(gRPCServer *grpc.Server, authRepo AuthRepo, userRepo UserRepo) {
-	pb.RegisterUserServiceServer(gRPCServer, &api{authRepo: authRepo, userRepo: userRepo})
+func Register(gRPCServer *grpc.Server, authRepo AuthRepo, userRepo UserRepo, rbacRepo RBACRepo, classRepo ClassRepo, guardianRepo GuardianRepo, schoolRepo SchoolRepo, importRepo ImportRepo, exportRepo ExportRepo) {
+	pb.RegisterUserServiceServer(gRPCServer, &api{
+		authRepo:     authRepo,
+		userRepo:     userRepo,
+		rbacRepo:     rbacRepo,
+		classRepo:    classRepo,
+		guardianRepo: guardianRepo,
+		schoolRepo:   schoolRepo,
+		importRepo:   importRepo,
+		exportRepo:   exportRepo,
+	})
 }
 
 func (a *api) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
@@ -97,19 +377,209 @@ func (a *api) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginRespons
 		return nil, status.Error(codes.InvalidArgument, "password is required")
 	}
 
-	token, err := a.authRepo.Login(ctx, req.Email, req.Password)
+	pair, challenge, err := a.authRepo.Login(ctx, req.Email, req.Password, deviceFromContext(ctx, req.DeviceLabel))
 	if err != nil {
 		if errors.Is(err, serviceerrors.ErrInvalidCredentials) {
 			return nil, status.Error(codes.Unauthenticated, "incorrect email or password")
 		} else if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.NotFound, "user with this email not found")
+		} else if errors.Is(err, serviceerrors.ErrEmailNotVerified) {
+			return nil, status.Error(codes.FailedPrecondition, "email is not verified")
+		} else if errors.Is(err, serviceerrors.ErrAccountLocked) {
+			return nil, retryStatus(codes.Unavailable, "ACCOUNT_LOCKED", "account is temporarily locked", err)
+		} else if errors.Is(err, serviceerrors.ErrTooManyAttempts) {
+			return nil, retryStatus(codes.ResourceExhausted, "TOO_MANY_ATTEMPTS", "too many login attempts", err)
 		}
 
 		return nil, status.Error(codes.Internal, "failed to login")
 	}
 
+	if challenge != nil {
+		return &pb.LoginResponse{
+			MfaChallengeId:        challenge.ID,
+			MfaEnrollmentRequired: challenge.EnrollmentRequired,
+		}, nil
+	}
+
 	return &pb.LoginResponse{
-		Token: token,
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt.Unix(),
+	}, nil
+}
+
+func (a *api) CompleteMFALogin(ctx context.Context, req *pb.CompleteMFALoginRequest) (*pb.CompleteMFALoginResponse, error) {
+	if req.MfaChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "mfa challenge id is required")
+	}
+
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	pair, err := a.authRepo.CompleteMFALogin(ctx, req.MfaChallengeId, req.Code)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrInvalidMFAChallenge) {
+			return nil, status.Error(codes.Unauthenticated, "mfa challenge is invalid or expired")
+		}
+		if errors.Is(err, serviceerrors.ErrInvalidMFACode) {
+			return nil, status.Error(codes.Unauthenticated, "invalid two-factor code")
+		}
+		if errors.Is(err, serviceerrors.ErrMFANotEnrolled) {
+			return nil, status.Error(codes.FailedPrecondition, "two-factor authentication enrollment is required")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to login")
+	}
+
+	return &pb.CompleteMFALoginResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt.Unix(),
+	}, nil
+}
+
+func (a *api) BeginTOTPEnrollment(ctx context.Context, req *pb.BeginTOTPEnrollmentRequest) (*pb.BeginTOTPEnrollmentResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" && req.MfaChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "token or mfa challenge id is required")
+	}
+
+	secret, uri, err := a.authRepo.BeginTOTPEnrollment(ctx, token, req.MfaChallengeId)
+	if err != nil {
+		return nil, mfaEnrollmentStatus(err, "failed to begin totp enrollment")
+	}
+
+	return &pb.BeginTOTPEnrollmentResponse{
+		Secret:     secret,
+		OtpauthUri: uri,
+	}, nil
+}
+
+func (a *api) ConfirmTOTPEnrollment(ctx context.Context, req *pb.ConfirmTOTPEnrollmentRequest) (*pb.ConfirmTOTPEnrollmentResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" && req.MfaChallengeId == "" {
+		return nil, status.Error(codes.InvalidArgument, "token or mfa challenge id is required")
+	}
+
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	recoveryCodes, err := a.authRepo.ConfirmTOTPEnrollment(ctx, token, req.MfaChallengeId, req.Code)
+	if err != nil {
+		return nil, mfaEnrollmentStatus(err, "failed to confirm totp enrollment")
+	}
+
+	return &pb.ConfirmTOTPEnrollmentResponse{
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func mfaEnrollmentStatus(err error, fallback string) error {
+	switch {
+	case errors.Is(err, jwt.ErrBadJWT):
+		return jwtStatus(err)
+	case errors.Is(err, serviceerrors.ErrTokenRevoked):
+		return status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+	case errors.Is(err, serviceerrors.ErrInvalidMFAChallenge):
+		return status.Error(codes.Unauthenticated, "mfa challenge is invalid or expired")
+	case errors.Is(err, serviceerrors.ErrInvalidMFACode):
+		return status.Error(codes.InvalidArgument, "invalid two-factor code")
+	case errors.Is(err, serviceerrors.ErrMFAAlreadyEnabled):
+		return status.Error(codes.AlreadyExists, "two-factor authentication is already enabled")
+	case errors.Is(err, serviceerrors.ErrMFANotEnrolled):
+		return status.Error(codes.FailedPrecondition, "totp enrollment was not started")
+	}
+
+	return status.Error(codes.Internal, fallback)
+}
+
+func (a *api) SetMFARequirement(ctx context.Context, req *pb.SetMFARequirementRequest) (*pb.SetMFARequirementResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if req.PermissionLevel == 0 {
+		return nil, status.Error(codes.InvalidArgument, "permission level is required")
+	}
+
+	err := a.authRepo.SetMFARequirement(ctx, token, req.PermissionLevel, req.Required)
+	if err != nil {
+		if errors.Is(err, jwt.ErrBadJWT) {
+			return nil, jwtStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+		}
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to set mfa requirement")
+	}
+
+	return &pb.SetMFARequirementResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	err := a.authRepo.UnlockAccount(ctx, token, req.Email)
+	if err != nil {
+		if errors.Is(err, jwt.ErrBadJWT) {
+			return nil, jwtStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+		}
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to unlock account")
+	}
+
+	return &pb.UnlockAccountResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	pair, err := a.authRepo.RefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrInvalidRefreshToken) {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
+		if errors.Is(err, serviceerrors.ErrRefreshTokenReused) {
+			return nil, status.Error(codes.Unauthenticated, "refresh token was already used, session revoked")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to refresh token")
+	}
+
+	return &pb.RefreshTokenResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.ExpiresAt.Unix(),
 	}, nil
 }
 
@@ -127,9 +597,15 @@ func (a *api) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.Regist
 		if errors.Is(err, storage.ErrUserExists) {
 			return nil, status.Error(codes.AlreadyExists, "user already exists")
 		}
+		if errors.Is(err, storage.ErrSchoolNotFound) {
+			return nil, status.Error(codes.NotFound, "school not found")
+		}
 		if errors.Is(err, serviceerrors.ErrBadEmailFormat) {
 			return nil, status.Error(codes.InvalidArgument, "bad email format")
 		}
+		if errors.Is(err, serviceerrors.ErrWeakPassword) {
+			return nil, passwordPolicyStatus(err, "password")
+		}
 
 		return nil, status.Error(codes.Internal, "failed to register")
 	}
@@ -144,22 +620,171 @@ func (a *api) Validate(ctx context.Context, req *pb.ValidateRequest) (*pb.Valida
 		return nil, status.Error(codes.InvalidArgument, "token is required")
 	}
 
-	uID, err := a.authRepo.Validate(ctx, req.Token)
+	u, err := a.authRepo.Validate(ctx, req.Token)
+	if err != nil {
+		return nil, TokenStatus(err)
+	}
+
+	return &pb.ValidateResponse{
+		UserId: u.ID,
+	}, nil
+}
+
+func (a *api) Introspect(ctx context.Context, req *pb.IntrospectRequest) (*pb.IntrospectResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	info, err := a.authRepo.Introspect(ctx, req.Token)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to introspect token")
+	}
+
+	if !info.Active {
+		return &pb.IntrospectResponse{Active: false}, nil
+	}
+
+	return &pb.IntrospectResponse{
+		Active:          true,
+		UserId:          info.UserID,
+		Email:           info.Email,
+		PermissionLevel: info.PermissionLevel,
+		Classname:       info.Classname,
+		SchoolId:        info.SchoolID,
+		UserActive:      info.UserActive,
+		Exp:             info.ExpiresAt.Unix(),
+		Iat:             info.IssuedAt.Unix(),
+		Jti:             info.TokenID,
+		SessionId:       info.SessionID,
+	}, nil
+}
+
+func (a *api) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
 
+	err := a.authRepo.Logout(ctx, token, req.RefreshToken)
 	if err != nil {
 		if errors.Is(err, jwt.ErrBadJWT) {
-			return nil, status.Error(codes.Unauthenticated, "invalid JWT")
+			return nil, jwtStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+		}
+		if errors.Is(err, serviceerrors.ErrInvalidRefreshToken) {
+			return nil, status.Error(codes.InvalidArgument, "invalid refresh token")
 		}
 
-		return nil, status.Error(codes.Internal, "failed to validate JWT")
+		return nil, status.Error(codes.Internal, "failed to logout")
 	}
 
-	return &pb.ValidateResponse{
-		UserId: uID,
+	return &pb.LogoutResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	err := a.authRepo.RevokeAllSessions(ctx, token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrBadJWT) {
+			return nil, jwtStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to revoke sessions")
+	}
+
+	return &pb.RevokeAllSessionsResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	sessions, current, err := a.authRepo.ListSessions(ctx, token, req.UserId)
+	if err != nil {
+		if errors.Is(err, jwt.ErrBadJWT) {
+			return nil, jwtStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+		}
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	return &pb.ListSessionsResponse{
+		Sessions: utils.ConvertSessions(sessions, current),
+	}, nil
+}
+
+func (a *api) TerminateSession(ctx context.Context, req *pb.TerminateSessionRequest) (*pb.TerminateSessionResponse, error) {
+	token := callerToken(ctx, req.Token)
+
+	if token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id is required")
+	}
+
+	err := a.authRepo.TerminateSession(ctx, token, req.SessionId)
+	if err != nil {
+		if errors.Is(err, jwt.ErrBadJWT) {
+			return nil, jwtStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+		}
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to terminate session")
+	}
+
+	return &pb.TerminateSessionResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	set := a.authRepo.GetJWKS(ctx)
+
+	return &pb.GetJWKSResponse{
+		Keys: utils.ConvertJWKs(set.Keys),
 	}, nil
 }
 
 func (a *api) UpdatePassword(ctx context.Context, req *pb.UpdatePasswordRequest) (*pb.UpdatePasswordResponse, error) {
+	token := callerToken(ctx, req.Token)
+
 	if req.NewPassword == "" {
 		return &pb.UpdatePasswordResponse{Status: http.StatusBadRequest}, status.Error(codes.InvalidArgument, "new password is required")
 	}
@@ -168,7 +793,7 @@ func (a *api) UpdatePassword(ctx context.Context, req *pb.UpdatePasswordRequest)
 		return nil, status.Error(codes.InvalidArgument, "old password is required")
 	}
 
-	if req.Token == "" {
+	if token == "" {
 		return nil, status.Error(codes.InvalidArgument, "token is required")
 	}
 
@@ -176,11 +801,20 @@ func (a *api) UpdatePassword(ctx context.Context, req *pb.UpdatePasswordRequest)
 		return nil, status.Error(codes.InvalidArgument, "email is required")
 	}
 
-	err := a.authRepo.ChangePassword(ctx, req.Email, req.OldPassword, req.NewPassword, req.Token)
+	err := a.authRepo.ChangePassword(ctx, req.Email, req.OldPassword, req.NewPassword, token)
 	if err != nil {
+		if errors.Is(err, jwt.ErrBadJWT) {
+			return nil, jwtStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+			return nil, status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+		}
 		if errors.Is(err, serviceerrors.ErrInvalidCredentials) {
 			return nil, status.Error(codes.Unauthenticated, "old password is incorrect")
 		}
+		if errors.Is(err, serviceerrors.ErrWeakPassword) {
+			return nil, passwordPolicyStatus(err, "new_password")
+		}
 		if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.NotFound, "user not found")
 		}
@@ -192,6 +826,79 @@ func (a *api) UpdatePassword(ctx context.Context, req *pb.UpdatePasswordRequest)
 	}, nil
 }
 
+func (a *api) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if err := a.authRepo.RequestPasswordReset(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, "failed to request password reset")
+	}
+
+	return &pb.RequestPasswordResetResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) ConfirmPasswordReset(ctx context.Context, req *pb.ConfirmPasswordResetRequest) (*pb.ConfirmPasswordResetResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if req.NewPassword == "" {
+		return nil, status.Error(codes.InvalidArgument, "new password is required")
+	}
+
+	err := a.authRepo.ConfirmPasswordReset(ctx, req.Token, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrInvalidResetToken) {
+			return nil, status.Error(codes.InvalidArgument, "reset token is invalid or expired")
+		}
+		if errors.Is(err, serviceerrors.ErrWeakPassword) {
+			return nil, passwordPolicyStatus(err, "new_password")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to reset password")
+	}
+
+	return &pb.ConfirmPasswordResetResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	err := a.authRepo.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrInvalidVerification) {
+			return nil, status.Error(codes.InvalidArgument, "verification token is invalid or expired")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to verify email")
+	}
+
+	return &pb.VerifyEmailResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) ResendVerification(ctx context.Context, req *pb.ResendVerificationRequest) (*pb.ResendVerificationResponse, error) {
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if err := a.authRepo.ResendVerification(ctx, req.Email); err != nil {
+		return nil, status.Error(codes.Internal, "failed to resend verification")
+	}
+
+	return &pb.ResendVerificationResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
 func (a *api) SetPermissionLevel(ctx context.Context, req *pb.SetPermissionLevelRequest) (*pb.SetPermissionLevelResponse, error) {
 	if req.PermissionLevel == 0 {
 		return nil, status.Error(codes.InvalidArgument, "permission level is required")
@@ -201,11 +908,12 @@ func (a *api) SetPermissionLevel(ctx context.Context, req *pb.SetPermissionLevel
 		return nil, status.Error(codes.InvalidArgument, "user id is required")
 	}
 
-	if req.InitiatorId == 0 {
-		return nil, status.Error(codes.InvalidArgument, "initiator id is required")
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	err := a.authRepo.SetPermissionLevel(ctx, req.UserId, req.PermissionLevel, req.InitiatorId)
+	err = a.authRepo.SetPermissionLevel(ctx, req.UserId, req.PermissionLevel, initiatorID)
 	if err != nil {
 		if errors.Is(err, serviceerrors.ErrAccessDenied) {
 			return nil, status.Error(codes.PermissionDenied, "permission denied")
@@ -213,6 +921,9 @@ func (a *api) SetPermissionLevel(ctx context.Context, req *pb.SetPermissionLevel
 		if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.NotFound, "user not found")
 		}
+		if errors.Is(err, storage.ErrRoleNotFound) {
+			return nil, status.Error(codes.InvalidArgument, "unknown permission level")
+		}
 		return nil, status.Error(codes.Internal, "failed to set permissions")
 	}
 
@@ -244,10 +955,15 @@ func (a *api) FillUserProfile(ctx context.Context, req *pb.FillUserProfileReques
 		return nil, status.Error(codes.InvalidArgument, "classname is required")
 	}
 
-	if req.DateOfBirth == "" {
+	if req.DateOfBirth == "" && req.BirthDate == nil {
 		return nil, status.Error(codes.InvalidArgument, "date of birth is required")
 	}
 
+	dateOfBirth, err := birthDate(req.BirthDate, req.DateOfBirth)
+	if err != nil {
+		return nil, err
+	}
+
 	if req.Middlename == "" {
 		return nil, status.Error(codes.InvalidArgument, "middlename is required")
 	}
@@ -260,12 +976,33 @@ func (a *api) FillUserProfile(ctx context.Context, req *pb.FillUserProfileReques
 		return nil, status.Error(codes.InvalidArgument, "lastname is required")
 	}
 
-	if req.UserId == 0 {
-		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := req.UserId
+	if userID == 0 {
+		userID = initiatorID
 	}
 
-	err := a.userRepo.FillUserProfile(ctx, req.Name, req.Lastname, req.Middlename, req.DateOfBirth, req.Classname, req.UserId)
+	err = a.userRepo.FillUserProfile(ctx, initiatorID, req.Name, req.Lastname, req.Middlename, dateOfBirth, req.Classname, userID)
 	if err != nil {
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		if errors.Is(err, serviceerrors.ErrInvalidProfile) {
+			return nil, profileStatus(err)
+		}
+		if errors.Is(err, serviceerrors.ErrClassNotFound) {
+			return nil, status.Error(codes.InvalidArgument, "class not found")
+		}
+		if errors.Is(err, serviceerrors.ErrClassFull) {
+			return nil, status.Error(codes.FailedPrecondition, "class is full")
+		}
+		if errors.Is(err, serviceerrors.ErrAccountActive) {
+			return nil, status.Error(codes.FailedPrecondition, "account is already active, use UpdateUserProfile")
+		}
 		if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.NotFound, "user not found")
 		}
@@ -277,17 +1014,141 @@ func (a *api) FillUserProfile(ctx context.Context, req *pb.FillUserProfileReques
 	}, nil
 }
 
+func (a *api) GetUserProfile(ctx context.Context, req *pb.GetUserProfileRequest) (*pb.GetUserProfileResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := req.UserId
+	if userID == 0 {
+		userID = initiatorID
+	}
+
+	user, err := a.userRepo.GetUserProfile(ctx, initiatorID, userID)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user profile")
+	}
+
+	return &pb.GetUserProfileResponse{
+		Profile: utils.ConvertUserRecord(user),
+	}, nil
+}
+
+func (a *api) UpdateUserProfile(ctx context.Context, req *pb.UpdateUserProfileRequest) (*pb.UpdateUserProfileResponse, error) {
+	if len(req.GetUpdateMask().GetPaths()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "update mask is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := req.UserId
+	if userID == 0 {
+		userID = initiatorID
+	}
+
+	profile := req.GetProfile()
+	update := models.UserInfo{
+		Name:       profile.GetName(),
+		Lastname:   profile.GetLastname(),
+		Middlename: profile.GetMiddlename(),
+		Classname:  profile.GetClassname(),
+	}
+
+	// birth_date is another form of date_of_birth, so both paths set it.
+	fields := make([]string, 0, len(req.UpdateMask.Paths))
+	for _, path := range req.UpdateMask.Paths {
+		if path == "birth_date" {
+			path = models.ProfileDateOfBirth
+		}
+		if path == models.ProfileDateOfBirth {
+			if update.DateOfBirth, err = birthDate(profile.GetBirthDate(), profile.GetDateOfBirth()); err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, path)
+	}
+
+	user, err := a.userRepo.UpdateUserProfile(ctx, initiatorID, userID, update, fields, req.OverrideLock)
+	if err != nil {
+		switch {
+		case errors.Is(err, serviceerrors.ErrAccessDenied):
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		case errors.Is(err, serviceerrors.ErrInvalidProfile):
+			return nil, profileStatus(err)
+		case errors.Is(err, serviceerrors.ErrProfileLocked):
+			return nil, status.Error(codes.FailedPrecondition, "profile is locked")
+		case errors.Is(err, serviceerrors.ErrClassNotFound):
+			return nil, status.Error(codes.InvalidArgument, "class not found")
+		case errors.Is(err, serviceerrors.ErrClassFull):
+			return nil, status.Error(codes.FailedPrecondition, "class is full")
+		case errors.Is(err, serviceerrors.ErrActivationBlocked):
+			return nil, activationStatus(err)
+		case errors.Is(err, storage.ErrUserNotFound):
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to update user profile")
+	}
+
+	return &pb.UpdateUserProfileResponse{
+		Profile: utils.ConvertUserRecord(user),
+	}, nil
+}
+
+func (a *api) LockUserProfile(ctx context.Context, req *pb.LockUserProfileRequest) (*pb.LockUserProfileResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.userRepo.LockUserProfile(ctx, initiatorID, req.UserId, req.Locked)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to lock user profile")
+	}
+
+	return &pb.LockUserProfileResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
 func (a *api) ChangeUserStatus(ctx context.Context, req *pb.ChangeUserStatusRequest) (*pb.ChangeUserStatusResponse, error) {
 	if req.UserId == 0 {
 		return nil, status.Error(codes.InvalidArgument, "user id is required")
 	}
 
-	if req.InitiatorId == 0 {
-		return nil, status.Error(codes.InvalidArgument, "initiator id is required")
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	err := a.userRepo.ChangeUserStatus(ctx, req.UserId, req.Active, req.InitiatorId)
+	err = a.userRepo.ChangeUserStatus(ctx, req.UserId, req.Active, initiatorID)
 	if err != nil {
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		if errors.Is(err, serviceerrors.ErrActivationBlocked) {
+			return nil, activationStatus(err)
+		}
 		if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.NotFound, "user not found")
 		}
@@ -320,11 +1181,19 @@ func (a *api) IsUserActive(ctx context.Context, req *pb.IsUserActiveRequest) (*p
 
 func (a *api) GetStudentsByClassname(ctx context.Context, req *pb.GetStudentsByClassnameRequest) (*pb.GetStudentsByClassnameResponse, error) {
 	if req.Classname == "" {
-		return nil, status.Error(codes.InvalidArgument, "user id is required")
+		return nil, status.Error(codes.InvalidArgument, "classname is required")
 	}
 
-	students, err := a.userRepo.GetStudentsByClassname(ctx, req.Classname)
+	initiatorID, err := initiator(ctx)
 	if err != nil {
+		return nil, err
+	}
+
+	students, err := a.userRepo.GetStudentsByClassname(ctx, initiatorID, req.Classname)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
 		if errors.Is(err, serviceerrors.ErrClassNotFound) {
 			return nil, status.Error(codes.NotFound, "class not found")
 		}
@@ -337,13 +1206,115 @@ func (a *api) GetStudentsByClassname(ctx context.Context, req *pb.GetStudentsByC
 	}, nil
 }
 
+func (a *api) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	active, err := activeFilter(req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CreatedAfter < 0 || req.CreatedBefore < 0 {
+		return nil, status.Error(codes.InvalidArgument, "created range must not be negative")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := user.ListQuery{
+		Filter: models.UserFilter{
+			EmailPrefix:     req.EmailPrefix,
+			Name:            req.Name,
+			Role:            req.Role,
+			PermissionLevel: req.PermissionLevel,
+			Active:          active,
+		},
+		Classname: req.Classname,
+		OrderBy:   req.OrderBy,
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	}
+	if req.CreatedAfter != 0 {
+		q.Filter.CreatedAfter = time.Unix(req.CreatedAfter, 0)
+	}
+	if req.CreatedBefore != 0 {
+		q.Filter.CreatedBefore = time.Unix(req.CreatedBefore, 0)
+	}
+
+	users, next, total, err := a.userRepo.ListUsers(ctx, initiatorID, q)
+	if err != nil {
+		switch {
+		case errors.Is(err, serviceerrors.ErrAccessDenied):
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		case errors.Is(err, serviceerrors.ErrInvalidUserQuery):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, serviceerrors.ErrClassNotFound):
+			return nil, status.Error(codes.NotFound, "class not found")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to list users")
+	}
+
+	return &pb.ListUsersResponse{
+		Users:         utils.ConvertUserRecords(users),
+		NextPageToken: next,
+		TotalCount:    total,
+	}, nil
+}
+
+// birthDate reads a date of birth given as a google.type.Date or as an ISO
+// 8601 string; d wins when both are set, and neither means no date.
+// Impossible dates are reported as a field violation.
+func birthDate(d *date.Date, s string) (time.Time, error) {
+	var (
+		t   time.Time
+		err error
+	)
+	switch {
+	case d != nil:
+		t, err = birthdate.FromParts(int(d.Year), int(d.Month), int(d.Day))
+	case s != "":
+		t, err = birthdate.Parse(s)
+	}
+	if err != nil {
+		return time.Time{}, profileStatus(&serviceerrors.ProfileError{Violations: []serviceerrors.FieldViolation{
+			{Field: models.ProfileDateOfBirth, Message: err.Error()},
+		}})
+	}
+
+	return t, nil
+}
+
+// activeFilter converts the status filter of a request, "active",
+// "inactive" or empty, into models.UserFilter.Active.
+func activeFilter(s string) (*bool, error) {
+	switch s {
+	case "":
+		return nil, nil
+	case "active", "inactive":
+		active := s == "active"
+
+		return &active, nil
+	}
+
+	return nil, status.Error(codes.InvalidArgument, "status must be active or inactive")
+}
+
 func (a *api) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
 	if req.UserId == 0 {
 		return nil, status.Error(codes.InvalidArgument, "user id is required")
 	}
 
-	err := a.userRepo.DeleteUser(ctx, req.UserId)
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.userRepo.DeleteUser(ctx, initiatorID, req.UserId)
 	if err != nil {
+		if errors.Is(err, serviceerrors.ErrAccessDenied) {
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
 		if errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Error(codes.NotFound, "user not found")
 		}
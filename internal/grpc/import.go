@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+	"AuthService/internal/services/imports"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/utils"
+	"bytes"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxImportSize limits the size of an imported file.
+const maxImportSize = 10 << 20
+
+func (a *api) BulkImportUsers(stream pb.UserService_BulkImportUsersServer) error {
+	ctx := stream.Context()
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		format string
+		dryRun bool
+		data   bytes.Buffer
+		rows   []models.ImportRow
+		first  = true
+	)
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if first {
+			format, dryRun = req.Format, req.DryRun
+			first = false
+		}
+
+		if data.Len()+len(req.Data) > maxImportSize {
+			return status.Error(codes.InvalidArgument, "file is too large")
+		}
+		// Rows are counted as they arrive, so that a client cannot make
+		// the server hold an unbounded import before it is rejected.
+		if len(rows)+len(req.Rows) > imports.MaxRows {
+			return status.Errorf(codes.InvalidArgument, "an import may hold at most %d rows", imports.MaxRows)
+		}
+		data.Write(req.Data)
+		rows = append(rows, utils.ConvertImportRows(req.Rows)...)
+	}
+
+	if data.Len() > 0 {
+		if len(rows) > 0 {
+			return status.Error(codes.InvalidArgument, "send either a file or rows")
+		}
+
+		if format == "" {
+			return status.Error(codes.InvalidArgument, "format is required")
+		}
+
+		rows, err = imports.ParseRows(format, data.Bytes())
+		if err != nil {
+			return importStatus(err, "failed to read file")
+		}
+	}
+
+	results, err := a.importRepo.ImportUsers(ctx, initiatorID, rows, dryRun)
+	if err != nil {
+		return importStatus(err, "failed to import users")
+	}
+
+	resp := &pb.BulkImportUsersResponse{
+		Results: utils.ConvertImportResults(results),
+		DryRun:  dryRun,
+	}
+	for _, r := range results {
+		switch r.Status {
+		case models.ImportCreated:
+			resp.Created++
+		case models.ImportInvalid:
+			resp.Invalid++
+		}
+	}
+
+	return stream.SendAndClose(resp)
+}
+
+func importStatus(err error, fallback string) error {
+	switch {
+	case errors.Is(err, serviceerrors.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, serviceerrors.ErrInvalidImport):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, storage.ErrUserExists):
+		// Another request registered one of the emails after the check.
+		return status.Error(codes.Aborted, "an imported email was registered meanwhile, retry the import")
+	case errors.Is(err, storage.ErrClassFull):
+		// Another request took the last seats of a class after the check.
+		return status.Error(codes.Aborted, "a class of the import filled up meanwhile, retry the import")
+	}
+
+	return status.Error(codes.Internal, fallback)
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/jwt"
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	UserID int64
+	Token  string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored in ctx by WithPrincipal.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+
+	return p, ok
+}
+
+// TokenStatus converts an error returned by AuthRepo.Validate into a
+// status for the client.
+func TokenStatus(err error) error {
+	if errors.Is(err, jwt.ErrBadJWT) {
+		return jwtStatus(err)
+	}
+	if errors.Is(err, serviceerrors.ErrTokenRevoked) {
+		return status.Error(codes.Unauthenticated, "invalid JWT: token has been revoked")
+	}
+	if errors.Is(err, storage.ErrUserNotFound) {
+		return status.Error(codes.Unauthenticated, "invalid JWT: user not found")
+	}
+
+	return status.Error(codes.Internal, "failed to validate JWT")
+}
+
+// callerToken returns the token the caller authenticated with, or token
+// from the request body when the call carried no principal.
+func callerToken(ctx context.Context, token string) string {
+	if p, ok := PrincipalFrom(ctx); ok && p.Token != "" {
+		return p.Token
+	}
+
+	return token
+}
+
+// initiator returns the ID of the authenticated caller.
+func initiator(ctx context.Context) (int64, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "authentication is required")
+	}
+
+	return p.UserID, nil
+}
@@ -0,0 +1,194 @@
+package grpc
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/utils"
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *api) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.AssignRoleResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+
+	if req.Role == "" {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.rbacRepo.AssignRole(ctx, initiatorID, req.UserId, req.Role); err != nil {
+		return nil, rbacStatus(err, "failed to assign role")
+	}
+
+	return &pb.AssignRoleResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) RevokeRole(ctx context.Context, req *pb.RevokeRoleRequest) (*pb.RevokeRoleResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+
+	if req.Role == "" {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.rbacRepo.RevokeRole(ctx, initiatorID, req.UserId, req.Role); err != nil {
+		return nil, rbacStatus(err, "failed to revoke role")
+	}
+
+	return &pb.RevokeRoleResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := a.rbacRepo.ListRoles(ctx, initiatorID, req.UserId)
+	if err != nil {
+		return nil, rbacStatus(err, "failed to list roles")
+	}
+
+	return &pb.ListRolesResponse{
+		Roles: utils.ConvertRoles(roles),
+	}, nil
+}
+
+func (a *api) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	if req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "permission is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := req.UserId
+	if userID == 0 {
+		userID = initiatorID
+	}
+
+	allowed, err := a.rbacRepo.CheckPermission(ctx, initiatorID, userID, req.Permission)
+	if err != nil {
+		return nil, rbacStatus(err, "failed to check permission")
+	}
+
+	return &pb.CheckPermissionResponse{
+		Allowed: allowed,
+	}, nil
+}
+
+func (a *api) GrantScopedRole(ctx context.Context, req *pb.GrantScopedRoleRequest) (*pb.GrantScopedRoleResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+
+	if req.Role == "" {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	if req.ScopeType == "" {
+		return nil, status.Error(codes.InvalidArgument, "scope type is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.ScopedRole{Role: req.Role, ScopeType: req.ScopeType, ScopeValue: req.ScopeValue}
+	if err = a.rbacRepo.GrantScopedRole(ctx, initiatorID, req.UserId, role); err != nil {
+		return nil, rbacStatus(err, "failed to grant scoped role")
+	}
+
+	return &pb.GrantScopedRoleResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) RevokeScopedRole(ctx context.Context, req *pb.RevokeScopedRoleRequest) (*pb.RevokeScopedRoleResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+
+	if req.Role == "" {
+		return nil, status.Error(codes.InvalidArgument, "role is required")
+	}
+
+	if req.ScopeType == "" {
+		return nil, status.Error(codes.InvalidArgument, "scope type is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.ScopedRole{Role: req.Role, ScopeType: req.ScopeType, ScopeValue: req.ScopeValue}
+	if err = a.rbacRepo.RevokeScopedRole(ctx, initiatorID, req.UserId, role); err != nil {
+		return nil, rbacStatus(err, "failed to revoke scoped role")
+	}
+
+	return &pb.RevokeScopedRoleResponse{
+		Status: http.StatusOK,
+	}, nil
+}
+
+func (a *api) ListScopedRoles(ctx context.Context, req *pb.ListScopedRolesRequest) (*pb.ListScopedRolesResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := req.UserId
+	if userID == 0 {
+		userID = initiatorID
+	}
+
+	roles, err := a.rbacRepo.ListScopedRoles(ctx, initiatorID, userID)
+	if err != nil {
+		return nil, rbacStatus(err, "failed to list scoped roles")
+	}
+
+	return &pb.ListScopedRolesResponse{
+		Roles: utils.ConvertScopedRoles(roles),
+	}, nil
+}
+
+func rbacStatus(err error, fallback string) error {
+	switch {
+	case errors.Is(err, serviceerrors.ErrInvalidScope):
+		return status.Error(codes.InvalidArgument, "invalid scope")
+	case errors.Is(err, serviceerrors.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, storage.ErrRoleNotFound):
+		return status.Error(codes.NotFound, "role not found")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	}
+
+	return status.Error(codes.Internal, fallback)
+}
@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"AuthService/internal/pb"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/utils"
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *api) CreateSchool(ctx context.Context, req *pb.CreateSchoolRequest) (*pb.CreateSchoolResponse, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	school, err := a.schoolRepo.CreateSchool(ctx, initiatorID, req.Name)
+	if err != nil {
+		return nil, schoolStatus(err, "failed to create school")
+	}
+
+	return &pb.CreateSchoolResponse{
+		School: utils.ConvertSchool(school),
+	}, nil
+}
+
+func (a *api) ListSchools(ctx context.Context, req *pb.ListSchoolsRequest) (*pb.ListSchoolsResponse, error) {
+	initiatorID, err := initiator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schools, err := a.schoolRepo.ListSchools(ctx, initiatorID)
+	if err != nil {
+		return nil, schoolStatus(err, "failed to list schools")
+	}
+
+	return &pb.ListSchoolsResponse{
+		Schools: utils.ConvertSchools(schools),
+	}, nil
+}
+
+func schoolStatus(err error, fallback string) error {
+	switch {
+	case errors.Is(err, serviceerrors.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, storage.ErrSchoolExists):
+		return status.Error(codes.AlreadyExists, "school already exists")
+	}
+
+	return status.Error(codes.Internal, fallback)
+}
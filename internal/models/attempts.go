@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// LoginAttempts counts the recent failed logins made for one account or
+// from one client address.
+type LoginAttempts struct {
+	Failures    int
+	LastFailure time.Time
+}
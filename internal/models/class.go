@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Class is a class of students in one school year, such as 9A in 2023/24.
+// AcademicYear is the year the school year starts in.
+type Class struct {
+	ID                int64
+	SchoolID          int64
+	Name              string
+	Grade             int64
+	Letter            string
+	AcademicYear      int64
+	HomeroomTeacherID int64
+	Capacity          int64
+	Students          int64
+	ArchivedAt        time.Time
+}
+
+// Fields of a class, as update masks name them.
+const (
+	ClassHomeroomTeacher = "homeroom_teacher_id"
+	ClassCapacity        = "capacity"
+)
+
+// Archived reports whether the class is closed to new students.
+func (c Class) Archived() bool {
+	return !c.ArchivedAt.IsZero()
+}
+
+// ClassFilter selects the classes returned by ListClasses. Zero fields
+// match any class.
+type ClassFilter struct {
+	AcademicYear    int64
+	Grade           int64
+	IncludeArchived bool
+}
+
+// ClassMembership is a stay of a student in a class. LeftAt is zero for the
+// class the student is in now.
+type ClassMembership struct {
+	UserID       int64
+	ClassID      int64
+	ClassName    string
+	AcademicYear int64
+	JoinedAt     time.Time
+	LeftAt       time.Time
+}
+
+// ClassPromotion moves the students of From to To at the end of a school
+// year. To is created when it has no ID; when Graduate is set the students
+// leave school instead.
+type ClassPromotion struct {
+	From     Class
+	To       Class
+	Graduate bool
+}
+
+// Outcomes of a StudentPromotion.
+const (
+	PromotionPromoted    = "promoted"
+	PromotionGraduated   = "graduated"
+	PromotionDeactivated = "deactivated"
+)
+
+// StudentPromotion reports what a promotion does to one student.
+type StudentPromotion struct {
+	UserID    int64
+	Name      string
+	Lastname  string
+	FromClass string
+	ToClass   string
+	Outcome   string
+}
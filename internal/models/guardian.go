@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Relationships of a guardian to a student.
+const (
+	RelationshipMother   = "mother"
+	RelationshipFather   = "father"
+	RelationshipGuardian = "guardian"
+	RelationshipOther    = "other"
+)
+
+// GuardianLink says that GuardianID may act for the student StudentID.
+// Primary marks the first person to contact; a student has at most one.
+type GuardianLink struct {
+	GuardianID   int64
+	StudentID    int64
+	Relationship string
+	Primary      bool
+	CreatedAt    time.Time
+	// ApprovedBy is the holder of guardians.manage who linked the guardian
+	// or approved the link, at ApprovedAt. Links made with an invite are
+	// not approved; only approved links count as consent of a parent.
+	ApprovedBy int64
+	ApprovedAt time.Time
+	// Other is the profile of the other side of the link in listings: the
+	// guardian in ListGuardians and the student in ListChildren.
+	Other UserInfo
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ImportRow is one row of a bulk user import. Line is the line of the row
+// in the imported file, counting the header, so that admins can find it.
+type ImportRow struct {
+	Line        int64
+	Email       string
+	Name        string
+	Lastname    string
+	Middlename  string
+	DateOfBirth string
+	Classname   string
+	Role        string
+}
+
+// Outcomes of an import row.
+const (
+	ImportValid   = "valid"
+	ImportCreated = "created"
+	ImportInvalid = "invalid"
+)
+
+// ImportResult reports what happened to an import row. Rows of a dry run
+// end up valid or invalid; other imports create every valid row. Accounts
+// with ActivationRequirements are created inactive.
+type ImportResult struct {
+	Line                   int64
+	Email                  string
+	UserID                 int64
+	Status                 string
+	Errors                 []string
+	ActivationRequirements []string
+}
+
+// ImportedUser is an account created by a bulk import. The user sets their
+// password through the invite, which works like a password reset token.
+type ImportedUser struct {
+	Profile         UserInfo
+	Email           string
+	Role            string
+	Active          bool
+	PassHash        []byte
+	InviteHash      string
+	InviteExpiresAt time.Time
+}
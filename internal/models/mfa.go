@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// TOTP is the authenticator app enrolled by a user. It only protects logins
+// once ConfirmedAt is set.
+type TOTP struct {
+	UserID      int64
+	Secret      string
+	ConfirmedAt time.Time
+	// LastStep is the time step of the last accepted code; codes of that
+	// step and earlier are rejected to prevent replays.
+	LastStep int64
+}
+
+// MFAChallenge is a login that passed the password check and waits for a
+// second factor. ID is only known to the client; storage keeps its hash.
+type MFAChallenge struct {
+	ID        string
+	UserID    int64
+	Device    Device
+	Attempts  int
+	ExpiresAt time.Time
+	// EnrollmentRequired is set when the user must enroll TOTP before the
+	// challenge can be completed.
+	EnrollmentRequired bool
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type RevokedToken struct {
+	TokenID   string
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+// UserRevocation invalidates every token of a user issued before
+// RevokedBefore. It is kept until ExpiresAt, when all such tokens would
+// have expired on their own.
+type UserRevocation struct {
+	UserID        int64
+	RevokedBefore time.Time
+	ExpiresAt     time.Time
+}
@@ -0,0 +1,25 @@
+package models
+
+// Role is a named set of permissions. Level orders the roles: a user may only
+// hand out roles up to their own highest level.
+type Role struct {
+	Name        string
+	Level       int64
+	Description string
+	Permissions []string
+}
+
+// Scope types of a ScopedRole.
+const (
+	ScopeClass  = "class"
+	ScopeGrade  = "grade"
+	ScopeSchool = "school"
+)
+
+// ScopedRole grants a role over the students of one class, of every class
+// of a grade, or of a whole school.
+type ScopedRole struct {
+	Role       string
+	ScopeType  string
+	ScopeValue string
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// School is a tenant of the service. Users and classes belong to one school.
+type School struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Device describes where a login came from.
+type Device struct {
+	Label     string
+	UserAgent string
+	IP        string
+}
+
+// Session is one login of a user. Its ID is shared by the refresh token
+// family and carried in the sid claim of every access token issued for it.
+type Session struct {
+	ID           string
+	UserID       int64
+	Device       Device
+	CreatedAt    time.Time
+	LastSeenAt   time.Time
+	ExpiresAt    time.Time
+	TerminatedAt time.Time
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	FamilyID  string
+	ExpiresAt time.Time
+	RotatedAt time.Time
+	RevokedAt time.Time
+}
+
+type Introspection struct {
+	Active          bool
+	UserID          int64
+	Email           string
+	PermissionLevel int64
+	Classname       string
+	SchoolID        int64
+	UserActive      bool
+	ExpiresAt       time.Time
+	IssuedAt        time.Time
+	TokenID         string
+	SessionID       string
+}
@@ -1,17 +1,105 @@
 package models
 
+import "time"
+
 type User struct {
 	ID              int64  `json:"id"`
 	Email           string `json:"email"`
 	PassHash        string `json:"password"`
 	PermissionLevel int64  `json:"permission_level"`
+	EmailVerified   bool   `json:"email_verified"`
+	SchoolID        int64  `json:"school_id"`
 }
 
+// UserInfo is the profile of a user. DateOfBirth is midnight UTC of the
+// day, or the zero time when it is not known.
 type UserInfo struct {
-	ID          int64
-	Name        string
-	Lastname    string
-	Middlename  string
-	DateOfBirth string
-	Classname   string
+	ID            int64
+	Name          string
+	Lastname      string
+	Middlename    string
+	DateOfBirth   time.Time
+	Classname     string
+	ClassID       int64
+	ProfileLocked bool
+}
+
+// Fields of a profile, as update masks name them.
+const (
+	ProfileName        = "name"
+	ProfileLastname    = "lastname"
+	ProfileMiddlename  = "middlename"
+	ProfileDateOfBirth = "date_of_birth"
+	ProfileClassname   = "classname"
+)
+
+// UserRecord is a user as listings and exports show them. Roles are sorted
+// by name. GuardianConsent tells that the consent of a parent was recorded
+// or that an approved guardian is linked. ActivationRequirements are filled
+// in by the services from their activation policies.
+type UserRecord struct {
+	Profile                UserInfo
+	Email                  string
+	Roles                  []string
+	PermissionLevel        int64
+	Active                 bool
+	EmailVerified          bool
+	SchoolID               int64
+	CreatedAt              time.Time
+	GuardianConsent        bool
+	ActivationRequirements []string
+}
+
+// UserFilter selects the users of a listing or an export. Zero fields match
+// any user; Active matches both states when nil. Name matches users whose
+// name, lastname or middlename contains it. CreatedBefore is exclusive.
+type UserFilter struct {
+	EmailPrefix     string
+	Name            string
+	ClassID         int64
+	Role            string
+	PermissionLevel int64
+	Active          *bool
+	SchoolID        int64
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+}
+
+// Sort orders of user listings. Ties are broken by user ID.
+const (
+	SortUsersByLastname  = "lastname"
+	SortUsersByEmail     = "email"
+	SortUsersByCreatedAt = "created_at"
+	SortUsersByID        = "id"
+)
+
+// UserPage selects a page of a user listing: up to Limit users in the
+// order of SortBy that come after the user at After.
+type UserPage struct {
+	SortBy     string
+	Descending bool
+	After      *UserCursor
+	Limit      int
+}
+
+// UserCursor is the position of a user in a listing: the values of the
+// sort columns, as the storage compares them, and the user ID.
+type UserCursor struct {
+	Values []string
+	ID     int64
+}
+
+// Cursor returns the position of u in a listing sorted by sortBy.
+func (u UserRecord) Cursor(sortBy string) UserCursor {
+	c := UserCursor{ID: u.Profile.ID}
+	switch sortBy {
+	case SortUsersByLastname:
+		c.Values = []string{u.Profile.Lastname, u.Profile.Name}
+	case SortUsersByEmail:
+		c.Values = []string{u.Email}
+	case SortUsersByCreatedAt:
+		c.Values = []string{u.CreatedAt.UTC().Format(time.DateTime)}
+	}
+
+	return c
 }
@@ -0,0 +1,52 @@
+// Package agepolicy holds the rules that depend on the age of a user.
+package agepolicy
+
+import (
+	"AuthService/internal/models"
+	"AuthService/pkg/tools/birthdate"
+	"slices"
+	"time"
+)
+
+// StudentRole is the role the rules apply to.
+const StudentRole = "student"
+
+// Requirements an account may have to meet before it is activated. They are
+// reported to clients, so the values must stay stable.
+const (
+	// RequireDateOfBirth is reported for students whose age is unknown.
+	RequireDateOfBirth = "date_of_birth"
+	// RequireGuardianConsent is reported for students under the guardian
+	// age that have neither a recorded consent nor an approved guardian.
+	RequireGuardianConsent = "guardian_consent"
+)
+
+// Policy checks accounts before activation. Students younger than
+// GuardianAge need the recorded consent of a parent or a guardian link
+// approved by a holder of guardians.manage; a GuardianAge of 0 turns the
+// rule off.
+type Policy struct {
+	GuardianAge int
+}
+
+func New(guardianAge int) *Policy {
+	return &Policy{GuardianAge: guardianAge}
+}
+
+// Requirements returns what the account of user still lacks on the day of
+// now. Accounts with requirements must not be activated.
+func (p *Policy) Requirements(user models.UserRecord, now time.Time) []string {
+	if p.GuardianAge <= 0 || !slices.Contains(user.Roles, StudentRole) {
+		return nil
+	}
+
+	if user.Profile.DateOfBirth.IsZero() {
+		return []string{RequireDateOfBirth}
+	}
+
+	if birthdate.Age(user.Profile.DateOfBirth, now) < p.GuardianAge && !user.GuardianConsent {
+		return []string{RequireGuardianConsent}
+	}
+
+	return nil
+}
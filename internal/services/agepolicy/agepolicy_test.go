@@ -0,0 +1,69 @@
+package agepolicy
+
+import (
+	"AuthService/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Requirements(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+	student := func(born time.Time, consent bool) models.UserRecord {
+		return models.UserRecord{
+			Profile:         models.UserInfo{DateOfBirth: born},
+			Roles:           []string{StudentRole},
+			GuardianConsent: consent,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		policy *Policy
+		user   models.UserRecord
+		want   []string
+	}{
+		{
+			name:   "under age without consent",
+			policy: New(14),
+			user:   student(time.Date(2010, time.May, 11, 0, 0, 0, 0, time.UTC), false),
+			want:   []string{RequireGuardianConsent},
+		},
+		{
+			name:   "under age with consent",
+			policy: New(14),
+			user:   student(time.Date(2010, time.May, 11, 0, 0, 0, 0, time.UTC), true),
+		},
+		{
+			name:   "of age on the birthday",
+			policy: New(14),
+			user:   student(time.Date(2010, time.May, 10, 0, 0, 0, 0, time.UTC), false),
+		},
+		{
+			name:   "unknown date of birth",
+			policy: New(14),
+			user:   student(time.Time{}, true),
+			want:   []string{RequireDateOfBirth},
+		},
+		{
+			name:   "not a student",
+			policy: New(14),
+			user: models.UserRecord{
+				Profile: models.UserInfo{DateOfBirth: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)},
+				Roles:   []string{"teacher"},
+			},
+		},
+		{
+			name:   "rule turned off",
+			policy: New(0),
+			user:   student(time.Time{}, false),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Requirements(tt.user, now))
+		})
+	}
+}
@@ -2,51 +2,139 @@ package auth
 
 import (
 	"AuthService/internal/models"
+	"AuthService/internal/services/rbac"
 	serviceerrors "AuthService/internal/services/service_errors"
 	"AuthService/internal/storage/storage"
-	"AuthService/pkg/tools/hash"
+	"AuthService/internal/tenant"
 	"AuthService/pkg/tools/jwt"
 	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/mailer"
+	"AuthService/pkg/tools/opaque"
+	"AuthService/pkg/tools/passpolicy"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// Config holds the settings of the auth service.
+type Config struct {
+	RefreshTokenTTL  time.Duration
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page that receives the reset token in its
+	// token query parameter.
+	PasswordResetURL string
+
+	EmailVerificationTTL time.Duration
+	// EmailVerificationURL is the page that receives the verification
+	// token in its token query parameter.
+	EmailVerificationURL string
+	// AllowUnverifiedLogin lets users log in before verifying their email.
+	AllowUnverifiedLogin bool
+
+	// MFAIssuer is the account issuer shown by authenticator apps.
+	MFAIssuer       string
+	MFAChallengeTTL time.Duration
+
+	// PasswordHistorySize is the number of previous passwords a new one
+	// may not repeat.
+	PasswordHistorySize int
+}
+
 type AuthStore struct {
 	jwt              jwt.JwtWrapper
+	cfg              Config
+	hasher           PasswordHasher
+	policy           PasswordPolicy
 	userCreater      UserCreater
 	userProvider     UserProvider
 	permissionSetter PermissionSetter
 	permissionGetter PermissionGetter
+	access           PermissionChecker
+	tokenStorage     TokenStorage
+	tokenRevoker     TokenRevoker
+	sessionStorage   SessionStorage
+	resetStorage     PasswordResetStorage
+	verifications    VerificationStorage
+	mfaStorage       MFAStorage
+	passwordHistory  PasswordHistory
+	limiter          LoginLimiter
+	mailer           mailer.Mailer
 	log              *slog.Logger
 }
 
 func New(
 	jwt jwt.JwtWrapper,
+	cfg Config,
+	hasher PasswordHasher,
+	policy PasswordPolicy,
 	userCreater UserCreater,
 	userProvider UserProvider,
 	permissionSetter PermissionSetter,
 	permissionGetter PermissionGetter,
+	access PermissionChecker,
+	tokenStorage TokenStorage,
+	tokenRevoker TokenRevoker,
+	sessionStorage SessionStorage,
+	resetStorage PasswordResetStorage,
+	verifications VerificationStorage,
+	mfaStorage MFAStorage,
+	passwordHistory PasswordHistory,
+	limiter LoginLimiter,
+	mailer mailer.Mailer,
 	log *slog.Logger,
 ) *AuthStore {
 	return &AuthStore{
 		jwt:              jwt,
+		cfg:              cfg,
+		hasher:           hasher,
+		policy:           policy,
 		userCreater:      userCreater,
 		userProvider:     userProvider,
 		permissionSetter: permissionSetter,
 		permissionGetter: permissionGetter,
+		access:           access,
+		tokenStorage:     tokenStorage,
+		tokenRevoker:     tokenRevoker,
+		sessionStorage:   sessionStorage,
+		resetStorage:     resetStorage,
+		verifications:    verifications,
+		mfaStorage:       mfaStorage,
+		passwordHistory:  passwordHistory,
+		limiter:          limiter,
+		mailer:           mailer,
 		log:              log,
 	}
 }
 
+type PasswordHasher interface {
+	Hash(pass string) ([]byte, error)
+	Check(pass string, hash []byte) bool
+	NeedsRehash(hash []byte) bool
+}
+
+type PasswordPolicy interface {
+	Check(password string, personal ...string) []passpolicy.Violation
+}
+
+type PasswordHistory interface {
+	GetPasswordHistory(ctx context.Context, userID int64, limit int) ([][]byte, error)
+	AddPasswordHistory(ctx context.Context, userID int64, passHash []byte, keep int) error
+}
+
 type UserCreater interface {
 	CreateUser(ctx context.Context, email string, hash []byte) (int64, error)
 }
 
 type UserProvider interface {
 	GetUser(ctx context.Context, email string) (models.User, error)
+	GetUserByID(ctx context.Context, userID int64) (models.User, error)
+	GetUserInfo(ctx context.Context, userID int64) (models.UserInfo, error)
+	IsActive(ctx context.Context, userID int64) (bool, error)
 	UpdatePassword(ctx context.Context, userID int64, passHash []byte) error
 }
 
@@ -58,6 +146,75 @@ type PermissionGetter interface {
 	GetPermission(ctx context.Context, userID int64) (int64, error)
 }
 
+// PermissionChecker returns ErrAccessDenied unless the user holds the
+// permission. RequireFor checks a permission over another user and also
+// accepts roles scoped to the class of a student.
+// RequireRank and RequireOutrank compare role levels, so that no one hands
+// out or acts on a rank above their own.
+type PermissionChecker interface {
+	Require(ctx context.Context, userID int64, permission string) error
+	RequireFor(ctx context.Context, initiatorID, userID int64, permission string) error
+	RequireRank(ctx context.Context, userID, level int64) error
+	RequireOutrank(ctx context.Context, initiatorID, userID int64) error
+}
+
+type TokenStorage interface {
+	SaveRefreshToken(ctx context.Context, token models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int64) error
+}
+
+type TokenRevoker interface {
+	RevokeToken(ctx context.Context, token models.RevokedToken) error
+	RevokeUserTokens(ctx context.Context, revocation models.UserRevocation) error
+	TerminateSession(ctx context.Context, sessionID string) error
+	IsRevoked(ctx context.Context, tokenID, sessionID string, userID int64, issuedAt time.Time) (bool, error)
+}
+
+type SessionStorage interface {
+	CreateSession(ctx context.Context, session models.Session) error
+	TouchSession(ctx context.Context, sessionID string, lastSeenAt, expiresAt time.Time) error
+	GetSession(ctx context.Context, sessionID string) (models.Session, error)
+	ListSessions(ctx context.Context, userID int64) ([]models.Session, error)
+	TerminateUserSessions(ctx context.Context, userID int64) error
+}
+
+type PasswordResetStorage interface {
+	GetPasswordReset(ctx context.Context, tokenHash string, now time.Time) (int64, error)
+	SavePasswordReset(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error
+	ConsumePasswordReset(ctx context.Context, tokenHash string, now time.Time) (int64, error)
+}
+
+type VerificationStorage interface {
+	SaveEmailVerification(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error
+	ConsumeEmailVerification(ctx context.Context, tokenHash string, now time.Time) (int64, error)
+}
+
+type MFAStorage interface {
+	SaveTOTP(ctx context.Context, userID int64, secret string) error
+	GetTOTP(ctx context.Context, userID int64) (models.TOTP, error)
+	ConfirmTOTP(ctx context.Context, userID, step int64, codeHashes []string, at time.Time) error
+	UseTOTPStep(ctx context.Context, userID, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string, at time.Time) error
+	CreateMFAChallenge(ctx context.Context, idHash string, challenge models.MFAChallenge) error
+	GetMFAChallenge(ctx context.Context, idHash string, now time.Time) (models.MFAChallenge, error)
+	UseMFAAttempt(ctx context.Context, idHash string, limit int) error
+	DeleteMFAChallenge(ctx context.Context, idHash string) error
+	IsMFARequired(ctx context.Context, permissionLevel int64) (bool, error)
+	SetMFARequired(ctx context.Context, permissionLevel int64, required bool) error
+}
+
+type LoginLimiter interface {
+	Check(ctx context.Context, email, ip string) error
+	Fail(ctx context.Context, email, ip string) error
+	FailAddress(ctx context.Context, ip string) error
+	Succeed(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+	AllowMail(ctx context.Context, kind, email string) (bool, error)
+}
+
 func (a *AuthStore) RegisterUser(ctx context.Context, email string, pass string) (int64, error) {
 	const op = "auth.Register"
 
@@ -74,7 +231,14 @@ func (a *AuthStore) RegisterUser(ctx context.Context, email string, pass string)
 		return 0, fmt.Errorf("failed to create user due to error: %w", serviceerrors.ErrBadEmailFormat)
 	}
 
-	passHash, err := hash.HashPass(pass)
+	if violations := a.policy.Check(pass, email); len(violations) > 0 {
+		err = &serviceerrors.PasswordPolicyError{Violations: violations}
+		log.Error("failed to register", sl.Err(err))
+
+		return 0, err
+	}
+
+	passHash, err := a.hasher.Hash(pass)
 	if err != nil {
 		log.Error("failed to register", sl.Err(err))
 
@@ -88,12 +252,27 @@ func (a *AuthStore) RegisterUser(ctx context.Context, email string, pass string)
 		return 0, fmt.Errorf("failed to create user due to error: %w", err)
 	}
 
+	if a.cfg.PasswordHistorySize > 0 {
+		if err = a.passwordHistory.AddPasswordHistory(ctx, id, passHash, a.cfg.PasswordHistorySize); err != nil {
+			log.Error("failed to save password history", sl.Err(err))
+		}
+	}
+
+	// The account exists at this point; a lost email can be sent again
+	// with ResendVerification.
+	if err = a.sendVerification(ctx, id, email); err != nil {
+		log.Error("failed to send verification email", sl.Err(err))
+	}
+
 	log.Info("user is registered")
 
 	return id, nil
 }
 
-func (a *AuthStore) Login(ctx context.Context, email string, password string) (string, error) {
+// Login checks the credentials of a user and starts a new session on the
+// given device. Users with two-factor authentication get an MFA challenge to
+// complete with CompleteMFALogin instead of tokens.
+func (a *AuthStore) Login(ctx context.Context, email string, password string, device models.Device) (models.TokenPair, *models.MFAChallenge, error) {
 	const op = "auth.Login"
 
 	log := a.log.With(
@@ -103,33 +282,301 @@ func (a *AuthStore) Login(ctx context.Context, email string, password string) (s
 
 	log.Info("login user")
 
+	if err := a.limiter.Check(ctx, email, device.IP); err != nil {
+		log.Error("failed to login", sl.Err(err))
+
+		return models.TokenPair{}, nil, err
+	}
+
 	user, err := a.userProvider.GetUser(ctx, email)
 
 	if err != nil {
 		log.Error("failed to login", sl.Err(err))
 
 		if errors.Is(err, storage.ErrUserNotFound) {
-			return "", fmt.Errorf("user not found due to error: %w", storage.ErrUserNotFound)
+			if err := a.limiter.FailAddress(ctx, device.IP); err != nil {
+				log.Error("failed to count login failure", sl.Err(err))
+			}
+
+			return models.TokenPair{}, nil, fmt.Errorf("user not found due to error: %w", storage.ErrUserNotFound)
 		}
-		return "", fmt.Errorf("failed to get user due to error: %w", err)
+		return models.TokenPair{}, nil, fmt.Errorf("failed to get user due to error: %w", err)
 	}
 
-	if ok := hash.CheckPass(password, []byte(user.PassHash)); !ok {
+	if ok := a.hasher.Check(password, []byte(user.PassHash)); !ok {
 		log.Error("failed to login", sl.Err(serviceerrors.ErrInvalidCredentials))
 
-		return "", fmt.Errorf("invalid credentials due to error: %w", serviceerrors.ErrInvalidCredentials)
+		a.failLogin(ctx, log, email, device.IP)
+
+		return models.TokenPair{}, nil, fmt.Errorf("invalid credentials due to error: %w", serviceerrors.ErrInvalidCredentials)
+	}
+
+	if err = a.limiter.Succeed(ctx, email); err != nil {
+		log.Error("failed to reset login attempts", sl.Err(err))
+	}
+
+	// The plain password is only known here, so this is where hashes of an
+	// older policy get upgraded.
+	if a.hasher.NeedsRehash([]byte(user.PassHash)) {
+		a.rehash(ctx, log, user.ID, password)
+	}
+
+	if !user.EmailVerified && !a.cfg.AllowUnverifiedLogin {
+		log.Error("failed to login", sl.Err(serviceerrors.ErrEmailNotVerified))
+
+		return models.TokenPair{}, nil, serviceerrors.ErrEmailNotVerified
+	}
+
+	challenge, err := a.newMFAChallenge(ctx, user, device)
+	if err != nil {
+		log.Error("failed to login", sl.Err(err))
+
+		return models.TokenPair{}, nil, err
+	}
+
+	if challenge != nil {
+		log.Info("second factor required")
+
+		return models.TokenPair{}, challenge, nil
 	}
 
-	token, err := a.jwt.NewToken(user)
+	pair, err := a.startSession(ctx, user, device)
 	if err != nil {
 		log.Error("failed to login", sl.Err(err))
 
-		return "", fmt.Errorf("failed to generate token due to error: %w", err)
+		return models.TokenPair{}, nil, err
 	}
 
 	log.Info("user logged in")
 
-	return token, nil
+	return pair, nil, nil
+}
+
+func (a *AuthStore) rehash(ctx context.Context, log *slog.Logger, userID int64, password string) {
+	passHash, err := a.hasher.Hash(password)
+	if err != nil {
+		log.Error("failed to rehash password", sl.Err(err))
+
+		return
+	}
+
+	if err = a.userProvider.UpdatePassword(ctx, userID, passHash); err != nil {
+		log.Error("failed to rehash password", sl.Err(err))
+
+		return
+	}
+
+	log.Info("password hash upgraded")
+}
+
+func (a *AuthStore) failLogin(ctx context.Context, log *slog.Logger, email, ip string) {
+	if err := a.limiter.Fail(ctx, email, ip); err != nil {
+		log.Error("failed to count login failure", sl.Err(err))
+	}
+}
+
+// UnlockAccount lifts the brute-force lockout of an account.
+func (a *AuthStore) UnlockAccount(ctx context.Context, token, email string) error {
+	const op = "auth.UnlockAccount"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+		slog.String("Username", email),
+	)
+
+	log.Info("unlocking account")
+
+	claims, err := a.parseToken(ctx, token)
+	if err != nil {
+		log.Error("failed to unlock account", sl.Err(err))
+
+		return err
+	}
+
+	// Unknown emails have no lockout to lift, but are still checked against
+	// the global permission; only an existing account has a class that a
+	// scoped role could cover.
+	user, err := a.userProvider.GetUser(ctx, email)
+	switch {
+	case err == nil:
+		err = a.access.RequireFor(ctx, claims.Uid, user.ID, rbac.PermAccountsUnlock)
+	case errors.Is(err, storage.ErrUserNotFound):
+		err = a.access.Require(ctx, claims.Uid, rbac.PermAccountsUnlock)
+	}
+	if err != nil {
+		log.Error("failed to unlock account", sl.Err(err))
+
+		return err
+	}
+
+	if err = a.limiter.Unlock(ctx, email); err != nil {
+		log.Error("failed to unlock account", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("account unlocked")
+
+	return nil
+}
+
+// startSession creates a session for a user who passed every login check
+// and issues its first token pair.
+func (a *AuthStore) startSession(ctx context.Context, user models.User, device models.Device) (models.TokenPair, error) {
+	refreshToken, refreshHash, err := a.newRefreshToken()
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("failed to generate refresh token due to error: %w", err)
+	}
+
+	now := time.Now()
+	session := models.Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		Device:     device,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(a.cfg.RefreshTokenTTL),
+	}
+	if err = a.sessionStorage.CreateSession(ctx, session); err != nil {
+		return models.TokenPair{}, fmt.Errorf("failed to create session due to error: %w", err)
+	}
+
+	next := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: refreshHash,
+		FamilyID:  session.ID,
+		ExpiresAt: session.ExpiresAt,
+	}
+	if err = a.tokenStorage.SaveRefreshToken(ctx, next); err != nil {
+		return models.TokenPair{}, fmt.Errorf("failed to save refresh token due to error: %w", err)
+	}
+
+	pair, err := a.newTokenPair(user, next.FamilyID, refreshToken)
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("failed to generate token due to error: %w", err)
+	}
+
+	return pair, nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. Every refresh
+// token is single-use: presenting one that was already rotated means it has
+// leaked, so the whole family it belongs to is revoked.
+func (a *AuthStore) RefreshToken(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	const op = "auth.RefreshToken"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("refreshing token")
+
+	current, err := a.tokenStorage.GetRefreshToken(ctx, opaque.Hash(refreshToken))
+	if err != nil {
+		log.Error("failed to refresh token", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return models.TokenPair{}, serviceerrors.ErrInvalidRefreshToken
+		}
+		return models.TokenPair{}, fmt.Errorf("failed to get refresh token due to error: %w", err)
+	}
+
+	log = log.With(slog.Int64("UserID", current.UserID))
+
+	if !current.RotatedAt.IsZero() {
+		return models.TokenPair{}, a.revokeReusedFamily(ctx, log, current.FamilyID)
+	}
+
+	if !current.RevokedAt.IsZero() || time.Now().After(current.ExpiresAt) {
+		log.Error("failed to refresh token", sl.Err(serviceerrors.ErrInvalidRefreshToken))
+
+		return models.TokenPair{}, serviceerrors.ErrInvalidRefreshToken
+	}
+
+	user, err := a.userProvider.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		log.Error("failed to refresh token", sl.Err(err))
+
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.TokenPair{}, serviceerrors.ErrInvalidRefreshToken
+		}
+		return models.TokenPair{}, fmt.Errorf("failed to get user due to error: %w", err)
+	}
+
+	newRefreshToken, refreshHash, err := a.newRefreshToken()
+	if err != nil {
+		log.Error("failed to refresh token", sl.Err(err))
+
+		return models.TokenPair{}, fmt.Errorf("failed to generate refresh token due to error: %w", err)
+	}
+
+	next := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: refreshHash,
+		FamilyID:  current.FamilyID,
+		ExpiresAt: time.Now().Add(a.cfg.RefreshTokenTTL),
+	}
+	if err = a.tokenStorage.RotateRefreshToken(ctx, current.ID, next); err != nil {
+		if errors.Is(err, storage.ErrTokenAlreadyRotated) {
+			return models.TokenPair{}, a.revokeReusedFamily(ctx, log, current.FamilyID)
+		}
+
+		log.Error("failed to refresh token", sl.Err(err))
+
+		return models.TokenPair{}, fmt.Errorf("failed to rotate refresh token due to error: %w", err)
+	}
+
+	if err = a.sessionStorage.TouchSession(ctx, next.FamilyID, time.Now(), next.ExpiresAt); err != nil {
+		// Only the last-seen time is lost, the refresh itself succeeded.
+		log.Error("failed to update session", sl.Err(err))
+	}
+
+	pair, err := a.newTokenPair(user, next.FamilyID, newRefreshToken)
+	if err != nil {
+		log.Error("failed to refresh token", sl.Err(err))
+
+		return models.TokenPair{}, fmt.Errorf("failed to generate token due to error: %w", err)
+	}
+
+	log.Info("token refreshed")
+
+	return pair, nil
+}
+
+func (a *AuthStore) revokeReusedFamily(ctx context.Context, log *slog.Logger, familyID string) error {
+	log.Warn("refresh token reuse detected, revoking token family", slog.String("FamilyID", familyID))
+
+	if err := a.tokenStorage.RevokeTokenFamily(ctx, familyID); err != nil {
+		log.Error("failed to revoke token family", sl.Err(err))
+
+		return fmt.Errorf("failed to revoke token family due to error: %w", err)
+	}
+
+	return serviceerrors.ErrRefreshTokenReused
+}
+
+func (a *AuthStore) newRefreshToken() (string, string, error) {
+	token, err := opaque.New()
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, opaque.Hash(token), nil
+}
+
+func (a *AuthStore) newTokenPair(user models.User, sessionID, refreshToken string) (models.TokenPair, error) {
+	expiresAt := time.Now().Add(a.jwt.TokenTTL)
+
+	accessToken, err := a.jwt.NewToken(user, sessionID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
 }
 
 func (a *AuthStore) ChangePassword(ctx context.Context, email, oldPassword, newPassword, token string) error {
@@ -149,7 +596,7 @@ func (a *AuthStore) ChangePassword(ctx context.Context, email, oldPassword, newP
 		return err
 	}
 
-	if ok := hash.CheckPass(oldPassword, []byte(user.PassHash)); !ok {
+	if ok := a.hasher.Check(oldPassword, []byte(user.PassHash)); !ok {
 		log.Error("failed to change password", sl.Err(serviceerrors.ErrInvalidCredentials))
 
 		return serviceerrors.ErrInvalidCredentials
@@ -162,14 +609,13 @@ func (a *AuthStore) ChangePassword(ctx context.Context, email, oldPassword, newP
 		return err
 	}
 
-	passHash, err := hash.HashPass(newPassword)
-	if err != nil {
+	if err = a.checkNewPassword(ctx, user, newPassword); err != nil {
 		log.Error("failed to change password", sl.Err(err))
 
 		return err
 	}
 
-	if err = a.userProvider.UpdatePassword(ctx, user.ID, passHash); err != nil {
+	if err = a.setPassword(ctx, user.ID, newPassword); err != nil {
 		log.Error("failed to change password", sl.Err(err))
 
 		return err
@@ -180,39 +626,282 @@ func (a *AuthStore) ChangePassword(ctx context.Context, email, oldPassword, newP
 	return nil
 }
 
-func (a *AuthStore) Validate(ctx context.Context, token string) (int64, error) {
+func (a *AuthStore) Validate(ctx context.Context, token string) (models.User, error) {
 	const op = "auth.Validate"
 
 	log := a.log.With(
 		slog.String("Operation", op),
-		slog.String("Token", token),
 	)
 
 	log.Info("validating jwt token")
 
-	var u models.User
+	claims, u, err := a.validate(ctx, token)
+	if err != nil {
+		log.Error("failed to validate jwt", sl.Err(err))
 
-	claims, err := a.jwt.ValidateToken(token)
+		return models.User{}, err
+	}
+
+	log = log.With(
+		slog.Int64("UserID", u.ID),
+		slog.String("SessionID", claims.SessionID),
+	)
+
+	log.Info("jwt token is valid")
+
+	return u, nil
+}
+
+// Introspect describes a token and its owner following RFC 7662: a token
+// that is invalid, expired, revoked or whose owner is gone is reported as
+// inactive rather than as an error.
+func (a *AuthStore) Introspect(ctx context.Context, token string) (models.Introspection, error) {
+	const op = "auth.Introspect"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("introspecting token")
 
+	claims, u, err := a.validate(ctx, token)
 	if err != nil {
-		log.Error("failed to validate jwt", sl.Err(err))
+		if errors.Is(err, jwt.ErrBadJWT) || errors.Is(err, serviceerrors.ErrTokenRevoked) || errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("token is inactive", sl.Err(err))
 
-		return 0, err
+			return models.Introspection{Active: false}, nil
+		}
+
+		log.Error("failed to introspect token", sl.Err(err))
+
+		return models.Introspection{}, err
 	}
 
-	u, err = a.userProvider.GetUser(ctx, claims.Email)
+	log = log.With(slog.Int64("UserID", u.ID))
 
+	// The caller may have named another school than the one of the token.
+	ctx = tenant.WithSchool(ctx, u.SchoolID)
+
+	info, err := a.userProvider.GetUserInfo(ctx, u.ID)
 	if err != nil {
-		log.Error("failed to validate jwt", sl.Err(err))
+		log.Error("failed to introspect token", sl.Err(err))
 
-		return 0, err
+		return models.Introspection{}, err
 	}
 
-	log.Info("jwt token is valid")
+	userActive, err := a.userProvider.IsActive(ctx, u.ID)
+	if err != nil {
+		log.Error("failed to introspect token", sl.Err(err))
+
+		return models.Introspection{}, err
+	}
+
+	log.Info("token introspected")
+
+	return models.Introspection{
+		Active:          true,
+		UserID:          u.ID,
+		Email:           u.Email,
+		PermissionLevel: u.PermissionLevel,
+		Classname:       info.Classname,
+		SchoolID:        u.SchoolID,
+		UserActive:      userActive,
+		ExpiresAt:       claims.ExpiresAt.Time,
+		IssuedAt:        claims.IssuedAt.Time,
+		TokenID:         claims.ID,
+		SessionID:       claims.SessionID,
+	}, nil
+}
+
+// validate checks the token and loads the user it was issued to.
+func (a *AuthStore) validate(ctx context.Context, token string) (*jwt.Claims, models.User, error) {
+	claims, err := a.parseToken(ctx, token)
+	if err != nil {
+		return nil, models.User{}, err
+	}
+
+	// Emails are unique within a school only. Tokens issued before schools
+	// were introduced carry none and belong to the default school.
+	school := claims.SchoolID
+	if school == 0 {
+		school = tenant.DefaultSchoolID
+	}
+
+	u, err := a.userProvider.GetUser(tenant.WithSchool(ctx, school), claims.Email)
+	if err != nil {
+		return nil, models.User{}, err
+	}
+
+	// The email could have been re-registered by someone else after the
+	// token was issued.
+	if u.ID != claims.Uid {
+		return nil, models.User{}, storage.ErrUserNotFound
+	}
+
+	return claims, u, nil
+}
+
+// Logout revokes the given access token and terminates its session. A
+// refresh token may be passed for tokens issued without a session, its whole
+// family is revoked then. Tokens that are already revoked are rejected with
+// ErrTokenRevoked, as Validate rejects them.
+func (a *AuthStore) Logout(ctx context.Context, token, refreshToken string) error {
+	const op = "auth.Logout"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("logging out")
+
+	claims, err := a.parseToken(ctx, token)
+	if err != nil {
+		log.Error("failed to logout", sl.Err(err))
+
+		return err
+	}
 
-	return u.ID, nil
+	log = log.With(slog.Int64("UserID", claims.Uid))
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		revoked := models.RevokedToken{
+			TokenID:   claims.ID,
+			UserID:    claims.Uid,
+			ExpiresAt: claims.ExpiresAt.Time,
+		}
+		if err = a.tokenRevoker.RevokeToken(ctx, revoked); err != nil {
+			log.Error("failed to logout", sl.Err(err))
+
+			return fmt.Errorf("failed to revoke token due to error: %w", err)
+		}
+	}
+
+	if claims.SessionID != "" {
+		if err = a.terminateSession(ctx, claims.SessionID); err != nil {
+			log.Error("failed to logout", sl.Err(err))
+
+			return err
+		}
+	}
+
+	if refreshToken != "" {
+		current, err := a.tokenStorage.GetRefreshToken(ctx, opaque.Hash(refreshToken))
+		if err != nil {
+			log.Error("failed to logout", sl.Err(err))
+
+			if errors.Is(err, storage.ErrTokenNotFound) {
+				return serviceerrors.ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get refresh token due to error: %w", err)
+		}
+
+		if current.UserID != claims.Uid {
+			log.Error("failed to logout", sl.Err(serviceerrors.ErrInvalidRefreshToken))
+
+			return serviceerrors.ErrInvalidRefreshToken
+		}
+
+		if err = a.tokenStorage.RevokeTokenFamily(ctx, current.FamilyID); err != nil {
+			log.Error("failed to logout", sl.Err(err))
+
+			return fmt.Errorf("failed to revoke token family due to error: %w", err)
+		}
+	}
+
+	log.Info("user logged out")
+
+	return nil
+}
+
+// RevokeAllSessions invalidates every access and refresh token issued to the
+// owner of the given token, including the token itself.
+func (a *AuthStore) RevokeAllSessions(ctx context.Context, token string) error {
+	const op = "auth.RevokeAllSessions"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("revoking all user sessions")
+
+	claims, err := a.parseToken(ctx, token)
+	if err != nil {
+		log.Error("failed to revoke sessions", sl.Err(err))
+
+		return err
+	}
+
+	log = log.With(slog.Int64("UserID", claims.Uid))
+
+	if err = a.revokeUserSessions(ctx, claims.Uid); err != nil {
+		log.Error("failed to revoke sessions", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("all user sessions revoked")
+
+	return nil
 }
 
+// revokeUserSessions invalidates every token issued to the user so far and
+// terminates all of their sessions.
+func (a *AuthStore) revokeUserSessions(ctx context.Context, userID int64) error {
+	// Token issue times have a one second resolution, so the cut-off is
+	// moved past the current second to cover tokens issued in it.
+	now := time.Now().Truncate(time.Second).Add(time.Second)
+	revocation := models.UserRevocation{
+		UserID:        userID,
+		RevokedBefore: now,
+		ExpiresAt:     now.Add(a.jwt.TokenTTL + a.jwt.Leeway),
+	}
+	if err := a.tokenRevoker.RevokeUserTokens(ctx, revocation); err != nil {
+		return fmt.Errorf("failed to revoke user tokens due to error: %w", err)
+	}
+
+	if err := a.tokenStorage.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens due to error: %w", err)
+	}
+
+	if err := a.sessionStorage.TerminateUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to terminate sessions due to error: %w", err)
+	}
+
+	return nil
+}
+
+// GetJWKS returns the public keys that verify the tokens issued by the
+// service.
+func (a *AuthStore) GetJWKS(ctx context.Context) jwt.JWKSet {
+	return a.jwt.JWKS()
+}
+
+// parseToken checks the token signature and makes sure it was not revoked.
+func (a *AuthStore) parseToken(ctx context.Context, token string) (*jwt.Claims, error) {
+	claims, err := a.jwt.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := a.tokenRevoker.IsRevoked(ctx, claims.ID, claims.SessionID, claims.Uid, issuedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation due to error: %w", err)
+	}
+	if revoked {
+		return nil, serviceerrors.ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// SetPermissionLevel replaces the roles of userID with the role that stands
+// for permissionLevel. The initiator needs users.permission.set and may not
+// hand out, or take away, a level above their own.
 func (a *AuthStore) SetPermissionLevel(ctx context.Context, userID, permissionLevel, initiatorID int64) error {
 	const op = "auth.SetPermissionLevel"
 
@@ -223,20 +912,27 @@ func (a *AuthStore) SetPermissionLevel(ctx context.Context, userID, permissionLe
 
 	log.Info("updating user permissions")
 
-	lvl, err := a.permissionGetter.GetPermission(ctx, initiatorID)
-	if err != nil {
+	if err := a.access.Require(ctx, initiatorID, rbac.PermUserPermissionSet); err != nil {
 		log.Error("failed to change permissions", sl.Err(err))
 
 		return err
 	}
 
-	if lvl < 3 {
-		log.Error("failed to change permissions", sl.Err(serviceerrors.ErrAccessDenied))
+	// The level replaces every role of the user, so the initiator has to
+	// reach both the new level and the current rank of the user.
+	if err := a.access.RequireRank(ctx, initiatorID, permissionLevel); err != nil {
+		log.Error("failed to change permissions", sl.Err(err))
 
-		return serviceerrors.ErrAccessDenied
+		return err
+	}
+
+	if err := a.access.RequireOutrank(ctx, initiatorID, userID); err != nil {
+		log.Error("failed to change permissions", sl.Err(err))
+
+		return err
 	}
 
-	if err = a.permissionSetter.SetPermission(ctx, userID, permissionLevel); err != nil {
+	if err := a.permissionSetter.SetPermission(ctx, userID, permissionLevel); err != nil {
 		log.Error("failed to change permissions", sl.Err(err))
 
 		return err
@@ -0,0 +1,384 @@
+package auth
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/opaque"
+	"AuthService/pkg/tools/totp"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodes = 10
+	// recoveryCodeBytes gives every code 80 random bits, too many to
+	// guess from a leaked hash even though the hash is not salted.
+	recoveryCodeBytes = 10
+	// totpSkew is the number of 30 second steps accepted on either side of
+	// the current one to tolerate clock drift.
+	totpSkew = 1
+	// maxMFAAttempts is the number of codes a challenge takes before it is
+	// dropped and the user has to log in again.
+	maxMFAAttempts = 5
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// BeginTOTPEnrollment generates a new TOTP secret for the user identified by
+// the access token or, for users who must enroll before they can log in, by
+// their MFA challenge. The secret is not used until ConfirmTOTPEnrollment.
+func (a *AuthStore) BeginTOTPEnrollment(ctx context.Context, token, challengeID string) (string, string, error) {
+	const op = "auth.BeginTOTPEnrollment"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("beginning totp enrollment")
+
+	user, err := a.mfaSubject(ctx, token, challengeID)
+	if err != nil {
+		log.Error("failed to begin totp enrollment", sl.Err(err))
+
+		return "", "", err
+	}
+
+	current, err := a.mfaStorage.GetTOTP(ctx, user.ID)
+	if err != nil && !errors.Is(err, storage.ErrTOTPNotFound) {
+		log.Error("failed to begin totp enrollment", sl.Err(err))
+
+		return "", "", err
+	}
+
+	if err == nil && !current.ConfirmedAt.IsZero() {
+		log.Error("failed to begin totp enrollment", sl.Err(serviceerrors.ErrMFAAlreadyEnabled))
+
+		return "", "", serviceerrors.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		log.Error("failed to begin totp enrollment", sl.Err(err))
+
+		return "", "", fmt.Errorf("failed to generate totp secret due to error: %w", err)
+	}
+
+	if err = a.mfaStorage.SaveTOTP(ctx, user.ID, secret); err != nil {
+		log.Error("failed to begin totp enrollment", sl.Err(err))
+
+		return "", "", err
+	}
+
+	log.Info("totp enrollment started", slog.Int64("UserID", user.ID))
+
+	return secret, totp.URI(a.cfg.MFAIssuer, user.Email, secret), nil
+}
+
+// ConfirmTOTPEnrollment enables TOTP once the user proves the authenticator
+// app works, and returns fresh recovery codes. The codes are shown only
+// once; storage keeps their hashes.
+func (a *AuthStore) ConfirmTOTPEnrollment(ctx context.Context, token, challengeID, code string) ([]string, error) {
+	const op = "auth.ConfirmTOTPEnrollment"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("confirming totp enrollment")
+
+	user, err := a.mfaSubject(ctx, token, challengeID)
+	if err != nil {
+		log.Error("failed to confirm totp enrollment", sl.Err(err))
+
+		return nil, err
+	}
+
+	current, err := a.mfaStorage.GetTOTP(ctx, user.ID)
+	if err != nil {
+		log.Error("failed to confirm totp enrollment", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTOTPNotFound) {
+			return nil, serviceerrors.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+
+	if !current.ConfirmedAt.IsZero() {
+		log.Error("failed to confirm totp enrollment", sl.Err(serviceerrors.ErrMFAAlreadyEnabled))
+
+		return nil, serviceerrors.ErrMFAAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(current.Secret, code, now, totpSkew)
+	if !ok {
+		log.Error("failed to confirm totp enrollment", sl.Err(serviceerrors.ErrInvalidMFACode))
+
+		return nil, serviceerrors.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		log.Error("failed to confirm totp enrollment", sl.Err(err))
+
+		return nil, err
+	}
+
+	if err = a.mfaStorage.ConfirmTOTP(ctx, user.ID, step, hashes, now); err != nil {
+		log.Error("failed to confirm totp enrollment", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTOTPNotFound) {
+			return nil, serviceerrors.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+
+	log.Info("totp enrollment confirmed", slog.Int64("UserID", user.ID))
+
+	return codes, nil
+}
+
+// CompleteMFALogin exchanges an MFA challenge and a TOTP or recovery code
+// for tokens. A challenge is dropped after maxMFAAttempts codes.
+func (a *AuthStore) CompleteMFALogin(ctx context.Context, challengeID, code string) (models.TokenPair, error) {
+	const op = "auth.CompleteMFALogin"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("completing mfa login")
+
+	idHash := opaque.Hash(challengeID)
+
+	challenge, err := a.mfaStorage.GetMFAChallenge(ctx, idHash, time.Now())
+	if err != nil {
+		log.Error("failed to complete mfa login", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return models.TokenPair{}, serviceerrors.ErrInvalidMFAChallenge
+		}
+		return models.TokenPair{}, err
+	}
+
+	log = log.With(slog.Int64("UserID", challenge.UserID))
+
+	// The attempt is counted before the code is checked, so concurrent
+	// requests cannot try more codes than the limit between them.
+	if err = a.mfaStorage.UseMFAAttempt(ctx, idHash, maxMFAAttempts); err != nil {
+		log.Error("failed to complete mfa login", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			_ = a.mfaStorage.DeleteMFAChallenge(ctx, idHash)
+
+			return models.TokenPair{}, serviceerrors.ErrInvalidMFAChallenge
+		}
+		return models.TokenPair{}, err
+	}
+
+	current, err := a.mfaStorage.GetTOTP(ctx, challenge.UserID)
+	if err != nil && !errors.Is(err, storage.ErrTOTPNotFound) {
+		log.Error("failed to complete mfa login", sl.Err(err))
+
+		return models.TokenPair{}, err
+	}
+
+	if err != nil || current.ConfirmedAt.IsZero() {
+		log.Error("failed to complete mfa login", sl.Err(serviceerrors.ErrMFANotEnrolled))
+
+		return models.TokenPair{}, serviceerrors.ErrMFANotEnrolled
+	}
+
+	if err = a.checkMFACode(ctx, current, code); err != nil {
+		log.Error("failed to complete mfa login", sl.Err(err))
+
+		return models.TokenPair{}, err
+	}
+
+	// Deleting the challenge makes sure it completes only once even when
+	// two requests carry valid codes.
+	if err = a.mfaStorage.DeleteMFAChallenge(ctx, idHash); err != nil {
+		log.Error("failed to complete mfa login", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return models.TokenPair{}, serviceerrors.ErrInvalidMFAChallenge
+		}
+		return models.TokenPair{}, err
+	}
+
+	user, err := a.userProvider.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		log.Error("failed to complete mfa login", sl.Err(err))
+
+		return models.TokenPair{}, err
+	}
+
+	pair, err := a.startSession(ctx, user, challenge.Device)
+	if err != nil {
+		log.Error("failed to complete mfa login", sl.Err(err))
+
+		return models.TokenPair{}, err
+	}
+
+	log.Info("user logged in")
+
+	return pair, nil
+}
+
+// SetMFARequirement makes two-factor authentication mandatory, or optional
+// again, for every user with the given permission level.
+func (a *AuthStore) SetMFARequirement(ctx context.Context, token string, permissionLevel int64, required bool) error {
+	const op = "auth.SetMFARequirement"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+		slog.Int64("PermissionLevel", permissionLevel),
+		slog.Bool("Required", required),
+	)
+
+	log.Info("setting mfa requirement")
+
+	claims, err := a.parseToken(ctx, token)
+	if err != nil {
+		log.Error("failed to set mfa requirement", sl.Err(err))
+
+		return err
+	}
+
+	if err = a.access.Require(ctx, claims.Uid, rbac.PermMFAManage); err != nil {
+		log.Error("failed to set mfa requirement", sl.Err(err))
+
+		return err
+	}
+
+	if err = a.mfaStorage.SetMFARequired(ctx, permissionLevel, required); err != nil {
+		log.Error("failed to set mfa requirement", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("mfa requirement set")
+
+	return nil
+}
+
+// newMFAChallenge returns a challenge when the user has TOTP enabled or has
+// to enroll it, and nil when the password is enough.
+func (a *AuthStore) newMFAChallenge(ctx context.Context, user models.User, device models.Device) (*models.MFAChallenge, error) {
+	current, err := a.mfaStorage.GetTOTP(ctx, user.ID)
+	if err != nil && !errors.Is(err, storage.ErrTOTPNotFound) {
+		return nil, err
+	}
+	enabled := err == nil && !current.ConfirmedAt.IsZero()
+
+	required, err := a.mfaStorage.IsMFARequired(ctx, user.PermissionLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	id, err := opaque.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mfa challenge due to error: %w", err)
+	}
+
+	challenge := &models.MFAChallenge{
+		ID:                 id,
+		UserID:             user.ID,
+		Device:             device,
+		ExpiresAt:          time.Now().Add(a.cfg.MFAChallengeTTL),
+		EnrollmentRequired: !enabled,
+	}
+	if err = a.mfaStorage.CreateMFAChallenge(ctx, opaque.Hash(id), *challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// mfaSubject returns the user an enrollment request is made for.
+func (a *AuthStore) mfaSubject(ctx context.Context, token, challengeID string) (models.User, error) {
+	if challengeID == "" {
+		_, user, err := a.validate(ctx, token)
+
+		return user, err
+	}
+
+	challenge, err := a.mfaStorage.GetMFAChallenge(ctx, opaque.Hash(challengeID), time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return models.User{}, serviceerrors.ErrInvalidMFAChallenge
+		}
+		return models.User{}, err
+	}
+
+	return a.userProvider.GetUserByID(ctx, challenge.UserID)
+}
+
+// checkMFACode accepts either a current TOTP code or an unused recovery
+// code.
+func (a *AuthStore) checkMFACode(ctx context.Context, current models.TOTP, code string) error {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(current.Secret, code, time.Now(), totpSkew)
+		if !ok {
+			return serviceerrors.ErrInvalidMFACode
+		}
+
+		if err := a.mfaStorage.UseTOTPStep(ctx, current.UserID, step); err != nil {
+			if errors.Is(err, storage.ErrCodeAlreadyUsed) {
+				return serviceerrors.ErrInvalidMFACode
+			}
+			return err
+		}
+
+		return nil
+	}
+
+	err := a.mfaStorage.UseRecoveryCode(ctx, current.UserID, opaque.Hash(normalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrCodeAlreadyUsed) {
+			return serviceerrors.ErrInvalidMFACode
+		}
+		return err
+	}
+
+	return nil
+}
+
+// newRecoveryCodes returns recovery codes formatted as xxxx-xxxx-xxxx-xxxx
+// together with the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodes)
+	hashes := make([]string, 0, recoveryCodes)
+
+	for i := 0; i < recoveryCodes; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code due to error: %w", err)
+		}
+
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(b))
+		codes = append(codes, raw[:4]+"-"+raw[4:8]+"-"+raw[8:12]+"-"+raw[12:])
+		hashes = append(hashes, opaque.Hash(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"AuthService/internal/models"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/passpolicy"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// checkNewPassword applies the password policy to a password chosen by an
+// existing user: besides the static rules it may not contain the user's
+// names or repeat one of their recent passwords.
+func (a *AuthStore) checkNewPassword(ctx context.Context, user models.User, password string) error {
+	personal := []string{user.Email}
+
+	info, err := a.userProvider.GetUserInfo(ctx, user.ID)
+	if err != nil && !errors.Is(err, storage.ErrUserNotFound) {
+		return err
+	}
+	personal = append(personal, info.Name, info.Lastname, info.Middlename)
+
+	violations := a.policy.Check(password, personal...)
+
+	reused, err := a.isReused(ctx, user, password)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violations = append(violations, passpolicy.Violation{
+			Rule:    passpolicy.RuleReused,
+			Message: fmt.Sprintf("password must differ from your last %d passwords", a.cfg.PasswordHistorySize),
+		})
+	}
+
+	if len(violations) > 0 {
+		return &serviceerrors.PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+func (a *AuthStore) isReused(ctx context.Context, user models.User, password string) (bool, error) {
+	if a.cfg.PasswordHistorySize <= 0 {
+		return false, nil
+	}
+
+	hashes, err := a.passwordHistory.GetPasswordHistory(ctx, user.ID, a.cfg.PasswordHistorySize)
+	if err != nil {
+		return false, err
+	}
+
+	// Accounts created before the history existed only have their current
+	// hash.
+	hashes = append(hashes, []byte(user.PassHash))
+
+	for _, hash := range hashes {
+		if a.hasher.Check(password, hash) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// setPassword stores a new password chosen by the user and remembers it in
+// the password history.
+func (a *AuthStore) setPassword(ctx context.Context, userID int64, password string) error {
+	passHash, err := a.hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("failed to generate hash password due to error: %w", err)
+	}
+
+	if err = a.userProvider.UpdatePassword(ctx, userID, passHash); err != nil {
+		return err
+	}
+
+	if a.cfg.PasswordHistorySize <= 0 {
+		return nil
+	}
+
+	return a.passwordHistory.AddPasswordHistory(ctx, userID, passHash, a.cfg.PasswordHistorySize)
+}
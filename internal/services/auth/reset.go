@@ -0,0 +1,180 @@
+package auth
+
+import (
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/mailer"
+	"AuthService/pkg/tools/opaque"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const passwordResetBody = `Hello,
+
+someone asked to reset the password of your EEducation account.
+Follow the link below to choose a new password:
+
+%s
+
+The link works once and expires in %s. If you did not ask for a reset,
+just ignore this message.
+`
+
+// mailTimeout bounds the work that is left to the background once a
+// request for an email has been answered.
+const mailTimeout = time.Minute
+
+// RequestPasswordReset emails a one-time reset link to the user. The lookup
+// and the email happen in the background and the call always succeeds, so
+// that neither its result nor its duration tells callers which accounts
+// exist; only the log tells the cases apart. An account gets at most the
+// mail quota of the limiter in reset emails.
+func (a *AuthStore) RequestPasswordReset(ctx context.Context, email string) error {
+	const op = "auth.RequestPasswordReset"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+		slog.String("Username", email),
+	)
+
+	log.Info("requesting password reset")
+
+	go a.sendPasswordReset(context.WithoutCancel(ctx), log, email)
+
+	return nil
+}
+
+func (a *AuthStore) sendPasswordReset(ctx context.Context, log *slog.Logger, email string) {
+	ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+
+	user, err := a.userProvider.GetUser(ctx, email)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("password reset requested for unknown email")
+
+			return
+		}
+
+		log.Error("failed to request password reset", sl.Err(err))
+
+		return
+	}
+
+	if ok, err := a.limiter.AllowMail(ctx, "reset", user.Email); err != nil || !ok {
+		log.Error("failed to request password reset", sl.Err(mailLimitErr(err)))
+
+		return
+	}
+
+	token, err := opaque.New()
+	if err != nil {
+		log.Error("failed to request password reset", sl.Err(err))
+
+		return
+	}
+
+	expiresAt := time.Now().Add(a.cfg.PasswordResetTTL)
+	if err = a.resetStorage.SavePasswordReset(ctx, user.ID, opaque.Hash(token), expiresAt); err != nil {
+		log.Error("failed to request password reset", sl.Err(err))
+
+		return
+	}
+
+	link, err := tokenLink(a.cfg.PasswordResetURL, token)
+	if err != nil {
+		log.Error("failed to request password reset", sl.Err(err))
+
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "EEducation password reset",
+		Body:    fmt.Sprintf(passwordResetBody, link, a.cfg.PasswordResetTTL),
+	}
+	if err = a.mailer.Send(ctx, msg); err != nil {
+		log.Error("failed to send password reset email", sl.Err(err))
+
+		return
+	}
+
+	log.Info("password reset email sent")
+}
+
+// mailLimitErr describes why AllowMail refused an email.
+func mailLimitErr(err error) error {
+	if err != nil {
+		return err
+	}
+
+	return serviceerrors.ErrTooManyEmails
+}
+
+// ConfirmPasswordReset sets a new password using a token from
+// RequestPasswordReset and signs the user out everywhere.
+func (a *AuthStore) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	const op = "auth.ConfirmPasswordReset"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("confirming password reset")
+
+	// The password is checked before the token is consumed, so a rejected
+	// password does not cost the user their reset link.
+	userID, err := a.resetStorage.GetPasswordReset(ctx, opaque.Hash(token), time.Now())
+	if err != nil {
+		log.Error("failed to reset password", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return serviceerrors.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	log = log.With(slog.Int64("UserID", userID))
+
+	user, err := a.userProvider.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Error("failed to reset password", sl.Err(err))
+
+		return err
+	}
+
+	if err = a.checkNewPassword(ctx, user, newPassword); err != nil {
+		log.Error("failed to reset password", sl.Err(err))
+
+		return err
+	}
+
+	if _, err = a.resetStorage.ConsumePasswordReset(ctx, opaque.Hash(token), time.Now()); err != nil {
+		log.Error("failed to reset password", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return serviceerrors.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	if err = a.setPassword(ctx, userID, newPassword); err != nil {
+		log.Error("failed to reset password", sl.Err(err))
+
+		return err
+	}
+
+	if err = a.revokeUserSessions(ctx, userID); err != nil {
+		log.Error("failed to reset password", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("password reset")
+
+	return nil
+}
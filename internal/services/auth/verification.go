@@ -0,0 +1,144 @@
+package auth
+
+import (
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/mailer"
+	"AuthService/pkg/tools/opaque"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+const verificationBody = `Hello,
+
+please confirm that this address belongs to you by following the link below:
+
+%s
+
+The link expires in %s. If you did not create an EEducation account,
+just ignore this message.
+`
+
+// VerifyEmail marks the email of the token owner as verified.
+func (a *AuthStore) VerifyEmail(ctx context.Context, token string) error {
+	const op = "auth.VerifyEmail"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+	)
+
+	log.Info("verifying email")
+
+	userID, err := a.verifications.ConsumeEmailVerification(ctx, opaque.Hash(token), time.Now())
+	if err != nil {
+		log.Error("failed to verify email", sl.Err(err))
+
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return serviceerrors.ErrInvalidVerification
+		}
+		return err
+	}
+
+	log.Info("email verified", slog.Int64("UserID", userID))
+
+	return nil
+}
+
+// ResendVerification sends a new verification email. Like
+// RequestPasswordReset it works in the background and reports success for
+// unknown and already verified emails, and it is bound by the same mail
+// quota.
+func (a *AuthStore) ResendVerification(ctx context.Context, email string) error {
+	const op = "auth.ResendVerification"
+
+	log := a.log.With(
+		slog.String("Operation", op),
+		slog.String("Username", email),
+	)
+
+	log.Info("resending verification email")
+
+	go a.resendVerification(context.WithoutCancel(ctx), log, email)
+
+	return nil
+}
+
+func (a *AuthStore) resendVerification(ctx context.Context, log *slog.Logger, email string) {
+	ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+
+	user, err := a.userProvider.GetUser(ctx, email)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			log.Info("verification requested for unknown email")
+
+			return
+		}
+
+		log.Error("failed to resend verification", sl.Err(err))
+
+		return
+	}
+
+	if user.EmailVerified {
+		log.Info("email is already verified")
+
+		return
+	}
+
+	if ok, err := a.limiter.AllowMail(ctx, "verification", user.Email); err != nil || !ok {
+		log.Error("failed to resend verification", sl.Err(mailLimitErr(err)))
+
+		return
+	}
+
+	if err = a.sendVerification(ctx, user.ID, user.Email); err != nil {
+		log.Error("failed to send verification email", sl.Err(err))
+
+		return
+	}
+
+	log.Info("verification email sent")
+}
+
+func (a *AuthStore) sendVerification(ctx context.Context, userID int64, email string) error {
+	token, err := opaque.New()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token due to error: %w", err)
+	}
+
+	expiresAt := time.Now().Add(a.cfg.EmailVerificationTTL)
+	if err = a.verifications.SaveEmailVerification(ctx, userID, opaque.Hash(token), expiresAt); err != nil {
+		return err
+	}
+
+	link, err := tokenLink(a.cfg.EmailVerificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	return a.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Confirm your EEducation email",
+		Body:    fmt.Sprintf(verificationBody, link, a.cfg.EmailVerificationTTL),
+	})
+}
+
+// tokenLink appends a one-time token to the page that consumes it.
+func tokenLink(page, token string) (string, error) {
+	u, err := url.Parse(page)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url due to error: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
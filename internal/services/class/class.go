@@ -0,0 +1,310 @@
+package class
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/auth"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MinGrade = 1
+	MaxGrade = 11
+
+	// DefaultCapacity is used when a class is created without one.
+	DefaultCapacity = 30
+)
+
+type ClassStore struct {
+	log          *slog.Logger
+	classStorage ClassStorage
+	access       PermissionChecker
+	guardians    GuardianChecker
+}
+
+func New(log *slog.Logger, classStorage ClassStorage, access PermissionChecker, guardians GuardianChecker) *ClassStore {
+	return &ClassStore{
+		log:          log,
+		classStorage: classStorage,
+		access:       access,
+		guardians:    guardians,
+	}
+}
+
+// PermissionChecker extends the auth checks with HasRole, which tells
+// whether a user holds a role globally.
+type PermissionChecker interface {
+	auth.PermissionChecker
+	HasRole(ctx context.Context, userID int64, role string) (bool, error)
+}
+
+type GuardianChecker interface {
+	IsGuardian(ctx context.Context, guardianID, studentID int64) (bool, error)
+}
+
+type ClassStorage interface {
+	CreateClass(ctx context.Context, class models.Class) (int64, error)
+	UpdateClass(ctx context.Context, class models.Class) error
+	ArchiveClass(ctx context.Context, classID int64, at time.Time) error
+	GetClass(ctx context.Context, classID int64) (models.Class, error)
+	ListClasses(ctx context.Context, filter models.ClassFilter) ([]models.Class, error)
+	ListClassMembers(ctx context.Context, classID int64) ([]models.UserInfo, error)
+	TransferStudent(ctx context.Context, userID int64, class models.Class, at time.Time) error
+	GetClassHistory(ctx context.Context, userID int64) ([]models.ClassMembership, error)
+	PromoteClasses(ctx context.Context, promotions []models.ClassPromotion, deactivate bool, at time.Time) error
+}
+
+// Name returns the name of the class of a grade and a letter, such as "9A".
+func Name(grade int64, letter string) string {
+	return fmt.Sprintf("%d%s", grade, strings.ToUpper(letter))
+}
+
+// NormalizeName brings a class name typed by a user to the stored form, so
+// that "9a" and " 9A" both name class 9A.
+func NormalizeName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// AcademicYear returns the year the school year of t started in. School
+// years start on the 1st of September.
+func AcademicYear(t time.Time) int64 {
+	if t.Month() >= time.September {
+		return int64(t.Year())
+	}
+
+	return int64(t.Year() - 1)
+}
+
+// CreateClass creates a class. The academic year defaults to the current
+// one and the capacity to DefaultCapacity.
+func (s *ClassStore) CreateClass(ctx context.Context, initiatorID int64, class models.Class) (models.Class, error) {
+	const op = "class.CreateClass"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Int64("Grade", class.Grade),
+		slog.String("Letter", class.Letter),
+	)
+
+	log.Info("creating class")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermClassesManage); err != nil {
+		log.Error("failed to create class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	if class.AcademicYear == 0 {
+		class.AcademicYear = AcademicYear(time.Now())
+	}
+	if class.Capacity == 0 {
+		class.Capacity = DefaultCapacity
+	}
+	class.Letter = strings.ToUpper(strings.TrimSpace(class.Letter))
+
+	if err := validateClass(class); err != nil {
+		log.Error("failed to create class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	class.Name = Name(class.Grade, class.Letter)
+
+	id, err := s.classStorage.CreateClass(ctx, class)
+	if err != nil {
+		log.Error("failed to create class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	class, err = s.classStorage.GetClass(ctx, id)
+	if err != nil {
+		log.Error("failed to create class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	log.Info("class created", slog.Int64("ClassID", id))
+
+	return class, nil
+}
+
+// UpdateClass sets the fields of a class named by fields, the homeroom
+// teacher and the capacity, to their values in update. Other fields keep
+// their values. The capacity may not drop below the number of students
+// already in the class.
+func (s *ClassStore) UpdateClass(ctx context.Context, initiatorID, classID int64, update models.Class, fields []string) (models.Class, error) {
+	const op = "class.UpdateClass"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Int64("ClassID", classID),
+		slog.Any("Fields", fields),
+	)
+
+	log.Info("updating class")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermClassesManage); err != nil {
+		log.Error("failed to update class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	class, err := s.classStorage.GetClass(ctx, classID)
+	if err != nil {
+		log.Error("failed to update class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	if class.Archived() {
+		log.Error("failed to update class", sl.Err(serviceerrors.ErrClassArchived))
+
+		return models.Class{}, serviceerrors.ErrClassArchived
+	}
+
+	if len(fields) == 0 {
+		log.Error("failed to update class", sl.Err(serviceerrors.ErrInvalidClass))
+
+		return models.Class{}, fmt.Errorf("%w: nothing to update", serviceerrors.ErrInvalidClass)
+	}
+
+	for _, field := range fields {
+		switch field {
+		case models.ClassHomeroomTeacher:
+			class.HomeroomTeacherID = update.HomeroomTeacherID
+		case models.ClassCapacity:
+			class.Capacity = update.Capacity
+		default:
+			err = fmt.Errorf("%w: field %q cannot be updated", serviceerrors.ErrInvalidClass, field)
+			log.Error("failed to update class", sl.Err(err))
+
+			return models.Class{}, err
+		}
+	}
+
+	if err = validateClass(class); err != nil {
+		log.Error("failed to update class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	if err = s.classStorage.UpdateClass(ctx, class); err != nil {
+		if errors.Is(err, storage.ErrClassFull) {
+			err = serviceerrors.ErrClassFull
+		}
+		log.Error("failed to update class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	log.Info("class updated")
+
+	return class, nil
+}
+
+// ArchiveClass closes a class to new students. Its students keep it until
+// they are moved.
+func (s *ClassStore) ArchiveClass(ctx context.Context, initiatorID, classID int64) error {
+	const op = "class.ArchiveClass"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Int64("ClassID", classID),
+	)
+
+	log.Info("archiving class")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermClassesManage); err != nil {
+		log.Error("failed to archive class", sl.Err(err))
+
+		return err
+	}
+
+	if err := s.classStorage.ArchiveClass(ctx, classID, time.Now()); err != nil {
+		log.Error("failed to archive class", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("class archived")
+
+	return nil
+}
+
+func (s *ClassStore) GetClass(ctx context.Context, classID int64) (models.Class, error) {
+	const op = "class.GetClass"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("ClassID", classID),
+	)
+
+	log.Info("getting class")
+
+	class, err := s.classStorage.GetClass(ctx, classID)
+	if err != nil {
+		log.Error("failed to get class", sl.Err(err))
+
+		return models.Class{}, err
+	}
+
+	return class, nil
+}
+
+func (s *ClassStore) ListClasses(ctx context.Context, filter models.ClassFilter) ([]models.Class, error) {
+	const op = "class.ListClasses"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("AcademicYear", filter.AcademicYear),
+		slog.Int64("Grade", filter.Grade),
+	)
+
+	log.Info("listing classes")
+
+	classes, err := s.classStorage.ListClasses(ctx, filter)
+	if err != nil {
+		log.Error("failed to list classes", sl.Err(err))
+
+		return nil, err
+	}
+
+	log.Info("classes listed", slog.Int("Count", len(classes)))
+
+	return classes, nil
+}
+
+func validateClass(class models.Class) error {
+	if class.Grade < MinGrade || class.Grade > MaxGrade {
+		return serviceerrors.ErrInvalidClass
+	}
+
+	r, size := utf8.DecodeRuneInString(class.Letter)
+	if size == 0 || size != len(class.Letter) || !unicode.IsLetter(r) {
+		return serviceerrors.ErrInvalidClass
+	}
+
+	if class.AcademicYear < 2000 || class.AcademicYear > 2100 {
+		return serviceerrors.ErrInvalidClass
+	}
+
+	if class.Capacity <= 0 {
+		return serviceerrors.ErrInvalidClass
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package class
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// PromoteAcademicYear ends the school year academicYear (the current one
+// when zero): the students of every open class move to the next grade of
+// the following year, and the final grade leaves school, deactivated when
+// deactivate is set. The old classes are archived. Only holders of the
+// student role are promoted, and a next class that exists already needs a
+// free seat for each of them. With dryRun nothing changes and only the
+// report is returned.
+func (s *ClassStore) PromoteAcademicYear(ctx context.Context, initiatorID, academicYear int64, deactivate, dryRun bool) ([]models.StudentPromotion, error) {
+	const op = "class.PromoteAcademicYear"
+
+	if academicYear == 0 {
+		academicYear = AcademicYear(time.Now())
+	}
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Int64("AcademicYear", academicYear),
+		slog.Bool("DryRun", dryRun),
+	)
+
+	log.Info("promoting academic year")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermClassesManage); err != nil {
+		log.Error("failed to promote academic year", sl.Err(err))
+
+		return nil, err
+	}
+
+	classes, err := s.classStorage.ListClasses(ctx, models.ClassFilter{AcademicYear: academicYear})
+	if err != nil {
+		log.Error("failed to promote academic year", sl.Err(err))
+
+		return nil, err
+	}
+
+	graduateOutcome := models.PromotionGraduated
+	if deactivate {
+		graduateOutcome = models.PromotionDeactivated
+	}
+
+	var (
+		promotions = make([]models.ClassPromotion, 0, len(classes))
+		report     []models.StudentPromotion
+	)
+	for _, cls := range classes {
+		p := models.ClassPromotion{From: cls, Graduate: cls.Grade >= MaxGrade}
+		if !p.Graduate {
+			p.To = models.Class{
+				Name:              Name(cls.Grade+1, cls.Letter),
+				Grade:             cls.Grade + 1,
+				Letter:            cls.Letter,
+				AcademicYear:      cls.AcademicYear + 1,
+				HomeroomTeacherID: cls.HomeroomTeacherID,
+				Capacity:          cls.Capacity,
+			}
+		}
+		promotions = append(promotions, p)
+
+		students, err := s.classStorage.ListClassMembers(ctx, cls.ID)
+		if err != nil {
+			log.Error("failed to promote academic year", sl.Err(err))
+
+			return nil, err
+		}
+
+		for _, st := range students {
+			line := models.StudentPromotion{
+				UserID:    st.ID,
+				Name:      st.Name,
+				Lastname:  st.Lastname,
+				FromClass: cls.Name,
+				ToClass:   p.To.Name,
+				Outcome:   models.PromotionPromoted,
+			}
+			if p.Graduate {
+				line.Outcome = graduateOutcome
+			}
+			report = append(report, line)
+		}
+	}
+
+	if dryRun {
+		log.Info("academic year promotion previewed", slog.Int("Classes", len(promotions)), slog.Int("Students", len(report)))
+
+		return report, nil
+	}
+
+	if err = s.classStorage.PromoteClasses(ctx, promotions, deactivate, time.Now()); err != nil {
+		if errors.Is(err, storage.ErrClassFull) {
+			err = fmt.Errorf("%w: %w", serviceerrors.ErrClassFull, err)
+		}
+		log.Error("failed to promote academic year", sl.Err(err))
+
+		return nil, err
+	}
+
+	log.Info("academic year promoted", slog.Int("Classes", len(promotions)), slog.Int("Students", len(report)))
+
+	return report, nil
+}
+
+// TransferStudent moves a student into another open class with a free
+// place. Users without the student role cannot be transferred.
+func (s *ClassStore) TransferStudent(ctx context.Context, initiatorID, userID, classID int64) error {
+	const op = "class.TransferStudent"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Int64("UserID", userID),
+		slog.Int64("ClassID", classID),
+	)
+
+	log.Info("transferring student")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermClassesManage); err != nil {
+		log.Error("failed to transfer student", sl.Err(err))
+
+		return err
+	}
+
+	class, err := s.classStorage.GetClass(ctx, classID)
+	if err != nil {
+		log.Error("failed to transfer student", sl.Err(err))
+
+		return err
+	}
+
+	if class.Archived() {
+		log.Error("failed to transfer student", sl.Err(serviceerrors.ErrClassArchived))
+
+		return serviceerrors.ErrClassArchived
+	}
+
+	student, err := s.access.HasRole(ctx, userID, rbac.RoleStudent)
+	if err != nil {
+		log.Error("failed to transfer student", sl.Err(err))
+
+		return err
+	}
+
+	if !student {
+		log.Error("failed to transfer student", sl.Err(serviceerrors.ErrNotStudent))
+
+		return serviceerrors.ErrNotStudent
+	}
+
+	if err = s.classStorage.TransferStudent(ctx, userID, class, time.Now()); err != nil {
+		if errors.Is(err, storage.ErrClassFull) {
+			err = serviceerrors.ErrClassFull
+		}
+		log.Error("failed to transfer student", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("student transferred")
+
+	return nil
+}
+
+// GetClassHistory returns the classes a student has been in. Students may
+// read their own history and guardians that of their children; others need
+// students.list.all.
+func (s *ClassStore) GetClassHistory(ctx context.Context, initiatorID, userID int64) ([]models.ClassMembership, error) {
+	const op = "class.GetClassHistory"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Int64("UserID", userID),
+	)
+
+	log.Info("getting class history")
+
+	if initiatorID != userID {
+		ok, err := s.guardians.IsGuardian(ctx, initiatorID, userID)
+		if err == nil && !ok {
+			err = s.access.Require(ctx, initiatorID, rbac.PermStudentsListAll)
+		}
+		if err != nil {
+			log.Error("failed to get class history", sl.Err(err))
+
+			return nil, err
+		}
+	}
+
+	history, err := s.classStorage.GetClassHistory(ctx, userID)
+	if err != nil {
+		log.Error("failed to get class history", sl.Err(err))
+
+		return nil, err
+	}
+
+	return history, nil
+}
@@ -0,0 +1,215 @@
+package exports
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/auth"
+	"AuthService/internal/services/class"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/birthdate"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type ExportStore struct {
+	log           *slog.Logger
+	exportStorage ExportStorage
+	access        PermissionChecker
+}
+
+func New(log *slog.Logger, exportStorage ExportStorage, access PermissionChecker) *ExportStore {
+	return &ExportStore{
+		log:           log,
+		exportStorage: exportStorage,
+		access:        access,
+	}
+}
+
+// PermissionChecker extends the auth checks with RequireInScope, which
+// ignores global roles and only accepts roles scoped to classname.
+type PermissionChecker interface {
+	auth.PermissionChecker
+	RequireInScope(ctx context.Context, userID int64, permission, classname string) error
+}
+
+type ExportStorage interface {
+	GetClassByName(ctx context.Context, name string) (models.Class, error)
+	ExportUsers(ctx context.Context, filter models.UserFilter, fn func(models.UserRecord) error) error
+}
+
+// Query selects the users of an export and how they are written. Classname
+// names an open class; Columns defaults to DefaultColumns.
+type Query struct {
+	Format    string
+	Columns   []string
+	Classname string
+	Filter    models.UserFilter
+}
+
+// ExportUsers writes the users of the school of ctx that match q to w and
+// returns how many it wrote. Users are streamed from the storage into w,
+// so that large exports are not held in memory. Exports need users.export,
+// except that teachers may export the class they teach.
+func (s *ExportStore) ExportUsers(ctx context.Context, initiatorID int64, q Query, w io.Writer) (int64, error) {
+	const op = "exports.ExportUsers"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.String("Format", q.Format),
+		slog.String("Classname", q.Classname),
+	)
+
+	log.Info("exporting users")
+
+	cols, err := selectColumns(q.Columns)
+	if err != nil {
+		log.Error("failed to export users", sl.Err(err))
+
+		return 0, err
+	}
+
+	filter := q.Filter
+	filter.Role = strings.ToLower(strings.TrimSpace(filter.Role))
+
+	var cls *models.Class
+	if q.Classname != "" {
+		c, err := s.exportStorage.GetClassByName(ctx, class.NormalizeName(q.Classname))
+		if err != nil {
+			if errors.Is(err, storage.ErrClassNotFound) {
+				err = serviceerrors.ErrClassNotFound
+			}
+			log.Error("failed to export users", sl.Err(err))
+
+			return 0, err
+		}
+		cls = &c
+		filter.ClassID = c.ID
+	}
+
+	if err = s.checkAccess(ctx, initiatorID, cls); err != nil {
+		log.Error("failed to export users", sl.Err(err))
+
+		return 0, err
+	}
+
+	out, err := newRowWriter(q.Format, w)
+	if err != nil {
+		log.Error("failed to export users", sl.Err(err))
+
+		return 0, err
+	}
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	if err = out.WriteHeader(header); err != nil {
+		log.Error("failed to export users", sl.Err(err))
+
+		return 0, err
+	}
+
+	var rows int64
+	err = s.exportStorage.ExportUsers(ctx, filter, func(u models.UserRecord) error {
+		values := make([]any, len(cols))
+		for i, c := range cols {
+			values[i] = c.value(u)
+		}
+		rows++
+
+		return out.WriteRow(values)
+	})
+	// The writer is closed even after a failure, to free the temporary
+	// files of XLSX exports.
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Error("failed to export users", sl.Err(err))
+
+		return 0, err
+	}
+
+	log.Info("users exported", slog.Int64("Rows", rows))
+
+	return rows, nil
+}
+
+// checkAccess returns ErrAccessDenied unless initiatorID holds users.export
+// or teaches cls: as its homeroom teacher or through a scoped role.
+func (s *ExportStore) checkAccess(ctx context.Context, initiatorID int64, cls *models.Class) error {
+	err := s.access.Require(ctx, initiatorID, rbac.PermUsersExport)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) || cls == nil {
+		return err
+	}
+
+	if cls.HomeroomTeacherID == initiatorID {
+		return nil
+	}
+
+	return s.access.RequireInScope(ctx, initiatorID, rbac.PermStudentsList, cls.Name)
+}
+
+// column is a column of an export. Values are strings, numbers or booleans,
+// which every format writes natively.
+type column struct {
+	name  string
+	value func(models.UserRecord) any
+}
+
+// columns are the columns an export may hold. The profile columns and roles
+// are named as imports expect them, so that a class list can be edited and
+// imported again.
+var columns = []column{
+	{"id", func(u models.UserRecord) any { return u.Profile.ID }},
+	{"email", func(u models.UserRecord) any { return u.Email }},
+	{"name", func(u models.UserRecord) any { return u.Profile.Name }},
+	{"lastname", func(u models.UserRecord) any { return u.Profile.Lastname }},
+	{"middlename", func(u models.UserRecord) any { return u.Profile.Middlename }},
+	{"date_of_birth", func(u models.UserRecord) any { return birthdate.Format(u.Profile.DateOfBirth) }},
+	{"class", func(u models.UserRecord) any { return u.Profile.Classname }},
+	{"roles", func(u models.UserRecord) any { return strings.Join(u.Roles, ",") }},
+	{"active", func(u models.UserRecord) any { return u.Active }},
+	{"school_id", func(u models.UserRecord) any { return u.SchoolID }},
+}
+
+// DefaultColumns make a class list.
+var DefaultColumns = []string{"id", "lastname", "name", "middlename", "date_of_birth", "class", "email"}
+
+// selectColumns returns the named columns in order.
+func selectColumns(names []string) ([]column, error) {
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+
+	selected := make([]column, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			return nil, fmt.Errorf("%w: column %q repeats", serviceerrors.ErrInvalidExport, name)
+		}
+		seen[name] = true
+
+		found := false
+		for _, c := range columns {
+			if c.name == name {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: unknown column %q", serviceerrors.ErrInvalidExport, name)
+		}
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,191 @@
+package exports
+
+import (
+	serviceerrors "AuthService/internal/services/service_errors"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Formats of export files.
+const (
+	FormatCSV   = "csv"
+	FormatXLSX  = "xlsx"
+	FormatJSONL = "jsonl"
+)
+
+// rowWriter writes the rows of an export in one format. Close finishes the
+// file; nothing written may be complete before it.
+type rowWriter interface {
+	WriteHeader(names []string) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+func newRowWriter(format string, w io.Writer) (rowWriter, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return &csvWriter{w: w, csv: csv.NewWriter(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	case FormatJSONL:
+		return &jsonlWriter{w: w}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown format %q", serviceerrors.ErrInvalidExport, format)
+}
+
+// csvWriter writes comma separated values, starting with a byte order mark
+// so that spreadsheets read the names as UTF-8.
+type csvWriter struct {
+	w   io.Writer
+	csv *csv.Writer
+}
+
+func (c *csvWriter) WriteHeader(names []string) error {
+	if _, err := io.WriteString(c.w, "\ufeff"); err != nil {
+		return err
+	}
+
+	return c.csv.Write(names)
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = escapeFormula(fmt.Sprint(v))
+	}
+
+	return c.csv.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.csv.Flush()
+
+	return c.csv.Error()
+}
+
+// jsonlWriter writes a JSON object per line, keyed by the column names.
+// Keys keep the order of the columns, which maps would lose.
+type jsonlWriter struct {
+	w     io.Writer
+	names [][]byte
+	line  bytes.Buffer
+}
+
+func (j *jsonlWriter) WriteHeader(names []string) error {
+	j.names = make([][]byte, len(names))
+	for i, name := range names {
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		j.names[i] = key
+	}
+
+	return nil
+}
+
+func (j *jsonlWriter) WriteRow(values []any) error {
+	j.line.Reset()
+	j.line.WriteByte('{')
+	for i, v := range values {
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			j.line.WriteByte(',')
+		}
+		j.line.Write(j.names[i])
+		j.line.WriteByte(':')
+		j.line.Write(value)
+	}
+	j.line.WriteString("}\n")
+
+	_, err := j.w.Write(j.line.Bytes())
+
+	return err
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
+
+// xlsxWriter writes a workbook of one sheet. The stream writer of excelize
+// keeps large sheets in a temporary file rather than in memory; the
+// workbook is written out when it is closed.
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+
+	stream, err := f.NewStreamWriter(f.GetSheetName(0))
+	if err != nil {
+		f.Close()
+
+		return nil, fmt.Errorf("failed to create sheet due to error: %w", err)
+	}
+
+	return &xlsxWriter{w: w, file: f, stream: stream}, nil
+}
+
+func (x *xlsxWriter) WriteHeader(names []string) error {
+	values := make([]any, len(names))
+	for i, name := range names {
+		values[i] = name
+	}
+
+	return x.WriteRow(values)
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			values[i] = escapeFormula(s)
+		}
+	}
+
+	x.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return fmt.Errorf("failed to flush sheet due to error: %w", err)
+	}
+
+	_, err := x.file.WriteTo(x.w)
+
+	return err
+}
+
+// escapeFormula keeps spreadsheets from running a value as a formula. Values
+// starting with a character that opens a formula get a leading apostrophe,
+// which spreadsheets hide and take as "this is text".
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
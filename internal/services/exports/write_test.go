@@ -0,0 +1,162 @@
+package exports
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/imports"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	testHeader = []string{"id", "name", "lastname", "active"}
+	testRows   = [][]any{
+		{int64(7), "Anna", "Ivanova", true},
+		{int64(8), "=HYPERLINK(\"http://evil\")", "@SUM(A1)", false},
+		{int64(9), "+1", "-2", true},
+		{int64(10), "\tTab", "\rReturn", false},
+	}
+)
+
+func writeRows(t *testing.T, format string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := newRowWriter(format, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteHeader(testHeader))
+	for _, row := range testRows {
+		// Writers may change the values they are given.
+		require.NoError(t, w.WriteRow(append([]any(nil), row...)))
+	}
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestRowWriter_CSV(t *testing.T) {
+	got := string(writeRows(t, "CSV"))
+
+	assert.Equal(t, "\ufeffid,name,lastname,active\n"+
+		"7,Anna,Ivanova,true\n"+
+		"8,\"'=HYPERLINK(\"\"http://evil\"\")\",'@SUM(A1),false\n"+
+		"9,'+1,'-2,true\n"+
+		"10,'\tTab,\"'\rReturn\",false\n", got)
+}
+
+func TestRowWriter_XLSX(t *testing.T) {
+	f, err := excelize.OpenReader(bytes.NewReader(writeRows(t, FormatXLSX)))
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows(f.GetSheetName(0))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "name", "lastname", "active"},
+		{"7", "Anna", "Ivanova", "TRUE"},
+		{"8", "'=HYPERLINK(\"http://evil\")", "'@SUM(A1)", "FALSE"},
+		{"9", "'+1", "'-2", "TRUE"},
+		{"10", "'\tTab", "'\rReturn", "FALSE"},
+	}, rows)
+
+	// No cell is stored as a formula.
+	formula, err := f.GetCellFormula(f.GetSheetName(0), "B3")
+	require.NoError(t, err)
+	assert.Empty(t, formula)
+}
+
+func TestRowWriter_JSONL(t *testing.T) {
+	got := string(writeRows(t, FormatJSONL))
+
+	// Keys follow the columns and values keep their types. JSON is not
+	// opened by spreadsheets, so values are written as they are.
+	assert.Equal(t, `{"id":7,"name":"Anna","lastname":"Ivanova","active":true}`+"\n"+
+		`{"id":8,"name":"=HYPERLINK(\"http://evil\")","lastname":"@SUM(A1)","active":false}`+"\n"+
+		`{"id":9,"name":"+1","lastname":"-2","active":true}`+"\n"+
+		`{"id":10,"name":"\tTab","lastname":"\rReturn","active":false}`+"\n", got)
+}
+
+func TestRowWriter_UnknownFormat(t *testing.T) {
+	_, err := newRowWriter("ods", &bytes.Buffer{})
+	assert.ErrorIs(t, err, serviceerrors.ErrInvalidExport)
+}
+
+func TestSelectColumns(t *testing.T) {
+	cols, err := selectColumns(nil)
+	require.NoError(t, err)
+	require.Len(t, cols, len(DefaultColumns))
+	for i, c := range cols {
+		assert.Equal(t, DefaultColumns[i], c.name)
+	}
+
+	cols, err = selectColumns([]string{" Email ", "id"})
+	require.NoError(t, err)
+	require.Len(t, cols, 2)
+	assert.Equal(t, "email", cols[0].name)
+	assert.Equal(t, "id", cols[1].name)
+
+	_, err = selectColumns([]string{"id", "ID"})
+	assert.ErrorIs(t, err, serviceerrors.ErrInvalidExport)
+
+	_, err = selectColumns([]string{"password"})
+	assert.ErrorIs(t, err, serviceerrors.ErrInvalidExport)
+}
+
+func TestExport_ImportsBack(t *testing.T) {
+	users := []models.UserRecord{
+		{
+			Email:   "anna@example.com",
+			Roles:   []string{"student"},
+			Profile: models.UserInfo{Name: "Anna", Lastname: "Ivanova", DateOfBirth: time.Date(2010, time.September, 1, 0, 0, 0, 0, time.UTC), Classname: "9A"},
+		},
+		{
+			Email:   "boris@example.com",
+			Roles:   []string{"student"},
+			Profile: models.UserInfo{Name: "=Boris", Lastname: "-Petrov", Middlename: "@Ivanovich", Classname: "9A"},
+		},
+	}
+
+	cols, err := selectColumns([]string{"email", "name", "lastname", "middlename", "date_of_birth", "class", "roles"})
+	require.NoError(t, err)
+
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newRowWriter(format, &buf)
+			require.NoError(t, err)
+
+			require.NoError(t, w.WriteHeader(header))
+			for _, u := range users {
+				values := make([]any, len(cols))
+				for i, c := range cols {
+					values[i] = c.value(u)
+				}
+				require.NoError(t, w.WriteRow(values))
+			}
+			require.NoError(t, w.Close())
+
+			rows, err := imports.ParseRows(format, buf.Bytes())
+			require.NoError(t, err)
+			require.Len(t, rows, 2)
+			assert.Equal(t, models.ImportRow{
+				Line: 2, Email: "anna@example.com", Name: "Anna", Lastname: "Ivanova",
+				DateOfBirth: "2010-09-01", Classname: "9A", Role: "student",
+			}, rows[0])
+			assert.Equal(t, models.ImportRow{
+				Line: 3, Email: "boris@example.com", Name: "=Boris", Lastname: "-Petrov", Middlename: "@Ivanovich",
+				Classname: "9A", Role: "student",
+			}, rows[1])
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package guardian
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/auth"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/opaque"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+type GuardianStore struct {
+	log             *slog.Logger
+	guardianStorage GuardianStorage
+	access          PermissionChecker
+	inviteTTL       time.Duration
+	activation      []ActivationHook
+}
+
+// New returns a GuardianStore. Active students who stop meeting the
+// requirements of the activation hooks when their consent is withdrawn are
+// deactivated.
+func New(log *slog.Logger, guardianStorage GuardianStorage, access PermissionChecker, inviteTTL time.Duration, activation ...ActivationHook) *GuardianStore {
+	return &GuardianStore{
+		log:             log,
+		guardianStorage: guardianStorage,
+		access:          access,
+		inviteTTL:       inviteTTL,
+		activation:      activation,
+	}
+}
+
+// ActivationHook returns what an account still lacks before it may be
+// activated, or nothing.
+type ActivationHook interface {
+	Requirements(user models.UserRecord, now time.Time) []string
+}
+
+// PermissionChecker extends the auth checks with HasRole, which tells
+// whether a user holds a role globally.
+type PermissionChecker interface {
+	auth.PermissionChecker
+	HasRole(ctx context.Context, userID int64, role string) (bool, error)
+}
+
+type GuardianStorage interface {
+	SaveGuardianInvite(ctx context.Context, studentID, createdBy int64, codeHash string, expiresAt time.Time) error
+	LinkGuardianByInvite(ctx context.Context, codeHash string, link models.GuardianLink, now time.Time) (int64, error)
+	LinkGuardian(ctx context.Context, link models.GuardianLink) error
+	UnlinkGuardian(ctx context.Context, guardianID, studentID int64) error
+	IsGuardian(ctx context.Context, guardianID, studentID int64) (bool, error)
+	ListGuardians(ctx context.Context, studentID int64) ([]models.GuardianLink, error)
+	ListChildren(ctx context.Context, guardianID int64) ([]models.GuardianLink, error)
+	SetParentalConsent(ctx context.Context, studentID, recordedBy int64, consent bool, at time.Time) error
+	GetUserRecord(ctx context.Context, userID int64) (models.UserRecord, error)
+	ChangeStatus(ctx context.Context, userID int64, isActive bool) error
+}
+
+// CreateInvite issues a one-time code that links whoever redeems it as a
+// guardian of studentID. Students may invite their own guardians; inviting
+// for others needs guardians.manage.
+func (s *GuardianStore) CreateInvite(ctx context.Context, initiatorID, studentID int64) (string, time.Time, error) {
+	const op = "guardian.CreateInvite"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("StudentID", studentID),
+		slog.Int64("InitiatorID", initiatorID),
+	)
+
+	log.Info("creating guardian invite")
+
+	if initiatorID != studentID {
+		if err := s.access.RequireFor(ctx, initiatorID, studentID, rbac.PermGuardiansManage); err != nil {
+			log.Error("failed to create invite", sl.Err(err))
+
+			return "", time.Time{}, err
+		}
+	}
+
+	code, err := opaque.New()
+	if err != nil {
+		log.Error("failed to create invite", sl.Err(err))
+
+		return "", time.Time{}, err
+	}
+
+	expiresAt := time.Now().Add(s.inviteTTL)
+	if err = s.guardianStorage.SaveGuardianInvite(ctx, studentID, initiatorID, opaque.Hash(code), expiresAt); err != nil {
+		log.Error("failed to create invite", sl.Err(err))
+
+		return "", time.Time{}, err
+	}
+
+	log.Info("guardian invite created")
+
+	return code, expiresAt, nil
+}
+
+// LinkGuardian links a guardian to a student. With an invite code the
+// initiator becomes a guardian of the student the code was issued for and
+// link.StudentID is ignored; students cannot redeem invites, so that no
+// student links themselves or a classmate. Links made with an invite are
+// not approved and do not count as consent of a parent. Without a code the
+// link needs guardians.manage over the student and is approved by the
+// initiator, which also approves an existing link. It returns the linked
+// student.
+func (s *GuardianStore) LinkGuardian(ctx context.Context, initiatorID int64, inviteCode string, link models.GuardianLink) (int64, error) {
+	const op = "guardian.LinkGuardian"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+	)
+
+	log.Info("linking guardian")
+
+	if link.Relationship == "" {
+		link.Relationship = models.RelationshipGuardian
+	}
+	if !validRelationship(link.Relationship) {
+		log.Error("failed to link guardian", sl.Err(serviceerrors.ErrInvalidGuardianLink))
+
+		return 0, serviceerrors.ErrInvalidGuardianLink
+	}
+
+	if inviteCode != "" {
+		student, err := s.access.HasRole(ctx, initiatorID, rbac.RoleStudent)
+		if err != nil {
+			log.Error("failed to link guardian", sl.Err(err))
+
+			return 0, err
+		}
+
+		if student {
+			log.Error("failed to link guardian", sl.Err(serviceerrors.ErrInvalidGuardianLink))
+
+			return 0, serviceerrors.ErrInvalidGuardianLink
+		}
+
+		link.GuardianID = initiatorID
+
+		studentID, err := s.guardianStorage.LinkGuardianByInvite(ctx, opaque.Hash(inviteCode), link, time.Now())
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrTokenNotFound):
+				err = serviceerrors.ErrInvalidInvite
+			case errors.Is(err, storage.ErrSelfGuardian):
+				err = serviceerrors.ErrInvalidGuardianLink
+			}
+			log.Error("failed to link guardian", sl.Err(err))
+
+			return 0, err
+		}
+
+		log.Info("guardian linked by invite", slog.Int64("StudentID", studentID))
+
+		return studentID, nil
+	}
+
+	if link.GuardianID == link.StudentID {
+		log.Error("failed to link guardian", sl.Err(serviceerrors.ErrInvalidGuardianLink))
+
+		return 0, serviceerrors.ErrInvalidGuardianLink
+	}
+
+	if err := s.access.RequireFor(ctx, initiatorID, link.StudentID, rbac.PermGuardiansManage); err != nil {
+		log.Error("failed to link guardian", sl.Err(err))
+
+		return 0, err
+	}
+
+	link.ApprovedBy = initiatorID
+	link.ApprovedAt = time.Now()
+	if err := s.guardianStorage.LinkGuardian(ctx, link); err != nil {
+		log.Error("failed to link guardian", sl.Err(err))
+
+		return 0, err
+	}
+
+	log.Info("guardian linked", slog.Int64("StudentID", link.StudentID), slog.Int64("GuardianID", link.GuardianID))
+
+	return link.StudentID, nil
+}
+
+// UnlinkGuardian removes a link. Guardians may unlink themselves; removing
+// other links needs guardians.manage over the student. A student left
+// without the consent their activation needs is deactivated.
+func (s *GuardianStore) UnlinkGuardian(ctx context.Context, initiatorID, guardianID, studentID int64) error {
+	const op = "guardian.UnlinkGuardian"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("GuardianID", guardianID),
+		slog.Int64("StudentID", studentID),
+		slog.Int64("InitiatorID", initiatorID),
+	)
+
+	log.Info("unlinking guardian")
+
+	if initiatorID != guardianID {
+		if err := s.access.RequireFor(ctx, initiatorID, studentID, rbac.PermGuardiansManage); err != nil {
+			log.Error("failed to unlink guardian", sl.Err(err))
+
+			return err
+		}
+	}
+
+	if err := s.guardianStorage.UnlinkGuardian(ctx, guardianID, studentID); err != nil {
+		log.Error("failed to unlink guardian", sl.Err(err))
+
+		return err
+	}
+
+	deactivated, err := s.deactivateWithoutConsent(ctx, studentID)
+	if err != nil {
+		log.Error("failed to unlink guardian", sl.Err(err))
+
+		return err
+	}
+	if deactivated {
+		log.Info("student deactivated without consent")
+	}
+
+	log.Info("guardian unlinked")
+
+	return nil
+}
+
+// ListGuardians lists the guardians of studentID. The student and their
+// guardians may list them; anyone else needs guardians.manage.
+func (s *GuardianStore) ListGuardians(ctx context.Context, initiatorID, studentID int64) ([]models.GuardianLink, error) {
+	const op = "guardian.ListGuardians"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("StudentID", studentID),
+		slog.Int64("InitiatorID", initiatorID),
+	)
+
+	log.Info("listing guardians")
+
+	if initiatorID != studentID {
+		ok, err := s.IsGuardian(ctx, initiatorID, studentID)
+		if err == nil && !ok {
+			err = s.access.RequireFor(ctx, initiatorID, studentID, rbac.PermGuardiansManage)
+		}
+		if err != nil {
+			log.Error("failed to list guardians", sl.Err(err))
+
+			return nil, err
+		}
+	}
+
+	links, err := s.guardianStorage.ListGuardians(ctx, studentID)
+	if err != nil {
+		log.Error("failed to list guardians", sl.Err(err))
+
+		return nil, err
+	}
+
+	log.Info("guardians listed")
+
+	return links, nil
+}
+
+// ListChildren lists the students guardianID is a guardian of. Guardians
+// may list their own children; anyone else needs guardians.manage over
+// guardianID.
+func (s *GuardianStore) ListChildren(ctx context.Context, initiatorID, guardianID int64) ([]models.GuardianLink, error) {
+	const op = "guardian.ListChildren"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("GuardianID", guardianID),
+		slog.Int64("InitiatorID", initiatorID),
+	)
+
+	log.Info("listing children")
+
+	if initiatorID != guardianID {
+		if err := s.access.RequireFor(ctx, initiatorID, guardianID, rbac.PermGuardiansManage); err != nil {
+			log.Error("failed to list children", sl.Err(err))
+
+			return nil, err
+		}
+	}
+
+	links, err := s.guardianStorage.ListChildren(ctx, guardianID)
+	if err != nil {
+		log.Error("failed to list children", sl.Err(err))
+
+		return nil, err
+	}
+
+	log.Info("children listed")
+
+	return links, nil
+}
+
+// SetParentalConsent records or withdraws the consent of a parent to the
+// account of studentID, given outside the service. It needs
+// guardians.manage over the student. A student left without the consent
+// their activation needs is deactivated.
+func (s *GuardianStore) SetParentalConsent(ctx context.Context, initiatorID, studentID int64, consent bool) error {
+	const op = "guardian.SetParentalConsent"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("StudentID", studentID),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Bool("Consent", consent),
+	)
+
+	log.Info("setting parental consent")
+
+	if err := s.access.RequireFor(ctx, initiatorID, studentID, rbac.PermGuardiansManage); err != nil {
+		log.Error("failed to set parental consent", sl.Err(err))
+
+		return err
+	}
+
+	if err := s.guardianStorage.SetParentalConsent(ctx, studentID, initiatorID, consent, time.Now()); err != nil {
+		log.Error("failed to set parental consent", sl.Err(err))
+
+		return err
+	}
+
+	if !consent {
+		deactivated, err := s.deactivateWithoutConsent(ctx, studentID)
+		if err != nil {
+			log.Error("failed to set parental consent", sl.Err(err))
+
+			return err
+		}
+		if deactivated {
+			log.Info("student deactivated without consent")
+		}
+	}
+
+	log.Info("parental consent set")
+
+	return nil
+}
+
+// IsGuardian reports whether guardianID is a guardian of studentID. Other
+// services use it to let guardians see the data of their children.
+func (s *GuardianStore) IsGuardian(ctx context.Context, guardianID, studentID int64) (bool, error) {
+	return s.guardianStorage.IsGuardian(ctx, guardianID, studentID)
+}
+
+// deactivateWithoutConsent deactivates studentID when the account is active
+// but no longer meets the requirements of the activation hooks, so that
+// withdrawn consent does not leave it active. It reports whether it did.
+func (s *GuardianStore) deactivateWithoutConsent(ctx context.Context, studentID int64) (bool, error) {
+	if len(s.activation) == 0 {
+		return false, nil
+	}
+
+	user, err := s.guardianStorage.GetUserRecord(ctx, studentID)
+	if err != nil || !user.Active {
+		return false, err
+	}
+
+	now := time.Now()
+	for _, hook := range s.activation {
+		if len(hook.Requirements(user, now)) > 0 {
+			return true, s.guardianStorage.ChangeStatus(ctx, studentID, false)
+		}
+	}
+
+	return false, nil
+}
+
+func validRelationship(relationship string) bool {
+	switch relationship {
+	case models.RelationshipMother, models.RelationshipFather, models.RelationshipGuardian, models.RelationshipOther:
+		return true
+	}
+
+	return false
+}
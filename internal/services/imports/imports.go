@@ -0,0 +1,459 @@
+package imports
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/auth"
+	"AuthService/internal/services/class"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/birthdate"
+	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/mailer"
+	"AuthService/pkg/tools/names"
+	"AuthService/pkg/tools/opaque"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxRows is the largest number of rows one import may hold.
+	MaxRows = 5000
+	// DefaultRole is given to rows that name no role.
+	DefaultRole = rbac.RoleStudent
+)
+
+type Config struct {
+	// InviteTTL is how long imported users may set their password.
+	InviteTTL time.Duration
+	// InviteURL is the page that receives the invite token in its "token"
+	// query parameter, the same page that confirms password resets.
+	InviteURL string
+}
+
+type ImportStore struct {
+	log           *slog.Logger
+	importStorage ImportStorage
+	hasher        PasswordHasher
+	access        auth.PermissionChecker
+	mailer        mailer.Mailer
+	cfg           Config
+	activation    []ActivationHook
+}
+
+// New creates the import service. Imported accounts start active unless one
+// of the activation hooks reports requirements for them.
+func New(log *slog.Logger, importStorage ImportStorage, hasher PasswordHasher, access auth.PermissionChecker, mailer mailer.Mailer, cfg Config, activation ...ActivationHook) *ImportStore {
+	return &ImportStore{
+		log:           log,
+		importStorage: importStorage,
+		hasher:        hasher,
+		access:        access,
+		mailer:        mailer,
+		cfg:           cfg,
+		activation:    activation,
+	}
+}
+
+// ActivationHook returns what an account still lacks before it may be
+// activated on the day of now.
+type ActivationHook interface {
+	Requirements(user models.UserRecord, now time.Time) []string
+}
+
+type ImportStorage interface {
+	GetRole(ctx context.Context, name string) (models.Role, error)
+	ListUserRoles(ctx context.Context, userID int64) ([]models.Role, error)
+	GetClassByName(ctx context.Context, name string) (models.Class, error)
+	ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
+	ImportUsers(ctx context.Context, users []models.ImportedUser) ([]int64, error)
+}
+
+type PasswordHasher interface {
+	Hash(pass string) ([]byte, error)
+}
+
+const inviteBody = `An account has been created for you at EEducation.
+
+Set your password to sign in:
+
+%s
+
+The link expires in %s.
+`
+
+// ImportUsers creates an account for every valid row of an import in the
+// school of ctx. Every row is checked before anything is created, and the
+// valid rows are created in one transaction. The results tell what happened
+// to each row; a dry run only checks them. Imported users receive an invite
+// to set their password.
+func (s *ImportStore) ImportUsers(ctx context.Context, initiatorID int64, rows []models.ImportRow, dryRun bool) ([]models.ImportResult, error) {
+	const op = "imports.ImportUsers"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Int("Rows", len(rows)),
+		slog.Bool("DryRun", dryRun),
+	)
+
+	log.Info("importing users")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermUsersImport); err != nil {
+		log.Error("failed to import users", sl.Err(err))
+
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		log.Error("failed to import users", sl.Err(serviceerrors.ErrInvalidImport))
+
+		return nil, fmt.Errorf("%w: the file has no rows", serviceerrors.ErrInvalidImport)
+	}
+
+	if len(rows) > MaxRows {
+		log.Error("failed to import users", sl.Err(serviceerrors.ErrInvalidImport))
+
+		return nil, fmt.Errorf("%w: the file has more than %d rows", serviceerrors.ErrInvalidImport, MaxRows)
+	}
+
+	results, users, err := s.check(ctx, initiatorID, rows)
+	if err != nil {
+		log.Error("failed to import users", sl.Err(err))
+
+		return nil, err
+	}
+
+	invalid := 0
+	for _, r := range results {
+		if r.Status == models.ImportInvalid {
+			invalid++
+		}
+	}
+
+	if dryRun || len(users) == 0 {
+		log.Info("import checked", slog.Int("Invalid", invalid))
+
+		return results, nil
+	}
+
+	invites, err := s.prepareInvites(users)
+	if err != nil {
+		log.Error("failed to import users", sl.Err(err))
+
+		return nil, err
+	}
+
+	ids, err := s.importStorage.ImportUsers(ctx, users)
+	if err != nil {
+		log.Error("failed to import users", sl.Err(err))
+
+		return nil, err
+	}
+
+	created := 0
+	for i := range results {
+		if results[i].Status == models.ImportValid {
+			results[i].UserID = ids[created]
+			results[i].Status = models.ImportCreated
+			created++
+		}
+	}
+
+	for i, u := range users {
+		if err = s.sendInvite(ctx, u.Email, invites[i]); err != nil {
+			// The accounts exist; an admin can send a password reset later.
+			log.Error("failed to send invite email", sl.Err(err), slog.Int64("UserID", ids[i]))
+		}
+	}
+
+	log.Info("users imported", slog.Int("Created", created), slog.Int("Invalid", invalid))
+
+	return results, nil
+}
+
+// check validates every row and returns the result of each row along with
+// the users the valid rows describe.
+func (s *ImportStore) check(ctx context.Context, initiatorID int64, rows []models.ImportRow) ([]models.ImportResult, []models.ImportedUser, error) {
+	level, err := s.maxLevel(ctx, initiatorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Email != "" {
+			emails = append(emails, row.Email)
+		}
+	}
+
+	existing, err := s.importStorage.ExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		results = make([]models.ImportResult, 0, len(rows))
+		users   = make([]models.ImportedUser, 0, len(rows))
+		seen    = make(map[string]int64)
+		roles   = make(map[string]*models.Role)
+		classes = make(map[string]*models.Class)
+		// placed counts the rows of the file that join each class.
+		placed = make(map[int64]int64)
+		now    = time.Now()
+	)
+
+	for _, row := range rows {
+		var problems []string
+
+		email := strings.TrimSpace(row.Email)
+		key := strings.ToLower(email)
+		switch {
+		case email == "":
+			problems = append(problems, "email is required")
+		case !validEmail(email):
+			problems = append(problems, "email is invalid")
+		case existing[key]:
+			problems = append(problems, "email is already registered")
+		case seen[key] != 0:
+			problems = append(problems, fmt.Sprintf("email repeats line %d", seen[key]))
+		}
+		if email != "" && seen[key] == 0 {
+			seen[key] = row.Line
+		}
+
+		problems = append(problems, checkName("name", row.Name, true)...)
+		problems = append(problems, checkName("lastname", row.Lastname, true)...)
+		problems = append(problems, checkName("middlename", row.Middlename, false)...)
+
+		dateOfBirth, err := parseDate(row.DateOfBirth, now)
+		if err != nil {
+			problems = append(problems, "date of birth "+err.Error())
+		}
+
+		roleName := strings.ToLower(strings.TrimSpace(row.Role))
+		if roleName == "" {
+			roleName = DefaultRole
+		}
+
+		role, err := s.role(ctx, roles, roleName)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case role == nil:
+			problems = append(problems, fmt.Sprintf("role %q does not exist", roleName))
+		case role.Level > level:
+			problems = append(problems, fmt.Sprintf("role %q is above your own", roleName))
+		case roleName == rbac.RoleClassTeacher:
+			// Imports carry no scope, and held globally the role would
+			// cover every student.
+			problems = append(problems, fmt.Sprintf("role %q needs a scope; grant it with GrantScopedRole", roleName))
+		}
+
+		var cls *models.Class
+		classname := class.NormalizeName(row.Classname)
+		switch {
+		case roleName == DefaultRole && classname == "":
+			problems = append(problems, "class is required for students")
+		case roleName != DefaultRole && classname != "":
+			problems = append(problems, "only students may have a class")
+		case classname != "":
+			cls, err = s.class(ctx, classes, classname)
+			if err != nil {
+				return nil, nil, err
+			}
+			if cls == nil {
+				problems = append(problems, fmt.Sprintf("class %q does not exist", classname))
+			} else if cls.Students+placed[cls.ID] >= cls.Capacity {
+				problems = append(problems, fmt.Sprintf("class %q is full", classname))
+			}
+		}
+
+		result := models.ImportResult{Line: row.Line, Email: email, Status: models.ImportValid}
+		if len(problems) > 0 {
+			result.Status = models.ImportInvalid
+			result.Errors = problems
+			results = append(results, result)
+
+			continue
+		}
+
+		user := models.ImportedUser{
+			Email: email,
+			Role:  roleName,
+			Profile: models.UserInfo{
+				Name:        strings.TrimSpace(row.Name),
+				Lastname:    strings.TrimSpace(row.Lastname),
+				Middlename:  strings.TrimSpace(row.Middlename),
+				DateOfBirth: dateOfBirth,
+			},
+		}
+		if cls != nil {
+			user.Profile.Classname = cls.Name
+			user.Profile.ClassID = cls.ID
+			placed[cls.ID]++
+		}
+
+		record := models.UserRecord{Profile: user.Profile, Email: email, Roles: []string{roleName}}
+		for _, hook := range s.activation {
+			result.ActivationRequirements = append(result.ActivationRequirements, hook.Requirements(record, now)...)
+		}
+		user.Active = len(result.ActivationRequirements) == 0
+
+		results = append(results, result)
+		users = append(users, user)
+	}
+
+	return results, users, nil
+}
+
+// prepareInvites gives every user a random password of their own that
+// nobody knows and an invite token, and returns the tokens.
+func (s *ImportStore) prepareInvites(users []models.ImportedUser) ([]string, error) {
+	expiresAt := time.Now().Add(s.cfg.InviteTTL)
+
+	invites := make([]string, len(users))
+	for i := range users {
+		password, err := opaque.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate password due to error: %w", err)
+		}
+
+		passHash, err := s.hasher.Hash(password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password due to error: %w", err)
+		}
+
+		token, err := opaque.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate invite due to error: %w", err)
+		}
+
+		invites[i] = token
+		users[i].PassHash = passHash
+		users[i].InviteHash = opaque.Hash(token)
+		users[i].InviteExpiresAt = expiresAt
+	}
+
+	return invites, nil
+}
+
+func (s *ImportStore) sendInvite(ctx context.Context, email, token string) error {
+	u, err := url.Parse(s.cfg.InviteURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse url due to error: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Your EEducation account",
+		Body:    fmt.Sprintf(inviteBody, u.String(), s.cfg.InviteTTL),
+	})
+}
+
+// maxLevel returns the highest role level of userID. Imports may not
+// create users above it.
+func (s *ImportStore) maxLevel(ctx context.Context, userID int64) (int64, error) {
+	roles, err := s.importStorage.ListUserRoles(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var level int64
+	for _, r := range roles {
+		level = max(level, r.Level)
+	}
+
+	return level, nil
+}
+
+// role returns the named role, or nil when it does not exist. Lookups are
+// cached in roles, since most rows name the same few roles.
+func (s *ImportStore) role(ctx context.Context, roles map[string]*models.Role, name string) (*models.Role, error) {
+	if r, ok := roles[name]; ok {
+		return r, nil
+	}
+
+	r, err := s.importStorage.GetRole(ctx, name)
+	if err != nil {
+		if !errors.Is(err, storage.ErrRoleNotFound) {
+			return nil, err
+		}
+		roles[name] = nil
+
+		return nil, nil
+	}
+
+	roles[name] = &r
+
+	return &r, nil
+}
+
+// class returns the open class with the given name, or nil when there is
+// none. Lookups are cached in classes.
+func (s *ImportStore) class(ctx context.Context, classes map[string]*models.Class, name string) (*models.Class, error) {
+	if c, ok := classes[name]; ok {
+		return c, nil
+	}
+
+	c, err := s.importStorage.GetClassByName(ctx, name)
+	if err != nil {
+		if !errors.Is(err, storage.ErrClassNotFound) {
+			return nil, err
+		}
+		classes[name] = nil
+
+		return nil, nil
+	}
+
+	classes[name] = &c
+
+	return &c, nil
+}
+
+// checkName returns the problem with a name column, if any, in the words
+// profile updates use.
+func checkName(field, value string, required bool) []string {
+	if err := names.Check(strings.TrimSpace(value), required); err != nil {
+		return []string{field + " " + err.Error()}
+	}
+
+	return nil
+}
+
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+
+	return err == nil && addr.Address == email
+}
+
+// parseDate returns an optional date of birth. It accepts the dates profile
+// updates accept.
+func parseDate(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := birthdate.Parse(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err = birthdate.Check(t, now); err != nil {
+		return time.Time{}, err
+	}
+
+	return t, nil
+}
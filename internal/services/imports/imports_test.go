@@ -0,0 +1,352 @@
+package imports
+
+import (
+	"AuthService/internal/models"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/mailer"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStorage struct {
+	roles    map[string]models.Role
+	level    int64
+	classes  map[string]models.Class
+	emails   map[string]bool
+	imported []models.ImportedUser
+}
+
+func (f *fakeStorage) GetRole(_ context.Context, name string) (models.Role, error) {
+	r, ok := f.roles[name]
+	if !ok {
+		return models.Role{}, storage.ErrRoleNotFound
+	}
+
+	return r, nil
+}
+
+func (f *fakeStorage) ListUserRoles(_ context.Context, _ int64) ([]models.Role, error) {
+	return []models.Role{{Name: "school_admin", Level: f.level}}, nil
+}
+
+func (f *fakeStorage) GetClassByName(_ context.Context, name string) (models.Class, error) {
+	c, ok := f.classes[name]
+	if !ok {
+		return models.Class{}, storage.ErrClassNotFound
+	}
+
+	return c, nil
+}
+
+func (f *fakeStorage) ExistingEmails(_ context.Context, emails []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	for _, e := range emails {
+		if f.emails[strings.ToLower(e)] {
+			existing[strings.ToLower(e)] = true
+		}
+	}
+
+	return existing, nil
+}
+
+func (f *fakeStorage) ImportUsers(_ context.Context, users []models.ImportedUser) ([]int64, error) {
+	f.imported = append(f.imported, users...)
+
+	ids := make([]int64, len(users))
+	for i := range users {
+		ids[i] = int64(100 + i)
+	}
+
+	return ids, nil
+}
+
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(pass string) ([]byte, error) {
+	return []byte("hash:" + pass), nil
+}
+
+type fakeAccess struct {
+	denied bool
+}
+
+func (f fakeAccess) Require(context.Context, int64, string) error {
+	if f.denied {
+		return serviceerrors.ErrAccessDenied
+	}
+
+	return nil
+}
+
+func (f fakeAccess) RequireFor(ctx context.Context, initiatorID, _ int64, permission string) error {
+	return f.Require(ctx, initiatorID, permission)
+}
+
+func (f fakeAccess) RequireRank(context.Context, int64, int64) error { return nil }
+
+func (f fakeAccess) RequireOutrank(context.Context, int64, int64) error { return nil }
+
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (f *fakeMailer) Send(_ context.Context, msg mailer.Message) error {
+	f.sent = append(f.sent, msg)
+
+	return nil
+}
+
+func newTestStore(access fakeAccess) (*ImportStore, *fakeStorage, *fakeMailer) {
+	st := &fakeStorage{
+		roles: map[string]models.Role{
+			"student":       {Name: "student", Level: 1},
+			"teacher":       {Name: "teacher", Level: 2},
+			"class_teacher": {Name: "class_teacher", Level: 2},
+			"system_admin":  {Name: "system_admin", Level: 4},
+		},
+		level: 3,
+		classes: map[string]models.Class{
+			"9A": {ID: 1, Name: "9A", Capacity: 30, Students: 10},
+			"9B": {ID: 2, Name: "9B", Capacity: 11, Students: 10},
+		},
+		emails: map[string]bool{"taken@example.com": true},
+	}
+	m := &fakeMailer{}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return New(log, st, fakeHasher{}, access, m, Config{InviteTTL: time.Hour, InviteURL: "https://example.com/reset"}), st, m
+}
+
+func TestImportUsers_DryRunReportsEveryRow(t *testing.T) {
+	s, st, m := newTestStore(fakeAccess{})
+
+	rows := []models.ImportRow{
+		{Line: 2, Email: "anna@example.com", Name: "Anna", Lastname: "Ivanova", DateOfBirth: "2010-09-01", Classname: "9a"},
+		{Line: 3, Email: "boris@example.com", Name: "Boris", Lastname: "Petrov", Role: "Teacher"},
+		{Line: 4, Email: "taken@example.com", Name: "Vera", Lastname: "Sidorova", Classname: "9A"},
+		{Line: 5, Email: "ANNA@example.com", Name: "Anna", Lastname: "Repeat", Classname: "9A"},
+	}
+
+	results, err := s.ImportUsers(context.Background(), 1, rows, true)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, models.ImportResult{Line: 2, Email: "anna@example.com", Status: models.ImportValid}, results[0])
+	assert.Equal(t, models.ImportResult{Line: 3, Email: "boris@example.com", Status: models.ImportValid}, results[1])
+	assert.Equal(t, models.ImportInvalid, results[2].Status)
+	assert.Equal(t, []string{"email is already registered"}, results[2].Errors)
+	assert.Equal(t, models.ImportInvalid, results[3].Status)
+	assert.Equal(t, []string{"email repeats line 2"}, results[3].Errors)
+
+	// A dry run creates nothing and sends nothing.
+	assert.Empty(t, st.imported)
+	assert.Empty(t, m.sent)
+}
+
+func TestImportUsers_RowErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		row  models.ImportRow
+		want []string
+	}{
+		{
+			name: "missing fields",
+			row:  models.ImportRow{Classname: "9A"},
+			want: []string{"email is required", "name is required", "lastname is required"},
+		},
+		{
+			name: "invalid email",
+			row:  models.ImportRow{Email: "Anna <anna@example.com>", Name: "Anna", Lastname: "Ivanova", Classname: "9A"},
+			want: []string{"email is invalid"},
+		},
+		{
+			name: "names as profiles check them",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna1", Lastname: strings.Repeat("x", 21), Classname: "9A"},
+			want: []string{
+				"name may only hold letters, spaces, hyphens and apostrophes",
+				"lastname is longer than 20 characters",
+			},
+		},
+		{
+			name: "date in another layout",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", DateOfBirth: "2.1.2006", Classname: "9A"},
+			want: []string{"date of birth must be a date such as 2010-09-01"},
+		},
+		{
+			name: "impossible date",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", DateOfBirth: "2010-02-30", Classname: "9A"},
+			want: []string{"date of birth is not a calendar date"},
+		},
+		{
+			name: "date in the future",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", DateOfBirth: "2999-01-01", Classname: "9A"},
+			want: []string{"date of birth is in the future"},
+		},
+		{
+			name: "unknown role",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Role: "janitor"},
+			want: []string{`role "janitor" does not exist`},
+		},
+		{
+			name: "role above the initiator",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Role: "system_admin"},
+			want: []string{`role "system_admin" is above your own`},
+		},
+		{
+			name: "scoped role",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Role: "class_teacher"},
+			want: []string{`role "class_teacher" needs a scope; grant it with GrantScopedRole`},
+		},
+		{
+			name: "student without a class",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova"},
+			want: []string{"class is required for students"},
+		},
+		{
+			name: "staff with a class",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Role: "teacher", Classname: "9A"},
+			want: []string{"only students may have a class"},
+		},
+		{
+			name: "unknown class",
+			row:  models.ImportRow{Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Classname: "0Z"},
+			want: []string{`class "0Z" does not exist`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newTestStore(fakeAccess{})
+
+			tt.row.Line = 2
+			results, err := s.ImportUsers(context.Background(), 1, []models.ImportRow{tt.row}, true)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, models.ImportInvalid, results[0].Status)
+			assert.Equal(t, tt.want, results[0].Errors)
+		})
+	}
+}
+
+func TestImportUsers_ClassFillsUp(t *testing.T) {
+	s, _, _ := newTestStore(fakeAccess{})
+
+	// 9B has one free place.
+	rows := []models.ImportRow{
+		{Line: 2, Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Classname: "9B"},
+		{Line: 3, Email: "b@example.com", Name: "Boris", Lastname: "Petrov", Classname: " 9b "},
+	}
+
+	results, err := s.ImportUsers(context.Background(), 1, rows, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, models.ImportValid, results[0].Status)
+	assert.Equal(t, []string{`class "9B" is full`}, results[1].Errors)
+}
+
+func TestImportUsers_CreatesValidRows(t *testing.T) {
+	s, st, m := newTestStore(fakeAccess{})
+
+	rows := []models.ImportRow{
+		{Line: 2, Email: "a@example.com", Name: " Anna ", Lastname: "Ivanova", DateOfBirth: "2010-09-01", Classname: "9a"},
+		{Line: 3, Email: "b@example.com", Lastname: "Petrov", Classname: "9A"},
+	}
+
+	results, err := s.ImportUsers(context.Background(), 1, rows, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, models.ImportCreated, results[0].Status)
+	assert.Equal(t, int64(100), results[0].UserID)
+	assert.Equal(t, models.ImportInvalid, results[1].Status)
+
+	require.Len(t, st.imported, 1)
+	assert.Equal(t, "Anna", st.imported[0].Profile.Name)
+	assert.Equal(t, "9A", st.imported[0].Profile.Classname)
+	assert.Equal(t, time.Date(2010, time.September, 1, 0, 0, 0, 0, time.UTC), st.imported[0].Profile.DateOfBirth)
+	assert.NotEmpty(t, st.imported[0].InviteHash)
+
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, "a@example.com", m.sent[0].To)
+}
+
+func TestImportUsers_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		access fakeAccess
+		rows   int
+		want   error
+	}{
+		{name: "no permission", access: fakeAccess{denied: true}, rows: 1, want: serviceerrors.ErrAccessDenied},
+		{name: "no rows", rows: 0, want: serviceerrors.ErrInvalidImport},
+		{name: "too many rows", rows: MaxRows + 1, want: serviceerrors.ErrInvalidImport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newTestStore(tt.access)
+
+			rows := make([]models.ImportRow, tt.rows)
+			_, err := s.ImportUsers(context.Background(), 1, rows, true)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+type fakeHook struct{}
+
+// Requirements asks every student without a date of birth for one.
+func (fakeHook) Requirements(user models.UserRecord, _ time.Time) []string {
+	if user.Roles[0] == "student" && user.Profile.DateOfBirth.IsZero() {
+		return []string{"date_of_birth"}
+	}
+
+	return nil
+}
+
+func TestImportUsers_ActivationHooks(t *testing.T) {
+	s, st, _ := newTestStore(fakeAccess{})
+	s.activation = []ActivationHook{fakeHook{}}
+
+	rows := []models.ImportRow{
+		{Line: 2, Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", DateOfBirth: "2010-09-01", Classname: "9A"},
+		{Line: 3, Email: "b@example.com", Name: "Boris", Lastname: "Petrov", Classname: "9A"},
+		{Line: 4, Email: "c@example.com", Name: "Vera", Lastname: "Sidorova", Role: "teacher"},
+	}
+
+	results, err := s.ImportUsers(context.Background(), 1, rows, false)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Empty(t, results[0].ActivationRequirements)
+	assert.Equal(t, []string{"date_of_birth"}, results[1].ActivationRequirements)
+	assert.Empty(t, results[2].ActivationRequirements)
+
+	// Accounts with requirements are created inactive.
+	require.Len(t, st.imported, 3)
+	assert.True(t, st.imported[0].Active)
+	assert.False(t, st.imported[1].Active)
+	assert.True(t, st.imported[2].Active)
+}
+
+func TestImportUsers_PasswordPerUser(t *testing.T) {
+	s, st, _ := newTestStore(fakeAccess{})
+
+	rows := []models.ImportRow{
+		{Line: 2, Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Classname: "9A"},
+		{Line: 3, Email: "b@example.com", Name: "Boris", Lastname: "Petrov", Classname: "9A"},
+	}
+
+	_, err := s.ImportUsers(context.Background(), 1, rows, false)
+	require.NoError(t, err)
+	require.Len(t, st.imported, 2)
+	assert.NotEmpty(t, st.imported[0].PassHash)
+	assert.NotEqual(t, st.imported[0].PassHash, st.imported[1].PassHash)
+	assert.NotEqual(t, st.imported[0].InviteHash, st.imported[1].InviteHash)
+}
@@ -0,0 +1,211 @@
+package imports
+
+import (
+	"AuthService/internal/models"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/pkg/tools/birthdate"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Formats of import files.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// columns maps the header names accepted in import files to the fields of
+// models.ImportRow. Headers are compared case-insensitively.
+var columns = map[string]string{
+	"email":         "email",
+	"e-mail":        "email",
+	"name":          "name",
+	"first name":    "name",
+	"firstname":     "name",
+	"lastname":      "lastname",
+	"last name":     "lastname",
+	"surname":       "lastname",
+	"middlename":    "middlename",
+	"middle name":   "middlename",
+	"patronymic":    "middlename",
+	"date of birth": "date_of_birth",
+	"date_of_birth": "date_of_birth",
+	"birth date":    "date_of_birth",
+	"dob":           "date_of_birth",
+	"class":         "class",
+	"classname":     "class",
+	"role":          "role",
+	"roles":         "role",
+}
+
+// ParseRows reads the rows of a CSV or XLSX file. The first line must be a
+// header naming the columns; columns with unknown names are ignored and
+// blank lines are skipped. CSV files may be separated by commas or, as
+// spreadsheets export them in many locales, by semicolons. Only the first
+// sheet of an XLSX file is read. The apostrophe exports put before values
+// that look like formulas is removed.
+func ParseRows(format string, data []byte) ([]models.ImportRow, error) {
+	var (
+		records [][]string
+		lines   []int64
+		err     error
+	)
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		records, lines, err = readCSV(data)
+	case FormatXLSX:
+		records, err = readXLSX(data)
+		for i := range records {
+			lines = append(lines, int64(i+1))
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", serviceerrors.ErrInvalidImport, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", serviceerrors.ErrInvalidImport)
+	}
+
+	fields := make([]string, len(records[0]))
+	hasEmail := false
+	for i, header := range records[0] {
+		header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+		fields[i] = columns[header]
+		hasEmail = hasEmail || fields[i] == "email"
+	}
+	if !hasEmail {
+		return nil, fmt.Errorf("%w: the header has no email column", serviceerrors.ErrInvalidImport)
+	}
+
+	var rows []models.ImportRow
+	for i, record := range records[1:] {
+		row := models.ImportRow{Line: lines[i+1]}
+		blank := true
+		for j, value := range record {
+			if j >= len(fields) {
+				break
+			}
+			value = strings.TrimSpace(unescapeFormula(strings.TrimSpace(value)))
+			blank = blank && value == ""
+
+			switch fields[j] {
+			case "email":
+				row.Email = value
+			case "name":
+				row.Name = value
+			case "lastname":
+				row.Lastname = value
+			case "middlename":
+				row.Middlename = value
+			case "date_of_birth":
+				row.DateOfBirth = value
+			case "class":
+				row.Classname = value
+			case "role":
+				row.Role = value
+			}
+		}
+		if !blank {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+// unescapeFormula undoes the escaping of values that would open a formula,
+// the reverse of what exports write.
+func unescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+
+	return s
+}
+
+// readCSV reads the records of a CSV file along with the line each starts
+// on, since the reader skips blank lines.
+func readCSV(data []byte) ([][]string, []int64, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	var (
+		records [][]string
+		lines   []int64
+	)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", serviceerrors.ErrInvalidImport, err)
+		}
+
+		line, _ := r.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, int64(line))
+	}
+
+	return records, lines, nil
+}
+
+// readXLSX reads the first sheet with raw cell values, turning the serial
+// numbers of date cells into dates.
+func readXLSX(data []byte) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data), excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serviceerrors.ErrInvalidImport, err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+
+	records, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", serviceerrors.ErrInvalidImport, err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	for i, header := range records[0] {
+		if columns[strings.ToLower(strings.TrimSpace(header))] != "date_of_birth" {
+			continue
+		}
+		for _, record := range records[1:] {
+			if i >= len(record) {
+				continue
+			}
+			serial, err := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			if err != nil {
+				continue
+			}
+			if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+				record[i] = t.Format(birthdate.Layout)
+			}
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,101 @@
+package imports
+
+import (
+	"AuthService/internal/models"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParseRows_CSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []models.ImportRow
+	}{
+		{
+			name: "commas",
+			data: "Email,Name,Last name,Class,Role\n" +
+				"a@example.com,Anna,Ivanova,9A,\n" +
+				"b@example.com,Boris,Petrov,,teacher\n",
+			want: []models.ImportRow{
+				{Line: 2, Email: "a@example.com", Name: "Anna", Lastname: "Ivanova", Classname: "9A"},
+				{Line: 3, Email: "b@example.com", Name: "Boris", Lastname: "Petrov", Role: "teacher"},
+			},
+		},
+		{
+			name: "semicolons, byte order mark and blank lines",
+			data: "\ufeffe-mail;surname;dob\n" +
+				"\n" +
+				"a@example.com; Ivanova ;2010-09-01\n" +
+				";;\n" +
+				"b@example.com;Petrov;\n",
+			want: []models.ImportRow{
+				{Line: 3, Email: "a@example.com", Lastname: "Ivanova", DateOfBirth: "2010-09-01"},
+				{Line: 5, Email: "b@example.com", Lastname: "Petrov"},
+			},
+		},
+		{
+			name: "unknown and missing columns",
+			data: "email,shoe size,name\n" +
+				"a@example.com,42\n",
+			want: []models.ImportRow{
+				{Line: 2, Email: "a@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseRows(FormatCSV, []byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rows)
+		})
+	}
+}
+
+func TestParseRows_XLSX(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	require.NoError(t, f.SetSheetRow(sheet, "A1", &[]any{"Email", "Name", "Date of birth"}))
+	require.NoError(t, f.SetSheetRow(sheet, "A2", &[]any{"a@example.com", "Anna", 40422}))
+	require.NoError(t, f.SetSheetRow(sheet, "A3", &[]any{"b@example.com", "Boris", "2011-02-03"}))
+
+	buf, err := f.WriteToBuffer()
+	require.NoError(t, err)
+
+	rows, err := ParseRows("XLSX", buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []models.ImportRow{
+		// Date cells hold serial numbers; 40422 is 2010-09-01.
+		{Line: 2, Email: "a@example.com", Name: "Anna", DateOfBirth: "2010-09-01"},
+		{Line: 3, Email: "b@example.com", Name: "Boris", DateOfBirth: "2011-02-03"},
+	}, rows)
+}
+
+func TestParseRows_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{name: "unknown format", format: "ods", data: "email\na@example.com\n"},
+		{name: "empty file", format: FormatCSV, data: ""},
+		{name: "no email column", format: FormatCSV, data: "name,lastname\nAnna,Ivanova\n"},
+		{name: "broken quotes", format: FormatCSV, data: "email,name\n\"a@example.com,Anna\n"},
+		{name: "not a spreadsheet", format: FormatXLSX, data: "email\na@example.com\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRows(tt.format, []byte(tt.data))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, serviceerrors.ErrInvalidImport)
+		})
+	}
+}
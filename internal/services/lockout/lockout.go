@@ -0,0 +1,224 @@
+package lockout
+
+import (
+	"AuthService/internal/models"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Store keeps the failure counters. Failures older than the window passed
+// to FailLogin are forgotten.
+type Store interface {
+	GetLoginAttempts(ctx context.Context, key string) (models.LoginAttempts, error)
+	FailLogin(ctx context.Context, key string, at time.Time, window time.Duration) (models.LoginAttempts, error)
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+// Policy describes how failures of one key are punished. After every failure
+// the next attempt has to wait BackoffBase, doubled per failure up to
+// BackoffMax; after Threshold failures the key is locked for Lockout. A zero
+// BackoffBase disables the back-off.
+type Policy struct {
+	Threshold   int
+	Lockout     time.Duration
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// Quota limits how often something may happen to one key within Window. A
+// zero Limit disables the quota.
+type Quota struct {
+	Limit  int
+	Window time.Duration
+}
+
+type Config struct {
+	Account Policy
+	IP      Policy
+	// Mail limits the emails, such as password resets, sent to one account.
+	Mail Quota
+}
+
+// Limiter tracks failed logins per account and per client IP.
+type Limiter struct {
+	log   *slog.Logger
+	store Store
+	cfg   Config
+}
+
+func New(log *slog.Logger, store Store, cfg Config) *Limiter {
+	return &Limiter{
+		log:   log,
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// Check returns a RetryAfterError when a login for email from ip has to
+// wait: ErrAccountLocked for a locked account and ErrTooManyAttempts for
+// back-off and throttled addresses.
+func (l *Limiter) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+
+	if ip != "" {
+		attempts, err := l.store.GetLoginAttempts(ctx, ipKey(ip))
+		if err != nil {
+			return err
+		}
+
+		if wait := l.cfg.IP.wait(attempts, now); wait > 0 {
+			return &serviceerrors.RetryAfterError{Err: serviceerrors.ErrTooManyAttempts, RetryAfter: wait}
+		}
+	}
+
+	attempts, err := l.store.GetLoginAttempts(ctx, accountKey(email))
+	if err != nil {
+		return err
+	}
+
+	if wait := l.cfg.Account.wait(attempts, now); wait > 0 {
+		if attempts.Failures >= l.cfg.Account.Threshold {
+			return &serviceerrors.RetryAfterError{Err: serviceerrors.ErrAccountLocked, RetryAfter: wait}
+		}
+		return &serviceerrors.RetryAfterError{Err: serviceerrors.ErrTooManyAttempts, RetryAfter: wait}
+	}
+
+	return nil
+}
+
+// Fail records a failed login for an existing account.
+func (l *Limiter) Fail(ctx context.Context, email, ip string) error {
+	now := time.Now()
+
+	if err := l.FailAddress(ctx, ip); err != nil {
+		return err
+	}
+
+	attempts, err := l.store.FailLogin(ctx, accountKey(email), now, l.cfg.Account.Lockout)
+	if err != nil {
+		return err
+	}
+
+	if attempts.Failures == l.cfg.Account.Threshold {
+		l.log.Warn("account locked", slog.String("Username", email), slog.String("IP", ip))
+	}
+
+	return nil
+}
+
+// FailAddress records a failed login for an email without an account. Only
+// the address is counted, so that guessed emails do not fill the store.
+func (l *Limiter) FailAddress(ctx context.Context, ip string) error {
+	if ip == "" {
+		return nil
+	}
+
+	_, err := l.store.FailLogin(ctx, ipKey(ip), time.Now(), l.cfg.IP.Lockout)
+
+	return err
+}
+
+// Succeed forgets the failures of an account after a correct password. The
+// counter of the address stays, since it may be shared by many accounts.
+func (l *Limiter) Succeed(ctx context.Context, email string) error {
+	return l.store.ResetLoginAttempts(ctx, accountKey(email))
+}
+
+// Unlock lifts the lockout of an account.
+func (l *Limiter) Unlock(ctx context.Context, email string) error {
+	return l.store.ResetLoginAttempts(ctx, accountKey(email))
+}
+
+// AllowMail counts an email of kind for the account email and reports
+// whether it stays within the mail quota. The failure counters of the store
+// serve as request counters here.
+func (l *Limiter) AllowMail(ctx context.Context, kind, email string) (bool, error) {
+	if l.cfg.Mail.Limit <= 0 {
+		return true, nil
+	}
+
+	attempts, err := l.store.FailLogin(ctx, mailKey(kind, email), time.Now(), l.cfg.Mail.Window)
+	if err != nil {
+		return false, err
+	}
+
+	return attempts.Failures <= l.cfg.Mail.Limit, nil
+}
+
+// Pruner is implemented by stores that keep forgotten counters until they
+// are pruned.
+type Pruner interface {
+	PruneLoginAttempts(ctx context.Context, before time.Time) error
+}
+
+// Run prunes the counters of the store every interval until ctx is
+// cancelled. Stores that are not a Pruner are left alone.
+func (l *Limiter) Run(ctx context.Context, interval time.Duration) {
+	pruner, ok := l.store.(Pruner)
+	if !ok {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := pruner.PruneLoginAttempts(ctx, now.Add(-l.cfg.window())); err != nil {
+				l.log.Error("failed to prune login attempts", sl.Err(err))
+			}
+		}
+	}
+}
+
+// window is the longest time a counter is remembered.
+func (c Config) window() time.Duration {
+	return max(c.Account.Lockout, c.IP.Lockout, c.Mail.Window)
+}
+
+func (p Policy) wait(attempts models.LoginAttempts, now time.Time) time.Duration {
+	if attempts.Failures == 0 || now.Sub(attempts.LastFailure) >= p.Lockout {
+		return 0
+	}
+
+	if p.Threshold > 0 && attempts.Failures >= p.Threshold {
+		return attempts.LastFailure.Add(p.Lockout).Sub(now)
+	}
+
+	if p.BackoffBase <= 0 {
+		return 0
+	}
+
+	delay := p.BackoffBase
+	for i := 1; i < attempts.Failures && delay < p.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > p.BackoffMax {
+		delay = p.BackoffMax
+	}
+
+	if wait := attempts.LastFailure.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+
+	return 0
+}
+
+func accountKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func mailKey(kind, email string) string {
+	return "mail:" + kind + ":" + strings.ToLower(email)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
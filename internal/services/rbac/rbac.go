@@ -0,0 +1,308 @@
+package rbac
+
+import (
+	"AuthService/internal/models"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Permissions checked by the services. They are seeded by the rbac
+// migration; a new permission needs a migration as well.
+const (
+	PermUserStatusChange  = "users.status.change"
+	PermUserPermissionSet = "users.permission.set"
+	PermUserDelete        = "users.delete"
+	PermUserProfileEdit   = "users.profile.edit"
+	PermStudentsList      = "students.list"
+	PermStudentsListAll   = "students.list.all"
+	PermRolesAssign       = "roles.assign"
+	PermRolesRead         = "roles.read"
+	PermSessionsManage    = "sessions.manage"
+	PermAccountsUnlock    = "accounts.unlock"
+	PermMFAManage         = "mfa.manage"
+	PermClassesManage     = "classes.manage"
+	PermGuardiansManage   = "guardians.manage"
+	PermSchoolsManage     = "schools.manage"
+	PermUsersImport       = "users.import"
+	PermUsersExport       = "users.export"
+	PermUsersRead         = "users.read"
+)
+
+// Roles the services refer to by name.
+const (
+	RoleStudent = "student"
+	RoleParent  = "parent"
+	// RoleClassTeacher only grants permissions through a scope and is
+	// never assigned globally.
+	RoleClassTeacher = "class_teacher"
+)
+
+type RBACStore struct {
+	log         *slog.Logger
+	roleStorage RoleStorage
+}
+
+func New(log *slog.Logger, roleStorage RoleStorage) *RBACStore {
+	return &RBACStore{
+		log:         log,
+		roleStorage: roleStorage,
+	}
+}
+
+type RoleStorage interface {
+	HasPermission(ctx context.Context, userID int64, permission string) (bool, error)
+	GetRole(ctx context.Context, name string) (models.Role, error)
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	ListUserRoles(ctx context.Context, userID int64) ([]models.Role, error)
+	AssignRole(ctx context.Context, userID int64, role string) error
+	RevokeRole(ctx context.Context, userID int64, role string) error
+	HasScopedPermission(ctx context.Context, userID int64, permission, classname, grade string) (bool, error)
+	ListScopedRoles(ctx context.Context, userID int64) ([]models.ScopedRole, error)
+	GrantScopedRole(ctx context.Context, userID int64, role models.ScopedRole) error
+	RevokeScopedRole(ctx context.Context, userID int64, role models.ScopedRole) error
+	UserExists(ctx context.Context, userID int64) (bool, error)
+	GetUserInfo(ctx context.Context, userID int64) (models.UserInfo, error)
+}
+
+// Require returns ErrAccessDenied unless one of the roles of the user grants
+// permission.
+func (s *RBACStore) Require(ctx context.Context, userID int64, permission string) error {
+	ok, err := s.roleStorage.HasPermission(ctx, userID, permission)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return serviceerrors.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// CheckPermission reports whether userID holds permission. Users may check
+// themselves; checking others needs roles.read.
+func (s *RBACStore) CheckPermission(ctx context.Context, initiatorID, userID int64, permission string) (bool, error) {
+	const op = "rbac.CheckPermission"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("UserID", userID),
+		slog.String("Permission", permission),
+	)
+
+	log.Info("checking permission")
+
+	if initiatorID != userID {
+		if err := s.checkRead(ctx, initiatorID, userID); err != nil {
+			log.Error("failed to check permission", sl.Err(err))
+
+			return false, err
+		}
+	}
+
+	ok, err := s.roleStorage.HasPermission(ctx, userID, permission)
+	if err != nil {
+		log.Error("failed to check permission", sl.Err(err))
+
+		return false, err
+	}
+
+	log.Info("permission checked", slog.Bool("Allowed", ok))
+
+	return ok, nil
+}
+
+// ListRoles returns the roles of userID, or every defined role when userID
+// is zero. Users may list their own roles; listing others needs roles.read.
+func (s *RBACStore) ListRoles(ctx context.Context, initiatorID, userID int64) ([]models.Role, error) {
+	const op = "rbac.ListRoles"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("UserID", userID),
+	)
+
+	log.Info("listing roles")
+
+	if userID == 0 {
+		roles, err := s.roleStorage.ListRoles(ctx)
+		if err != nil {
+			log.Error("failed to list roles", sl.Err(err))
+
+			return nil, err
+		}
+
+		return roles, nil
+	}
+
+	if initiatorID != userID {
+		if err := s.checkRead(ctx, initiatorID, userID); err != nil {
+			log.Error("failed to list roles", sl.Err(err))
+
+			return nil, err
+		}
+	}
+
+	roles, err := s.roleStorage.ListUserRoles(ctx, userID)
+	if err != nil {
+		log.Error("failed to list roles", sl.Err(err))
+
+		return nil, err
+	}
+
+	log.Info("roles listed")
+
+	return roles, nil
+}
+
+// AssignRole gives a role to a user. The initiator needs roles.assign and
+// may not hand out roles above their own level, nor change the roles of a
+// user who outranks them. Roles that only make sense
+// over a scope are granted with GrantScopedRole instead.
+func (s *RBACStore) AssignRole(ctx context.Context, initiatorID, userID int64, role string) error {
+	const op = "rbac.AssignRole"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("UserID", userID),
+		slog.String("Role", role),
+	)
+
+	log.Info("assigning role")
+
+	if role == RoleClassTeacher {
+		err := fmt.Errorf("%w: %s needs a class, grade or school scope", serviceerrors.ErrInvalidScope, role)
+		log.Error("failed to assign role", sl.Err(err))
+
+		return err
+	}
+
+	if err := s.checkRoleChange(ctx, initiatorID, userID, role); err != nil {
+		log.Error("failed to assign role", sl.Err(err))
+
+		return err
+	}
+
+	if err := s.roleStorage.AssignRole(ctx, userID, role); err != nil {
+		log.Error("failed to assign role", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("role assigned")
+
+	return nil
+}
+
+// RevokeRole takes a role from a user under the same rules as AssignRole.
+func (s *RBACStore) RevokeRole(ctx context.Context, initiatorID, userID int64, role string) error {
+	const op = "rbac.RevokeRole"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("UserID", userID),
+		slog.String("Role", role),
+	)
+
+	log.Info("revoking role")
+
+	if err := s.checkRoleChange(ctx, initiatorID, userID, role); err != nil {
+		log.Error("failed to revoke role", sl.Err(err))
+
+		return err
+	}
+
+	if err := s.roleStorage.RevokeRole(ctx, userID, role); err != nil {
+		log.Error("failed to revoke role", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("role revoked")
+
+	return nil
+}
+
+// checkRead checks that initiatorID may read the roles of userID: it needs
+// roles.read, and userID has to be a user of the school of ctx.
+func (s *RBACStore) checkRead(ctx context.Context, initiatorID, userID int64) error {
+	if err := s.Require(ctx, initiatorID, PermRolesRead); err != nil {
+		return err
+	}
+
+	ok, err := s.roleStorage.UserExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// checkRoleChange checks that initiatorID may give role to userID or take
+// it away: it needs roles.assign, the level of role and the rank of userID.
+func (s *RBACStore) checkRoleChange(ctx context.Context, initiatorID, userID int64, role string) error {
+	if err := s.Require(ctx, initiatorID, PermRolesAssign); err != nil {
+		return err
+	}
+
+	target, err := s.roleStorage.GetRole(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	if err = s.RequireRank(ctx, initiatorID, target.Level); err != nil {
+		return err
+	}
+
+	return s.RequireOutrank(ctx, initiatorID, userID)
+}
+
+// RequireRank returns ErrAccessDenied unless one of the roles of userID
+// reaches level.
+func (s *RBACStore) RequireRank(ctx context.Context, userID, level int64) error {
+	rank, err := s.rank(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if rank < level {
+		return serviceerrors.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// RequireOutrank returns ErrAccessDenied when userID holds a role above
+// every role of initiatorID, so that no one manages an account of a higher
+// rank than their own.
+func (s *RBACStore) RequireOutrank(ctx context.Context, initiatorID, userID int64) error {
+	rank, err := s.rank(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.RequireRank(ctx, initiatorID, rank)
+}
+
+// rank returns the highest level of the roles of userID.
+func (s *RBACStore) rank(ctx context.Context, userID int64) (int64, error) {
+	roles, err := s.roleStorage.ListUserRoles(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var rank int64
+	for _, r := range roles {
+		rank = max(rank, r.Level)
+	}
+
+	return rank, nil
+}
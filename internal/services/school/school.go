@@ -0,0 +1,92 @@
+package school
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/auth"
+	"AuthService/internal/services/rbac"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type SchoolStore struct {
+	log           *slog.Logger
+	schoolStorage SchoolStorage
+	access        auth.PermissionChecker
+}
+
+func New(log *slog.Logger, schoolStorage SchoolStorage, access auth.PermissionChecker) *SchoolStore {
+	return &SchoolStore{
+		log:           log,
+		schoolStorage: schoolStorage,
+		access:        access,
+	}
+}
+
+type SchoolStorage interface {
+	CreateSchool(ctx context.Context, name string) (int64, error)
+	ListSchools(ctx context.Context) ([]models.School, error)
+}
+
+// CreateSchool adds a school to the deployment. It needs schools.manage,
+// which only super admins hold.
+func (s *SchoolStore) CreateSchool(ctx context.Context, initiatorID int64, name string) (models.School, error) {
+	const op = "school.CreateSchool"
+
+	name = strings.TrimSpace(name)
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.String("Name", name),
+	)
+
+	log.Info("creating school")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermSchoolsManage); err != nil {
+		log.Error("failed to create school", sl.Err(err))
+
+		return models.School{}, err
+	}
+
+	id, err := s.schoolStorage.CreateSchool(ctx, name)
+	if err != nil {
+		log.Error("failed to create school", sl.Err(err))
+
+		return models.School{}, err
+	}
+
+	log.Info("school created", slog.Int64("SchoolID", id))
+
+	return models.School{ID: id, Name: name, CreatedAt: time.Now()}, nil
+}
+
+func (s *SchoolStore) ListSchools(ctx context.Context, initiatorID int64) ([]models.School, error) {
+	const op = "school.ListSchools"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+	)
+
+	log.Info("listing schools")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermSchoolsManage); err != nil {
+		log.Error("failed to list schools", sl.Err(err))
+
+		return nil, err
+	}
+
+	schools, err := s.schoolStorage.ListSchools(ctx)
+	if err != nil {
+		log.Error("failed to list schools", sl.Err(err))
+
+		return nil, err
+	}
+
+	log.Info("schools listed")
+
+	return schools, nil
+}
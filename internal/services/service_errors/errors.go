@@ -1,10 +1,102 @@
 package serviceerrors
 
-import "errors"
+import (
+	"AuthService/pkg/tools/passpolicy"
+	"errors"
+	"strings"
+	"time"
+)
 
 var (
-	ErrAccessDenied       = errors.New("Not enough permissions")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrBadEmailFormat     = errors.New("bad email format")
-	ErrClassNotFound      = errors.New("class not found")
+	ErrAccessDenied        = errors.New("Not enough permissions")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrBadEmailFormat      = errors.New("bad email format")
+	ErrClassNotFound       = errors.New("class not found")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrTokenRevoked        = errors.New("token revoked")
+	ErrInvalidResetToken   = errors.New("invalid password reset token")
+	ErrInvalidVerification = errors.New("invalid email verification token")
+	ErrEmailNotVerified    = errors.New("email is not verified")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidMFACode      = errors.New("invalid two-factor code")
+	ErrInvalidMFAChallenge = errors.New("invalid two-factor challenge")
+	ErrAccountLocked       = errors.New("account is temporarily locked")
+	ErrTooManyAttempts     = errors.New("too many login attempts")
+	ErrTooManyEmails       = errors.New("too many emails for the account")
+	ErrWeakPassword        = errors.New("password does not meet the policy")
+	ErrInvalidScope        = errors.New("invalid role scope")
+	ErrInvalidClass        = errors.New("invalid class")
+	ErrClassArchived       = errors.New("class is archived")
+	ErrClassFull           = errors.New("class is full")
+	ErrNotStudent          = errors.New("user is not a student")
+	ErrInvalidGuardianLink = errors.New("invalid guardian link")
+	ErrInvalidInvite       = errors.New("invalid guardian invite code")
+	ErrInvalidImport       = errors.New("invalid import file")
+	ErrInvalidExport       = errors.New("invalid export request")
+	ErrInvalidUserQuery    = errors.New("invalid user query")
+	ErrInvalidProfile      = errors.New("invalid profile")
+	ErrProfileLocked       = errors.New("profile is locked")
+	ErrAccountActive       = errors.New("account is already active")
+	ErrActivationBlocked   = errors.New("account does not meet the activation requirements")
 )
+
+// PasswordPolicyError lists the password rules a new password breaks.
+type PasswordPolicyError struct {
+	Violations []passpolicy.Violation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error()
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// ProfileError lists the fields of a profile update that are not valid.
+type ProfileError struct {
+	Violations []FieldViolation
+}
+
+// FieldViolation tells why the value of a field was rejected.
+type FieldViolation struct {
+	Field   string
+	Message string
+}
+
+func (e *ProfileError) Error() string {
+	return ErrInvalidProfile.Error()
+}
+
+func (e *ProfileError) Unwrap() error {
+	return ErrInvalidProfile
+}
+
+// ActivationError lists what an account lacks before it may be activated.
+type ActivationError struct {
+	Requirements []string
+}
+
+func (e *ActivationError) Error() string {
+	return ErrActivationBlocked.Error() + ": " + strings.Join(e.Requirements, ", ")
+}
+
+func (e *ActivationError) Unwrap() error {
+	return ErrActivationBlocked
+}
+
+// RetryAfterError tells the caller when a rejected request may be retried.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
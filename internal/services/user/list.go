@@ -0,0 +1,218 @@
+package user
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the page size of listings that ask for none.
+	DefaultPageSize = 50
+	// MaxPageSize is the largest page a listing returns.
+	MaxPageSize = 500
+)
+
+// ListQuery selects a page of ListUsers. Classname names an open class.
+// OrderBy is one of the models.SortUsersBy orders, descending when it
+// starts with "-"; it defaults to lastname. PageToken continues the
+// listing that returned it.
+type ListQuery struct {
+	Filter    models.UserFilter
+	Classname string
+	OrderBy   string
+	PageSize  int
+	PageToken string
+}
+
+// ListUsers returns a page of the users of the school of ctx that match q,
+// the token of the next page, empty on the last one, and the number of
+// matching users. Listing needs users.read, except that teachers may list
+// the class they teach.
+func (s *UserStore) ListUsers(ctx context.Context, initiatorID int64, q ListQuery) ([]models.UserRecord, string, int64, error) {
+	const op = "user.ListUsers"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.String("Classname", q.Classname),
+		slog.String("OrderBy", q.OrderBy),
+	)
+
+	log.Info("listing users")
+
+	page, err := pageOf(q)
+	if err != nil {
+		log.Error("failed to list users", sl.Err(err))
+
+		return nil, "", 0, err
+	}
+
+	filter := q.Filter
+	filter.Role = strings.ToLower(strings.TrimSpace(filter.Role))
+	filter.EmailPrefix = strings.TrimSpace(filter.EmailPrefix)
+	filter.Name = strings.TrimSpace(filter.Name)
+
+	var cls *models.Class
+	if q.Classname != "" {
+		c, err := s.getClass(ctx, q.Classname)
+		if err != nil {
+			log.Error("failed to list users", sl.Err(err))
+
+			return nil, "", 0, err
+		}
+		cls = &c
+		filter.ClassID = c.ID
+	}
+
+	if err = s.checkListAccess(ctx, initiatorID, cls); err != nil {
+		log.Error("failed to list users", sl.Err(err))
+
+		return nil, "", 0, err
+	}
+
+	fingerprint := filterFingerprint(filter)
+	if q.PageToken != "" {
+		if page.After, err = decodePageToken(q.PageToken, page, fingerprint); err != nil {
+			log.Error("failed to list users", sl.Err(err))
+
+			return nil, "", 0, err
+		}
+	}
+
+	// One user more than the page tells whether another page follows.
+	limit := page.Limit
+	page.Limit++
+
+	users, err := s.userHelper.ListUsers(ctx, filter, page)
+	if err != nil {
+		log.Error("failed to list users", sl.Err(err))
+
+		return nil, "", 0, err
+	}
+
+	total, err := s.userHelper.CountUsers(ctx, filter)
+	if err != nil {
+		log.Error("failed to list users", sl.Err(err))
+
+		return nil, "", 0, err
+	}
+
+	var next string
+	if len(users) > limit {
+		users = users[:limit]
+		next = encodePageToken(page, users[limit-1].Cursor(page.SortBy), fingerprint)
+	}
+
+	now := time.Now()
+	for i := range users {
+		users[i].ActivationRequirements = s.activationRequirements(users[i], now)
+	}
+
+	log.Info("users listed", slog.Int("Users", len(users)), slog.Int64("Total", total))
+
+	return users, next, total, nil
+}
+
+// checkListAccess returns ErrAccessDenied unless initiatorID holds
+// users.read or may list the students of cls.
+func (s *UserStore) checkListAccess(ctx context.Context, initiatorID int64, cls *models.Class) error {
+	err := s.access.Require(ctx, initiatorID, rbac.PermUsersRead)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) || cls == nil {
+		return err
+	}
+
+	return s.checkClassAccess(ctx, initiatorID, *cls)
+}
+
+// pageOf returns the sort order and the size of the page q asks for.
+func pageOf(q ListQuery) (models.UserPage, error) {
+	page := models.UserPage{SortBy: models.SortUsersByLastname, Limit: q.PageSize}
+
+	if orderBy := strings.TrimSpace(q.OrderBy); orderBy != "" {
+		page.SortBy, page.Descending = strings.CutPrefix(orderBy, "-")
+	}
+
+	switch page.SortBy {
+	case models.SortUsersByLastname, models.SortUsersByEmail, models.SortUsersByCreatedAt, models.SortUsersByID:
+	default:
+		return page, fmt.Errorf("%w: unknown sort order %q", serviceerrors.ErrInvalidUserQuery, page.SortBy)
+	}
+
+	switch {
+	case page.Limit < 0:
+		return page, fmt.Errorf("%w: page size must not be negative", serviceerrors.ErrInvalidUserQuery)
+	case page.Limit == 0:
+		page.Limit = DefaultPageSize
+	case page.Limit > MaxPageSize:
+		page.Limit = MaxPageSize
+	}
+
+	return page, nil
+}
+
+// pageToken is the position of a listing between two pages. It keeps the
+// sort order and a fingerprint of the filter, so that a token cannot
+// continue a different listing.
+type pageToken struct {
+	SortBy     string   `json:"s"`
+	Descending bool     `json:"d,omitempty"`
+	Values     []string `json:"v,omitempty"`
+	ID         int64    `json:"i"`
+	Filter     string   `json:"f"`
+}
+
+func encodePageToken(page models.UserPage, cursor models.UserCursor, fingerprint string) string {
+	b, _ := json.Marshal(pageToken{
+		SortBy:     page.SortBy,
+		Descending: page.Descending,
+		Values:     cursor.Values,
+		ID:         cursor.ID,
+		Filter:     fingerprint,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageToken(token string, page models.UserPage, fingerprint string) (*models.UserCursor, error) {
+	invalid := fmt.Errorf("%w: invalid page token", serviceerrors.ErrInvalidUserQuery)
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var t pageToken
+	if err = json.Unmarshal(b, &t); err != nil {
+		return nil, invalid
+	}
+
+	if len(t.Values) != len(models.UserRecord{}.Cursor(t.SortBy).Values) {
+		return nil, invalid
+	}
+
+	if t.SortBy != page.SortBy || t.Descending != page.Descending || t.Filter != fingerprint {
+		return nil, fmt.Errorf("%w: the page token belongs to another query", serviceerrors.ErrInvalidUserQuery)
+	}
+
+	return &models.UserCursor{Values: t.Values, ID: t.ID}, nil
+}
+
+// filterFingerprint identifies the users filter selects.
+func filterFingerprint(filter models.UserFilter) string {
+	b, _ := json.Marshal(filter)
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,349 @@
+package user
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/services/rbac"
+	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
+	"AuthService/pkg/tools/birthdate"
+	"AuthService/pkg/tools/logger/sl"
+	"AuthService/pkg/tools/names"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GetUserProfile returns the profile of userID. Users may read their own
+// profile; other profiles need users.read, users.profile.edit over the
+// class of the user, or access to the class as its teacher. The initiator
+// is authorized before the profile is loaded, so that those who may not
+// read it cannot tell whether userID exists.
+func (s *UserStore) GetUserProfile(ctx context.Context, initiatorID, userID int64) (models.UserRecord, error) {
+	const op = "user.GetUserProfile"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("UserID", userID),
+		slog.Int64("InitiatorID", initiatorID),
+	)
+
+	log.Info("getting user profile")
+
+	if err := s.checkProfileRead(ctx, initiatorID, userID); err != nil {
+		log.Error("failed to get profile", sl.Err(err))
+
+		return models.UserRecord{}, err
+	}
+
+	user, err := s.userHelper.GetUserRecord(ctx, userID)
+	if err != nil {
+		log.Error("failed to get profile", sl.Err(err))
+
+		return models.UserRecord{}, err
+	}
+	user.ActivationRequirements = s.activationRequirements(user, time.Now())
+
+	log.Info("profile found")
+
+	return user, nil
+}
+
+// checkProfileRead returns ErrAccessDenied unless initiatorID may read the
+// profile of userID. Unknown users are reported as ErrAccessDenied to those
+// without users.read.
+func (s *UserStore) checkProfileRead(ctx context.Context, initiatorID, userID int64) error {
+	if initiatorID == userID {
+		return nil
+	}
+
+	err := s.access.Require(ctx, initiatorID, rbac.PermUsersRead)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return err
+	}
+
+	err = s.access.RequireFor(ctx, initiatorID, userID, rbac.PermUserProfileEdit)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return err
+	}
+
+	info, err := s.userHelper.GetUserInfo(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return serviceerrors.ErrAccessDenied
+		}
+
+		return err
+	}
+
+	if info.Classname == "" {
+		return serviceerrors.ErrAccessDenied
+	}
+
+	cls, err := s.getClass(ctx, info.Classname)
+	if err != nil {
+		if errors.Is(err, serviceerrors.ErrClassNotFound) {
+			return serviceerrors.ErrAccessDenied
+		}
+
+		return err
+	}
+
+	return s.checkClassAccess(ctx, initiatorID, cls)
+}
+
+// UpdateUserProfile sets the fields of the profile of userID named by
+// fields to their values in update, whether or not the account is active.
+// Users may update their own profile until it is locked; other profiles
+// need users.profile.edit. A locked profile is only updated with
+// overrideLock, by holders of users.profile.edit over every class who
+// outrank the user, and never by the user themselves. Moving
+// the user to another class also needs classes.manage over that class. The
+// date of birth of an active account only changes to one that still meets
+// the activation requirements.
+func (s *UserStore) UpdateUserProfile(ctx context.Context, initiatorID, userID int64, update models.UserInfo, fields []string, overrideLock bool) (models.UserRecord, error) {
+	const op = "user.UpdateUserProfile"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("UserID", userID),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Any("Fields", fields),
+		slog.Bool("OverrideLock", overrideLock),
+	)
+
+	log.Info("updating user profile")
+
+	info, err := s.checkProfileUpdate(ctx, initiatorID, userID, overrideLock)
+	if err != nil {
+		log.Error("failed to update profile", sl.Err(err))
+
+		return models.UserRecord{}, err
+	}
+
+	update.ID = userID
+	if err = validateProfile(&update, fields); err != nil {
+		log.Error("failed to update profile", sl.Err(err))
+
+		return models.UserRecord{}, err
+	}
+
+	if slices.Contains(fields, models.ProfileDateOfBirth) {
+		if err = s.checkStillActivatable(ctx, userID, update.DateOfBirth); err != nil {
+			log.Error("failed to update profile", sl.Err(err))
+
+			return models.UserRecord{}, err
+		}
+	}
+
+	if slices.Contains(fields, models.ProfileClassname) {
+		cls, err := s.getClass(ctx, update.Classname)
+		if err != nil {
+			log.Error("failed to update profile", sl.Err(err))
+
+			return models.UserRecord{}, err
+		}
+
+		if info.ClassID != cls.ID {
+			if err = s.requireClassesManage(ctx, initiatorID, cls); err != nil {
+				log.Error("failed to update profile", sl.Err(err))
+
+				return models.UserRecord{}, err
+			}
+		}
+		update.Classname = cls.Name
+		update.ClassID = cls.ID
+	}
+
+	if err = s.userHelper.UpdateUserInfo(ctx, update, fields); err != nil {
+		log.Error("failed to update profile", sl.Err(err))
+
+		if errors.Is(err, storage.ErrClassFull) {
+			return models.UserRecord{}, serviceerrors.ErrClassFull
+		}
+
+		return models.UserRecord{}, err
+	}
+
+	user, err := s.userHelper.GetUserRecord(ctx, userID)
+	if err != nil {
+		log.Error("failed to update profile", sl.Err(err))
+
+		return models.UserRecord{}, err
+	}
+	user.ActivationRequirements = s.activationRequirements(user, time.Now())
+
+	log.Info("profile updated")
+
+	return user, nil
+}
+
+// requireClassesManage returns ErrAccessDenied unless initiatorID holds
+// classes.manage over every class or over cls.
+func (s *UserStore) requireClassesManage(ctx context.Context, initiatorID int64, cls models.Class) error {
+	err := s.access.Require(ctx, initiatorID, rbac.PermClassesManage)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return err
+	}
+
+	return s.access.RequireInScope(ctx, initiatorID, rbac.PermClassesManage, cls.Name)
+}
+
+// activationRequirements collects the requirements of every activation hook
+// for an inactive account.
+func (s *UserStore) activationRequirements(user models.UserRecord, now time.Time) []string {
+	if user.Active {
+		return nil
+	}
+
+	return s.requirements(user, now)
+}
+
+func (s *UserStore) requirements(user models.UserRecord, now time.Time) []string {
+	var requirements []string
+	for _, hook := range s.activation {
+		requirements = append(requirements, hook.Requirements(user, now)...)
+	}
+
+	return requirements
+}
+
+// checkStillActivatable returns an ActivationError when userID is active
+// and would no longer meet the activation requirements with dateOfBirth, so
+// that a profile update cannot leave an account active that could not be
+// activated.
+func (s *UserStore) checkStillActivatable(ctx context.Context, userID int64, dateOfBirth time.Time) error {
+	user, err := s.userHelper.GetUserRecord(ctx, userID)
+	if err != nil || !user.Active {
+		return err
+	}
+
+	user.Profile.DateOfBirth = dateOfBirth
+	if requirements := s.requirements(user, time.Now()); len(requirements) > 0 {
+		return &serviceerrors.ActivationError{Requirements: requirements}
+	}
+
+	return nil
+}
+
+// checkProfileUpdate authorizes the update before loading the profile of
+// userID, so that callers without users.read cannot tell unknown users from
+// those they may not edit, and returns the profile. Updates of a locked
+// profile without overrideLock get ErrProfileLocked.
+func (s *UserStore) checkProfileUpdate(ctx context.Context, initiatorID, userID int64, overrideLock bool) (models.UserInfo, error) {
+	switch {
+	case overrideLock && initiatorID == userID:
+		return models.UserInfo{}, serviceerrors.ErrAccessDenied
+	case overrideLock:
+		if err := s.access.Require(ctx, initiatorID, rbac.PermUserProfileEdit); err != nil {
+			return models.UserInfo{}, err
+		}
+	case initiatorID != userID:
+		if err := s.access.RequireFor(ctx, initiatorID, userID, rbac.PermUserProfileEdit); err != nil {
+			return models.UserInfo{}, err
+		}
+	}
+
+	info, err := s.userHelper.GetUserInfo(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) && initiatorID != userID {
+			if readErr := s.access.Require(ctx, initiatorID, rbac.PermUsersRead); readErr != nil {
+				return models.UserInfo{}, readErr
+			}
+		}
+
+		return models.UserInfo{}, err
+	}
+
+	if overrideLock {
+		if err = s.access.RequireOutrank(ctx, initiatorID, userID); err != nil {
+			return models.UserInfo{}, err
+		}
+
+		return info, nil
+	}
+
+	if info.ProfileLocked {
+		return models.UserInfo{}, serviceerrors.ErrProfileLocked
+	}
+
+	return info, nil
+}
+
+// validateProfile checks the named fields of user and trims their values.
+// All problems are returned at once in a ProfileError.
+func validateProfile(user *models.UserInfo, fields []string) error {
+	var violations []serviceerrors.FieldViolation
+	violate := func(field, msg string) {
+		violations = append(violations, serviceerrors.FieldViolation{Field: field, Message: msg})
+	}
+
+	if len(fields) == 0 {
+		violate("update_mask", "at least one field is required")
+	}
+
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if seen[field] {
+			violate("update_mask", fmt.Sprintf("field %q repeats", field))
+			continue
+		}
+		seen[field] = true
+
+		switch field {
+		case models.ProfileName:
+			user.Name = strings.TrimSpace(user.Name)
+			if err := names.Check(user.Name, true); err != nil {
+				violate(field, err.Error())
+			}
+		case models.ProfileLastname:
+			user.Lastname = strings.TrimSpace(user.Lastname)
+			if err := names.Check(user.Lastname, true); err != nil {
+				violate(field, err.Error())
+			}
+		case models.ProfileMiddlename:
+			user.Middlename = strings.TrimSpace(user.Middlename)
+			if err := names.Check(user.Middlename, false); err != nil {
+				violate(field, err.Error())
+			}
+		case models.ProfileDateOfBirth:
+			if user.DateOfBirth.IsZero() {
+				break
+			}
+			if err := birthdate.Check(user.DateOfBirth, time.Now()); err != nil {
+				violate(field, err.Error())
+			}
+		case models.ProfileClassname:
+			if strings.TrimSpace(user.Classname) == "" {
+				violate(field, "is required")
+			}
+		default:
+			violate("update_mask", fmt.Sprintf("field %q cannot be updated", field))
+		}
+	}
+
+	if len(violations) > 0 {
+		return &serviceerrors.ProfileError{Violations: violations}
+	}
+
+	return nil
+}
+
+// checkDateOfBirth returns a ProfileError unless d is unknown or a
+// plausible date of birth on the day of now.
+func checkDateOfBirth(d, now time.Time) error {
+	if d.IsZero() {
+		return nil
+	}
+
+	if err := birthdate.Check(d, now); err != nil {
+		return &serviceerrors.ProfileError{Violations: []serviceerrors.FieldViolation{
+			{Field: models.ProfileDateOfBirth, Message: err.Error()},
+		}}
+	}
+
+	return nil
+}
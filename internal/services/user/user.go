@@ -4,59 +4,124 @@ import (
 	"AuthService/internal/models"
 	"AuthService/internal/pb"
 	"AuthService/internal/services/auth"
+	"AuthService/internal/services/class"
+	"AuthService/internal/services/rbac"
 	serviceerrors "AuthService/internal/services/service_errors"
+	"AuthService/internal/storage/storage"
 	"AuthService/internal/utils"
 	"AuthService/pkg/tools/logger/sl"
 	"context"
+	"errors"
 	"log/slog"
+	"time"
 )
 
 type UserStore struct {
-	log              *slog.Logger
-	userFiller       UserFiller
-	userHelper       UserHelper
-	permissionGetter auth.PermissionGetter
+	log        *slog.Logger
+	userFiller UserFiller
+	userHelper UserHelper
+	classes    ClassProvider
+	access     PermissionChecker
+	activation []ActivationHook
 }
 
-func New(log *slog.Logger, userFiller UserFiller, userHelper UserHelper, permissionGetter auth.PermissionGetter) *UserStore {
+// New creates the user service. Accounts are only activated once none of
+// the activation hooks reports requirements for them.
+func New(log *slog.Logger, userFiller UserFiller, userHelper UserHelper, classes ClassProvider, access PermissionChecker, activation ...ActivationHook) *UserStore {
 	return &UserStore{
-		log:              log,
-		userFiller:       userFiller,
-		userHelper:       userHelper,
-		permissionGetter: permissionGetter,
+		log:        log,
+		userFiller: userFiller,
+		userHelper: userHelper,
+		classes:    classes,
+		access:     access,
+		activation: activation,
 	}
 }
 
+// ActivationHook returns what an account still lacks before it may be
+// activated on the day of now, such as the consent of a parent.
+type ActivationHook interface {
+	Requirements(user models.UserRecord, now time.Time) []string
+}
+
+// PermissionChecker extends the auth checks with RequireInScope, which
+// ignores global roles and only accepts roles scoped to classname.
+type PermissionChecker interface {
+	auth.PermissionChecker
+	RequireInScope(ctx context.Context, userID int64, permission, classname string) error
+}
+
 type UserFiller interface {
 	FillUserInfo(ctx context.Context, user models.UserInfo) error
 }
 
 type UserHelper interface {
+	GetUserInfo(ctx context.Context, userID int64) (models.UserInfo, error)
+	GetUserRecord(ctx context.Context, userID int64) (models.UserRecord, error)
+	UpdateUserInfo(ctx context.Context, user models.UserInfo, fields []string) error
+	SetProfileLocked(ctx context.Context, userID int64, locked bool) error
 	ChangeStatus(ctx context.Context, userID int64, isActive bool) error
 	IsActive(ctx context.Context, userID int64) (bool, error)
-	GetStudentsByClass(ctx context.Context, classname string) ([]*models.UserDTO, error)
+	GetStudentsByClass(ctx context.Context, classID int64) ([]*models.UserDTO, error)
 	DelUser(ctx context.Context, userID int64) error
+	ListUsers(ctx context.Context, filter models.UserFilter, page models.UserPage) ([]models.UserRecord, error)
+	CountUsers(ctx context.Context, filter models.UserFilter) (int64, error)
 }
 
-func (s *UserStore) FillUserProfile(ctx context.Context, name, lastname, middlename, dateOfBirth, classname string, userID int64) error {
+type ClassProvider interface {
+	GetClassByName(ctx context.Context, name string) (models.Class, error)
+}
+
+// FillUserProfile fills the profile of userID before the account is
+// activated; active accounts are changed with UpdateUserProfile. Users may
+// fill their own profile until it is locked; other profiles need
+// users.profile.edit. The class must be an open class with a free place.
+func (s *UserStore) FillUserProfile(ctx context.Context, initiatorID int64, name, lastname, middlename string, dateOfBirth time.Time, classname string, userID int64) error {
 	const op = "user.FillUserProfile"
 
 	log := s.log.With(
 		slog.String("Operation", op),
 		slog.Int64("UserID", userID),
+		slog.Int64("InitiatorID", initiatorID),
 	)
 
 	log.Info("filling user profile")
 
+	if err := s.checkProfileEdit(ctx, initiatorID, userID); err != nil {
+		log.Error("failed to fill profile", sl.Err(err))
+
+		return err
+	}
+
+	if err := checkDateOfBirth(dateOfBirth, time.Now()); err != nil {
+		log.Error("failed to fill profile", sl.Err(err))
+
+		return err
+	}
+
+	cls, err := s.getClass(ctx, classname)
+	if err != nil {
+		log.Error("failed to fill profile", sl.Err(err))
+
+		return err
+	}
+
 	user := models.UserInfo{
 		ID:          userID,
 		Name:        name,
 		Lastname:    lastname,
 		Middlename:  middlename,
 		DateOfBirth: dateOfBirth,
-		Classname:   classname,
+		Classname:   cls.Name,
+		ClassID:     cls.ID,
 	}
-	if err := s.userFiller.FillUserInfo(ctx, user); err != nil {
+	if err = s.userFiller.FillUserInfo(ctx, user); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserActive):
+			err = serviceerrors.ErrAccountActive
+		case errors.Is(err, storage.ErrClassFull):
+			err = serviceerrors.ErrClassFull
+		}
 		log.Error("failed to fill profile", sl.Err(err))
 
 		return err
@@ -67,6 +132,55 @@ func (s *UserStore) FillUserProfile(ctx context.Context, name, lastname, middlen
 	return nil
 }
 
+// LockUserProfile stops the owner of userID from editing their profile, or
+// lets them edit it again.
+func (s *UserStore) LockUserProfile(ctx context.Context, initiatorID, userID int64, locked bool) error {
+	const op = "user.LockUserProfile"
+
+	log := s.log.With(
+		slog.String("Operation", op),
+		slog.Int64("UserID", userID),
+		slog.Int64("InitiatorID", initiatorID),
+		slog.Bool("Locked", locked),
+	)
+
+	log.Info("locking user profile")
+
+	if err := s.access.Require(ctx, initiatorID, rbac.PermUserProfileEdit); err != nil {
+		log.Error("failed to lock profile", sl.Err(err))
+
+		return err
+	}
+
+	if err := s.userHelper.SetProfileLocked(ctx, userID, locked); err != nil {
+		log.Error("failed to lock profile", sl.Err(err))
+
+		return err
+	}
+
+	log.Info("user profile locked")
+
+	return nil
+}
+
+// checkProfileEdit returns ErrAccessDenied unless initiatorID may edit the
+// profile of userID.
+func (s *UserStore) checkProfileEdit(ctx context.Context, initiatorID, userID int64) error {
+	info, err := s.userHelper.GetUserInfo(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if initiatorID == userID && !info.ProfileLocked {
+		return nil
+	}
+
+	return s.access.RequireFor(ctx, initiatorID, userID, rbac.PermUserProfileEdit)
+}
+
+// ChangeUserStatus activates or deactivates userID. Accounts are not
+// activated while an activation hook reports requirements for them, and
+// accounts above the rank of the initiator are not changed at all.
 func (s *UserStore) ChangeUserStatus(ctx context.Context, userID int64, isActive bool, initiatorID int64) error {
 	const op = "user.ChangeUserStatus"
 
@@ -77,19 +191,35 @@ func (s *UserStore) ChangeUserStatus(ctx context.Context, userID int64, isActive
 
 	log.Info("changing user status")
 
-	lvl, err := s.permissionGetter.GetPermission(ctx, initiatorID)
-	if err != nil {
-		log.Error("failed to get user permissions", sl.Err(err))
+	if err := s.access.RequireFor(ctx, initiatorID, userID, rbac.PermUserStatusChange); err != nil {
+		log.Error("failed to change status", sl.Err(err))
 
 		return err
 	}
-	if lvl < 3 {
-		log.Error("failed to change status", sl.Err(serviceerrors.ErrAccessDenied))
 
-		return serviceerrors.ErrAccessDenied
+	if err := s.access.RequireOutrank(ctx, initiatorID, userID); err != nil {
+		log.Error("failed to change status", sl.Err(err))
+
+		return err
+	}
+
+	if isActive {
+		user, err := s.userHelper.GetUserRecord(ctx, userID)
+		if err != nil {
+			log.Error("failed to change status", sl.Err(err))
+
+			return err
+		}
+
+		if requirements := s.activationRequirements(user, time.Now()); len(requirements) > 0 {
+			err = &serviceerrors.ActivationError{Requirements: requirements}
+			log.Error("failed to change status", sl.Err(err))
+
+			return err
+		}
 	}
 
-	if err = s.userHelper.ChangeStatus(ctx, userID, isActive); err != nil {
+	if err := s.userHelper.ChangeStatus(ctx, userID, isActive); err != nil {
 		log.Error("failed to change status", sl.Err(err))
 
 		return err
@@ -122,40 +252,141 @@ func (s *UserStore) IsUserActive(ctx context.Context, userID int64) (bool, error
 	return active, nil
 }
 
-func (s *UserStore) GetStudentsByClassname(ctx context.Context, classname string) ([]*pb.Student, error) {
+// getClass returns the open class named classname.
+func (s *UserStore) getClass(ctx context.Context, classname string) (models.Class, error) {
+	cls, err := s.classes.GetClassByName(ctx, class.NormalizeName(classname))
+	if err != nil {
+		if errors.Is(err, storage.ErrClassNotFound) {
+			return models.Class{}, serviceerrors.ErrClassNotFound
+		}
+
+		return models.Class{}, err
+	}
+
+	return cls, nil
+}
+
+// GetStudentsByClassname lists the students of classname. Teachers may list
+// their own class; other classes need students.list.all.
+func (s *UserStore) GetStudentsByClassname(ctx context.Context, initiatorID int64, classname string) ([]*pb.Student, error) {
 	const op = "user.GetStudentsByClassname"
 
 	log := s.log.With(
 		slog.String("Operation", op),
 		slog.String("Classname", classname),
+		slog.Int64("InitiatorID", initiatorID),
 	)
 
 	log.Info("getting students by classname")
 
-	students, err := s.userHelper.GetStudentsByClass(ctx, classname)
+	// Callers who may not list any students learn nothing about the class.
+	if err := s.requireStudentsList(ctx, initiatorID, classname); err != nil {
+		log.Error("failed to get students", sl.Err(err))
+
+		return nil, err
+	}
+
+	cls, err := s.getClass(ctx, classname)
 	if err != nil {
 		log.Error("failed to get students", sl.Err(err))
 
 		return nil, err
 	}
-	if len(students) == 0 {
-		log.Error("failed to get students", sl.Err(serviceerrors.ErrClassNotFound))
 
-		return nil, serviceerrors.ErrClassNotFound
+	if err = s.checkClassAccess(ctx, initiatorID, cls); err != nil {
+		log.Error("failed to get students", sl.Err(err))
+
+		return nil, err
+	}
+
+	students, err := s.userHelper.GetStudentsByClass(ctx, cls.ID)
+	if err != nil {
+		log.Error("failed to get students", sl.Err(err))
+
+		return nil, err
 	}
+
 	return utils.ConvertUsers(students), nil
 }
 
-func (s *UserStore) DeleteUser(ctx context.Context, userID int64) error {
+// requireStudentsList returns ErrAccessDenied unless initiatorID may list
+// the students of some class: of every class, of classname through a scoped
+// role, or of the class they teach. It needs no lookup of classname, so it
+// runs before one.
+func (s *UserStore) requireStudentsList(ctx context.Context, initiatorID int64, classname string) error {
+	err := s.access.Require(ctx, initiatorID, rbac.PermStudentsListAll)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return err
+	}
+
+	err = s.access.RequireInScope(ctx, initiatorID, rbac.PermStudentsList, class.NormalizeName(classname))
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return err
+	}
+
+	return s.access.Require(ctx, initiatorID, rbac.PermStudentsList)
+}
+
+// checkClassAccess returns ErrAccessDenied unless initiatorID teaches cls
+// or may list the students of every class. A teacher teaches the class they
+// are the homeroom teacher of, the classes their scoped roles cover and,
+// failing that, the class of their own profile.
+func (s *UserStore) checkClassAccess(ctx context.Context, initiatorID int64, cls models.Class) error {
+	if cls.HomeroomTeacherID == initiatorID {
+		return nil
+	}
+
+	err := s.access.Require(ctx, initiatorID, rbac.PermStudentsListAll)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return err
+	}
+
+	err = s.access.RequireInScope(ctx, initiatorID, rbac.PermStudentsList, cls.Name)
+	if !errors.Is(err, serviceerrors.ErrAccessDenied) {
+		return err
+	}
+
+	if err = s.access.Require(ctx, initiatorID, rbac.PermStudentsList); err != nil {
+		return err
+	}
+
+	info, err := s.userHelper.GetUserInfo(ctx, initiatorID)
+	if err != nil {
+		return err
+	}
+
+	if info.ClassID != cls.ID {
+		return serviceerrors.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// DeleteUser deletes userID. The initiator needs users.delete and a rank
+// of at least the one of userID.
+func (s *UserStore) DeleteUser(ctx context.Context, initiatorID, userID int64) error {
 	const op = "user.DeleteUser"
 
 	log := s.log.With(
 		slog.String("Operation", op),
 		slog.Int64("UserID", userID),
+		slog.Int64("InitiatorID", initiatorID),
 	)
 
 	log.Info("deleting user")
 
+	if err := s.access.Require(ctx, initiatorID, rbac.PermUserDelete); err != nil {
+		log.Error("failed to delete user", sl.Err(err))
+
+		return err
+	}
+
+	if err := s.access.RequireOutrank(ctx, initiatorID, userID); err != nil {
+		log.Error("failed to delete user", sl.Err(err))
+
+		return err
+	}
+
 	err := s.userHelper.DelUser(ctx, userID)
 	if err != nil {
 		log.Error("failed to delete user", sl.Err(err))
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"AuthService/internal/models"
+	"context"
+	"sync"
+	"time"
+)
+
+type loginAttempts struct {
+	attempts  models.LoginAttempts
+	expiresAt time.Time
+}
+
+// LoginAttempts keeps failed login counters in process memory. It is enough
+// for a single replica; several replicas should share the MySQL store.
+type LoginAttempts struct {
+	mu      sync.Mutex
+	entries map[string]loginAttempts
+}
+
+func NewLoginAttempts() *LoginAttempts {
+	return &LoginAttempts{
+		entries: make(map[string]loginAttempts),
+	}
+}
+
+func (l *LoginAttempts) GetLoginAttempts(_ context.Context, key string) (models.LoginAttempts, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.entries[key].attempts, nil
+}
+
+func (l *LoginAttempts) FailLogin(_ context.Context, key string, at time.Time, window time.Duration) (models.LoginAttempts, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := l.entries[key]
+	if at.Sub(entry.attempts.LastFailure) >= window {
+		entry.attempts.Failures = 0
+	}
+
+	entry.attempts.Failures++
+	entry.attempts.LastFailure = at
+	entry.expiresAt = at.Add(window)
+	l.entries[key] = entry
+
+	return entry.attempts, nil
+}
+
+func (l *LoginAttempts) ResetLoginAttempts(_ context.Context, key string) error {
+	l.mu.Lock()
+	delete(l.entries, key)
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Prune drops the counters whose failures have been forgotten.
+func (l *LoginAttempts) Prune(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, entry := range l.entries {
+		if !entry.expiresAt.After(now) {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// Run prunes the counters every interval until ctx is cancelled.
+func (l *LoginAttempts) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.Prune(now)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"AuthService/internal/models"
+	"AuthService/pkg/tools/logger/sl"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type RevocationStorage interface {
+	RevokeToken(ctx context.Context, token models.RevokedToken) error
+	RevokeUserTokens(ctx context.Context, revocation models.UserRevocation) error
+	ListRevokedTokens(ctx context.Context) ([]models.RevokedToken, error)
+	ListUserRevocations(ctx context.Context) ([]models.UserRevocation, error)
+	PruneRevocations(ctx context.Context, now time.Time) error
+	TerminateSession(ctx context.Context, sessionID string, terminatedAt time.Time) error
+	ListTerminatedSessions(ctx context.Context, since time.Time) ([]models.Session, error)
+}
+
+// Revocations keeps every unexpired revocation in memory so that token
+// validation never has to hit the database. Writes go through to the
+// storage first; Run periodically prunes expired entries and reloads the
+// set, which also picks up revocations made by other replicas.
+//
+// A terminated session is remembered for tokenTTL, after which every access
+// token issued for it has expired.
+type Revocations struct {
+	log      *slog.Logger
+	storage  RevocationStorage
+	tokenTTL time.Duration
+
+	mu       sync.RWMutex
+	tokens   map[string]time.Time
+	users    map[int64]models.UserRevocation
+	sessions map[string]time.Time
+}
+
+func NewRevocations(log *slog.Logger, storage RevocationStorage, tokenTTL time.Duration) *Revocations {
+	return &Revocations{
+		log:      log,
+		storage:  storage,
+		tokenTTL: tokenTTL,
+		tokens:   make(map[string]time.Time),
+		users:    make(map[int64]models.UserRevocation),
+		sessions: make(map[string]time.Time),
+	}
+}
+
+func (r *Revocations) RevokeToken(ctx context.Context, token models.RevokedToken) error {
+	if err := r.storage.RevokeToken(ctx, token); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.tokens[token.TokenID] = token.ExpiresAt
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *Revocations) RevokeUserTokens(ctx context.Context, revocation models.UserRevocation) error {
+	if err := r.storage.RevokeUserTokens(ctx, revocation); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.users[revocation.UserID] = revocation
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *Revocations) TerminateSession(ctx context.Context, sessionID string) error {
+	now := time.Now()
+
+	if err := r.storage.TerminateSession(ctx, sessionID, now); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.sessions[sessionID] = now
+	r.mu.Unlock()
+
+	return nil
+}
+
+// IsRevoked reports whether a token with the given ID, session, owner and
+// issue time has been revoked on its own, with its session or by a
+// revocation of all user tokens.
+func (r *Revocations) IsRevoked(_ context.Context, tokenID, sessionID string, userID int64, issuedAt time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.tokens[tokenID]; ok && tokenID != "" {
+		return true, nil
+	}
+
+	if _, ok := r.sessions[sessionID]; ok && sessionID != "" {
+		return true, nil
+	}
+
+	if revocation, ok := r.users[userID]; ok && issuedAt.Before(revocation.RevokedBefore) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Load replaces the in-memory set with the current contents of the storage.
+func (r *Revocations) Load(ctx context.Context) error {
+	tokens, err := r.storage.ListRevokedTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load revoked tokens due to error: %w", err)
+	}
+
+	users, err := r.storage.ListUserRevocations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load user revocations due to error: %w", err)
+	}
+
+	sessions, err := r.storage.ListTerminatedSessions(ctx, time.Now().Add(-r.tokenTTL))
+	if err != nil {
+		return fmt.Errorf("failed to load terminated sessions due to error: %w", err)
+	}
+
+	tokenSet := make(map[string]time.Time, len(tokens))
+	for _, token := range tokens {
+		tokenSet[token.TokenID] = token.ExpiresAt
+	}
+
+	userSet := make(map[int64]models.UserRevocation, len(users))
+	for _, user := range users {
+		userSet[user.UserID] = user
+	}
+
+	sessionSet := make(map[string]time.Time, len(sessions))
+	for _, session := range sessions {
+		sessionSet[session.ID] = session.TerminatedAt
+	}
+
+	r.mu.Lock()
+	r.tokens = tokenSet
+	r.users = userSet
+	r.sessions = sessionSet
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Prune drops revocations whose tokens would have expired anyway.
+func (r *Revocations) Prune(ctx context.Context, now time.Time) error {
+	if err := r.storage.PruneRevocations(ctx, now); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	for id, expiresAt := range r.tokens {
+		if expiresAt.Before(now) {
+			delete(r.tokens, id)
+		}
+	}
+	for id, revocation := range r.users {
+		if revocation.ExpiresAt.Before(now) {
+			delete(r.users, id)
+		}
+	}
+	for id, terminatedAt := range r.sessions {
+		if terminatedAt.Add(r.tokenTTL).Before(now) {
+			delete(r.sessions, id)
+		}
+	}
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Run prunes and reloads the cache every interval until ctx is cancelled.
+func (r *Revocations) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.Prune(ctx, now); err != nil {
+				r.log.Error("failed to prune revocations", sl.Err(err))
+			}
+			if err := r.Load(ctx); err != nil {
+				r.log.Error("failed to reload revocations", sl.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *StDb) GetLoginAttempts(ctx context.Context, key string) (models.LoginAttempts, error) {
+	stmt, err := s.db.Prepare("SELECT failures, last_failure FROM login_attempts WHERE `key` = ?")
+	if err != nil {
+		return models.LoginAttempts{}, err
+	}
+
+	var attempts models.LoginAttempts
+	err = stmt.QueryRowContext(ctx, key).Scan(&attempts.Failures, &attempts.LastFailure)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LoginAttempts{}, nil
+		}
+
+		return models.LoginAttempts{}, err
+	}
+
+	return attempts, nil
+}
+
+// FailLogin increments the failure counter of key and returns it. The
+// increment is a single upsert, so concurrent replicas never lose an update;
+// the counter is read back in the same transaction. A counter whose last
+// failure is older than window starts over.
+func (s *StDb) FailLogin(ctx context.Context, key string, at time.Time, window time.Duration) (models.LoginAttempts, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.LoginAttempts{}, fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	// failures is assigned before last_failure, so the IF still sees the
+	// previous failure time.
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO login_attempts(`key`, failures, last_failure) VALUES(?, 1, ?) "+
+			"ON DUPLICATE KEY UPDATE `failures` = IF(`last_failure` <= ?, 1, `failures` + 1), `last_failure` = VALUES(`last_failure`)",
+		key, at.UTC(), at.Add(-window).UTC(),
+	)
+	if err != nil {
+		return models.LoginAttempts{}, fmt.Errorf("failed to count login failure due to error: %w", err)
+	}
+
+	var attempts models.LoginAttempts
+	err = tx.QueryRowContext(ctx, "SELECT failures, last_failure FROM login_attempts WHERE `key` = ?", key).
+		Scan(&attempts.Failures, &attempts.LastFailure)
+	if err != nil {
+		return models.LoginAttempts{}, err
+	}
+
+	return attempts, tx.Commit()
+}
+
+func (s *StDb) ResetLoginAttempts(ctx context.Context, key string) error {
+	stmt, err := s.db.Prepare("DELETE FROM login_attempts WHERE `key` = ?")
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, key)
+
+	return err
+}
+
+// PruneLoginAttempts drops the counters whose last failure is before before.
+func (s *StDb) PruneLoginAttempts(ctx context.Context, before time.Time) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE last_failure < ?", before.UTC()); err != nil {
+		return fmt.Errorf("failed to prune login attempts due to error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// selectClasses ends with the inSchool condition; queries add their own
+// conditions with AND.
+const selectClasses = "SELECT c.id, c.school_id, c.name, c.grade, c.letter, c.academic_year, c.homeroom_teacher_id, c.capacity, c.archived_at, " +
+	"(SELECT COUNT(*) FROM users u WHERE u.class_id = c.id AND u." + isStudent + ") FROM classes c WHERE " + inSchool
+
+// CreateClass creates a class in the school of ctx.
+func (s *StDb) CreateClass(ctx context.Context, class models.Class) (int64, error) {
+	if err := s.checkHomeroomTeacher(ctx, class.HomeroomTeacherID, tenant.SchoolOrDefault(ctx)); err != nil {
+		return 0, err
+	}
+
+	stmt, err := s.db.Prepare("INSERT INTO classes(school_id, name, grade, letter, academic_year, homeroom_teacher_id, capacity) VALUES(?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := stmt.ExecContext(ctx, tenant.SchoolOrDefault(ctx), class.Name, class.Grade, class.Letter, class.AcademicYear, nullID(class.HomeroomTeacherID), class.Capacity)
+	if err != nil {
+		return 0, classError(err, "create class")
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ID due to error: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateClass changes the homeroom teacher and the capacity of a class. The
+// class row is locked while its students are counted, so that no student
+// joins in between; a capacity below their number gives
+// storage.ErrClassFull.
+func (s *StDb) UpdateClass(ctx context.Context, class models.Class) error {
+	if err := s.checkHomeroomTeacher(ctx, class.HomeroomTeacherID, class.SchoolID); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	school := tenant.SchoolID(ctx)
+	err = tx.QueryRowContext(ctx, "SELECT id FROM classes WHERE id = ? AND "+inSchool+" FOR UPDATE", class.ID, school, school).Scan(&class.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrClassNotFound
+		}
+
+		return err
+	}
+
+	students, err := countStudents(ctx, tx, class.ID)
+	if err != nil {
+		return err
+	}
+
+	if class.Capacity < students {
+		return storage.ErrClassFull
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE classes SET homeroom_teacher_id = ?, capacity = ? WHERE id = ?",
+		nullID(class.HomeroomTeacherID), class.Capacity, class.ID)
+	if err != nil {
+		return classError(err, "update class")
+	}
+
+	return tx.Commit()
+}
+
+func (s *StDb) ArchiveClass(ctx context.Context, classID int64, at time.Time) error {
+	stmt, err := s.db.Prepare("UPDATE classes SET archived_at = COALESCE(archived_at, ?) WHERE id = ? AND " + inSchool)
+	if err != nil {
+		return err
+	}
+
+	school := tenant.SchoolID(ctx)
+	res, err := stmt.ExecContext(ctx, at.UTC(), classID, school, school)
+	if err != nil {
+		return fmt.Errorf("failed to archive class due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err = s.GetClass(ctx, classID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *StDb) GetClass(ctx context.Context, classID int64) (models.Class, error) {
+	return s.getClass(ctx, selectClasses+" AND c.id = ?", classID)
+}
+
+// GetClassByName returns the open class with the given name in the latest
+// school year that has one. A context reaching every school looks in the
+// default one, since class names are unique within a school only.
+func (s *StDb) GetClassByName(ctx context.Context, name string) (models.Class, error) {
+	if tenant.SchoolID(ctx) == 0 {
+		ctx = tenant.WithSchool(ctx, tenant.DefaultSchoolID)
+	}
+
+	return s.getClass(ctx, selectClasses+" AND c.name = ? AND c.archived_at IS NULL ORDER BY c.academic_year DESC LIMIT 1", name)
+}
+
+func (s *StDb) getClass(ctx context.Context, query string, args ...any) (models.Class, error) {
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return models.Class{}, err
+	}
+
+	school := tenant.SchoolID(ctx)
+	class, err := scanClass(stmt.QueryRowContext(ctx, append([]any{school, school}, args...)...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Class{}, storage.ErrClassNotFound
+		}
+
+		return models.Class{}, err
+	}
+
+	return class, nil
+}
+
+func (s *StDb) ListClasses(ctx context.Context, filter models.ClassFilter) ([]models.Class, error) {
+	school := tenant.SchoolID(ctx)
+
+	var (
+		where []string
+		args  = []any{school, school}
+	)
+	if filter.AcademicYear != 0 {
+		where = append(where, "c.academic_year = ?")
+		args = append(args, filter.AcademicYear)
+	}
+	if filter.Grade != 0 {
+		where = append(where, "c.grade = ?")
+		args = append(args, filter.Grade)
+	}
+	if !filter.IncludeArchived {
+		where = append(where, "c.archived_at IS NULL")
+	}
+
+	query := selectClasses
+	if len(where) > 0 {
+		query += " AND " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY c.academic_year DESC, c.grade, c.letter"
+
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list classes due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var classes []models.Class
+	for rows.Next() {
+		class, err := scanClass(rows)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+
+	return classes, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanClass(row scanner) (models.Class, error) {
+	var (
+		class      models.Class
+		homeroom   sql.NullInt64
+		archivedAt sql.NullTime
+	)
+	err := row.Scan(&class.ID, &class.SchoolID, &class.Name, &class.Grade, &class.Letter, &class.AcademicYear,
+		&homeroom, &class.Capacity, &archivedAt, &class.Students)
+	if err != nil {
+		return models.Class{}, err
+	}
+
+	class.HomeroomTeacherID = homeroom.Int64
+	class.ArchivedAt = archivedAt.Time
+
+	return class, nil
+}
+
+// checkHomeroomTeacher makes sure that a homeroom teacher belongs to
+// schoolID, the school of the class. Contexts reaching every school would
+// let a teacher of any school through an inSchool check.
+func (s *StDb) checkHomeroomTeacher(ctx context.Context, teacherID, schoolID int64) error {
+	if teacherID == 0 {
+		return nil
+	}
+
+	var count int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE id = ? AND school_id = ?", teacherID, schoolID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// classError converts the key errors of a class write into storage errors.
+func classError(err error, action string) error {
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		switch mysqlErr.Number {
+		case 1062:
+			return fmt.Errorf("failed to %s: %w", action, storage.ErrClassExists)
+		case 1452:
+			return fmt.Errorf("failed to %s: %w", action, storage.ErrUserNotFound)
+		}
+	}
+
+	return fmt.Errorf("failed to %s due to error: %w", action, err)
+}
+
+// nullID stores a zero ID as NULL.
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
@@ -0,0 +1,41 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ExportUsers calls fn with every user of the school of ctx that matches
+// filter, ordered by class and name. Rows are read as they are needed, so
+// exports of any size take little memory; an error of fn stops the export
+// and is returned.
+func (s *StDb) ExportUsers(ctx context.Context, filter models.UserFilter, fn func(models.UserRecord) error) error {
+	where, args := userFilterWhere(ctx, filter)
+
+	query := selectUserRecords
+	if len(where) > 0 {
+		query += " AND " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY u.classname, u.lastname, u.name, u.id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export users due to error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUserRecord(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan user due to error: %w", err)
+		}
+
+		if err = fn(user); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
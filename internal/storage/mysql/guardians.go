@@ -0,0 +1,245 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *StDb) SaveGuardianInvite(ctx context.Context, studentID, createdBy int64, codeHash string, expiresAt time.Time) error {
+	stmt, err := s.db.Prepare("INSERT INTO guardian_invites(code_hash, student_id, created_by, expires_at) VALUES(?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+
+	if _, err = stmt.ExecContext(ctx, codeHash, studentID, createdBy, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to save guardian invite due to error: %w", err)
+	}
+
+	return nil
+}
+
+// LinkGuardianByInvite consumes an unused, unexpired invite and links the
+// guardian to the student it was issued for. It returns the student ID.
+// Invites for students of another school are not found; a student redeeming
+// their own invite gets storage.ErrSelfGuardian and the invite stays unused.
+func (s *StDb) LinkGuardianByInvite(ctx context.Context, codeHash string, link models.GuardianLink, now time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	school := tenant.SchoolID(ctx)
+	err = tx.QueryRowContext(ctx,
+		"SELECT i.student_id FROM guardian_invites i JOIN users u ON u.id = i.student_id "+
+			"WHERE i.code_hash = ? AND i.used_at IS NULL AND i.expires_at > ? AND (? = 0 OR u.school_id = ?) FOR UPDATE",
+		codeHash, now.UTC(), school, school,
+	).Scan(&link.StudentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrTokenNotFound
+		}
+
+		return 0, err
+	}
+
+	if link.StudentID == link.GuardianID {
+		return 0, storage.ErrSelfGuardian
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE guardian_invites SET used_at = ? WHERE code_hash = ?", now.UTC(), codeHash); err != nil {
+		return 0, fmt.Errorf("failed to consume guardian invite due to error: %w", err)
+	}
+
+	if err = linkGuardian(ctx, tx, link); err != nil {
+		return 0, err
+	}
+
+	return link.StudentID, tx.Commit()
+}
+
+// LinkGuardian links a guardian to a student, or updates the relationship
+// of an existing link.
+func (s *StDb) LinkGuardian(ctx context.Context, link models.GuardianLink) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var count int
+	school := tenant.SchoolID(ctx)
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE id IN (?, ?) AND "+inSchool, link.GuardianID, link.StudentID, school, school).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count < 2 {
+		return storage.ErrUserNotFound
+	}
+
+	if err = linkGuardian(ctx, tx, link); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// linkGuardian writes the link, keeps a single primary contact per student
+// and gives the guardian the parent role. An approval of the link is kept
+// when it is written again without one.
+func linkGuardian(ctx context.Context, tx *sql.Tx, link models.GuardianLink) error {
+	if link.Primary {
+		if _, err := tx.ExecContext(ctx, "UPDATE guardians SET is_primary = 0 WHERE student_id = ?", link.StudentID); err != nil {
+			return fmt.Errorf("failed to reset primary guardian due to error: %w", err)
+		}
+	}
+
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO guardians(guardian_id, student_id, relationship, is_primary, approved_by, approved_at) VALUES(?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE relationship = VALUES(relationship), is_primary = VALUES(is_primary), "+
+			"approved_by = COALESCE(VALUES(approved_by), approved_by), approved_at = COALESCE(VALUES(approved_at), approved_at)",
+		link.GuardianID, link.StudentID, link.Relationship, link.Primary, nullID(link.ApprovedBy), nullDate(link.ApprovedAt.UTC()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link guardian due to error: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, "INSERT IGNORE INTO user_roles(user_id, role) VALUES(?, 'parent')", link.GuardianID); err != nil {
+		return fmt.Errorf("failed to assign parent role due to error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StDb) UnlinkGuardian(ctx context.Context, guardianID, studentID int64) error {
+	stmt, err := s.db.Prepare("DELETE g FROM guardians g JOIN users u ON u.id = g.student_id " +
+		"WHERE g.guardian_id = ? AND g.student_id = ? AND (? = 0 OR u.school_id = ?)")
+	if err != nil {
+		return err
+	}
+
+	school := tenant.SchoolID(ctx)
+	res, err := stmt.ExecContext(ctx, guardianID, studentID, school, school)
+	if err != nil {
+		return fmt.Errorf("failed to unlink guardian due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrGuardianNotFound
+	}
+
+	return nil
+}
+
+func (s *StDb) IsGuardian(ctx context.Context, guardianID, studentID int64) (bool, error) {
+	stmt, err := s.db.Prepare("SELECT COUNT(*) FROM guardians g JOIN users u ON u.id = g.student_id " +
+		"WHERE g.guardian_id = ? AND g.student_id = ? AND (? = 0 OR u.school_id = ?)")
+	if err != nil {
+		return false, err
+	}
+
+	school := tenant.SchoolID(ctx)
+	var n int
+	if err = stmt.QueryRowContext(ctx, guardianID, studentID, school, school).Scan(&n); err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// SetParentalConsent records that a parent consented to the account of
+// studentID, as recordedBy entered it at, or clears the consent. It
+// returns storage.ErrUserNotFound for students outside the school of ctx.
+func (s *StDb) SetParentalConsent(ctx context.Context, studentID, recordedBy int64, consent bool, at time.Time) error {
+	var (
+		consentAt sql.NullTime
+		consentBy sql.NullInt64
+	)
+	if consent {
+		consentAt = sql.NullTime{Time: at.UTC(), Valid: true}
+		consentBy = nullID(recordedBy)
+	}
+
+	school := tenant.SchoolID(ctx)
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET parental_consent_at = ?, parental_consent_by = ? WHERE id = ? AND "+inSchool,
+		consentAt, consentBy, studentID, school, school)
+	if err != nil {
+		return fmt.Errorf("failed to set parental consent due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		// MySQL reports no affected rows when the value does not change.
+		count := 0
+		if err = userExists(ctx, s.db, studentID, &count); err != nil {
+			return fmt.Errorf("failed to set parental consent due to error: %w", err)
+		}
+		if count == 0 {
+			return storage.ErrUserNotFound
+		}
+	}
+
+	return nil
+}
+
+// ListGuardians returns the guardians of a student, primary contact first.
+func (s *StDb) ListGuardians(ctx context.Context, studentID int64) ([]models.GuardianLink, error) {
+	return s.listGuardianLinks(ctx,
+		"SELECT g.guardian_id, g.student_id, g.relationship, g.is_primary, g.created_at, g.approved_by, g.approved_at, u.id, u.name, u.lastname, u.classname "+
+			"FROM guardians g JOIN users u ON u.id = g.guardian_id WHERE g.student_id = ? AND (? = 0 OR u.school_id = ?) "+
+			"ORDER BY g.is_primary DESC, g.created_at",
+		studentID,
+	)
+}
+
+// ListChildren returns the students a guardian is linked to.
+func (s *StDb) ListChildren(ctx context.Context, guardianID int64) ([]models.GuardianLink, error) {
+	return s.listGuardianLinks(ctx,
+		"SELECT g.guardian_id, g.student_id, g.relationship, g.is_primary, g.created_at, g.approved_by, g.approved_at, u.id, u.name, u.lastname, u.classname "+
+			"FROM guardians g JOIN users u ON u.id = g.student_id WHERE g.guardian_id = ? AND (? = 0 OR u.school_id = ?) "+
+			"ORDER BY u.lastname, u.name",
+		guardianID,
+	)
+}
+
+func (s *StDb) listGuardianLinks(ctx context.Context, query string, args ...any) ([]models.GuardianLink, error) {
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	school := tenant.SchoolID(ctx)
+	rows, err := stmt.QueryContext(ctx, append(args, school, school)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guardians due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var links []models.GuardianLink
+	for rows.Next() {
+		var (
+			link                      models.GuardianLink
+			approvedBy                sql.NullInt64
+			approvedAt                sql.NullTime
+			name, lastname, classname sql.NullString
+		)
+		err = rows.Scan(&link.GuardianID, &link.StudentID, &link.Relationship, &link.Primary, &link.CreatedAt,
+			&approvedBy, &approvedAt, &link.Other.ID, &name, &lastname, &classname)
+		if err != nil {
+			return nil, err
+		}
+		link.ApprovedBy = approvedBy.Int64
+		link.ApprovedAt = approvedAt.Time
+		link.Other.Name = name.String
+		link.Other.Lastname = lastname.String
+		link.Other.Classname = classname.String
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ExistingEmails returns which of emails are taken in the school of ctx,
+// keyed by the lower-cased email.
+func (s *StDb) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	args := make([]any, 0, len(emails)+1)
+	args = append(args, tenant.SchoolOrDefault(ctx))
+	for _, email := range emails {
+		args = append(args, email)
+	}
+
+	query := "SELECT email FROM users WHERE school_id = ? AND email IN (?" + strings.Repeat(", ?", len(emails)-1) + ")"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up emails due to error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email string
+		if err = rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		existing[strings.ToLower(email)] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// ImportUsers creates the accounts of a bulk import in the school of ctx in
+// one transaction: if one fails, none is created. Imported accounts are
+// active and verified, since the school vouches for them. Every user with a
+// class takes a seat in it, and storage.ErrClassFull is returned when a
+// class filled up since the rows were checked. It returns the IDs of the
+// users in order.
+func (s *StDb) ImportUsers(ctx context.Context, users []models.ImportedUser) ([]int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	school := tenant.SchoolOrDefault(ctx)
+	now := time.Now()
+
+	ids := make([]int64, 0, len(users))
+	for _, u := range users {
+		p := u.Profile
+
+		if p.ClassID != 0 {
+			if err = reserveSeat(ctx, tx, p.ClassID); err != nil {
+				return nil, fmt.Errorf("failed to import %s: %w", u.Email, err)
+			}
+		}
+
+		res, err := tx.ExecContext(ctx,
+			"INSERT INTO users(email, pass_hash, school_id, name, lastname, middlename, date_of_birth, classname, class_id, is_active, email_verified) "+
+				"VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)",
+			u.Email, u.PassHash, school, p.Name, p.Lastname, p.Middlename, nullDate(p.DateOfBirth), nullString(p.Classname), nullID(p.ClassID), u.Active,
+		)
+		if err != nil {
+			if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+				return nil, fmt.Errorf("failed to import %s: %w", u.Email, storage.ErrUserExists)
+			}
+
+			return nil, fmt.Errorf("failed to import user due to error: %w", err)
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ID due to error: %w", err)
+		}
+
+		if _, err = tx.ExecContext(ctx, "INSERT INTO user_roles(user_id, role) VALUES(?, ?)", id, u.Role); err != nil {
+			return nil, fmt.Errorf("failed to assign role due to error: %w", err)
+		}
+
+		if _, err = tx.ExecContext(ctx, syncPermissionLevel, id, id); err != nil {
+			return nil, fmt.Errorf("failed to sync permission level due to error: %w", err)
+		}
+
+		if p.ClassID != 0 {
+			if err = changeClass(ctx, tx, id, p.ClassID, now); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO password_resets(token_hash, user_id, expires_at) VALUES(?, ?, ?)",
+			u.InviteHash, id, u.InviteExpiresAt.UTC(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save invite due to error: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, tx.Commit()
+}
+
+// nullString stores an empty string as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
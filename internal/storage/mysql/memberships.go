@@ -0,0 +1,253 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// changeClass ends the current class membership of userID and starts one in
+// classID, or none when classID is zero.
+func changeClass(ctx context.Context, tx *sql.Tx, userID, classID int64, at time.Time) error {
+	if _, err := tx.ExecContext(ctx, "UPDATE class_memberships SET left_at = ? WHERE user_id = ? AND left_at IS NULL", at.UTC(), userID); err != nil {
+		return fmt.Errorf("failed to close class membership due to error: %w", err)
+	}
+
+	if classID == 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO class_memberships(user_id, class_id, joined_at) VALUES(?, ?, ?)", userID, classID, at.UTC()); err != nil {
+		return fmt.Errorf("failed to open class membership due to error: %w", err)
+	}
+
+	return nil
+}
+
+// isStudent is the condition that the user of a users row holds the student
+// role. Only students take seats, are listed as members and are promoted;
+// staff with a class in their profile are not.
+const isStudent = "id IN (SELECT user_id FROM user_roles WHERE role = 'student')"
+
+// reserveSeat locks classID until tx ends and returns storage.ErrClassFull
+// when the class has no free seat. Holding the lock, concurrent enrolments
+// cannot both take the last seat.
+func reserveSeat(ctx context.Context, tx *sql.Tx, classID int64) error {
+	return reserveSeats(ctx, tx, classID, 1)
+}
+
+// reserveSeats is reserveSeat for seats students at once.
+func reserveSeats(ctx context.Context, tx *sql.Tx, classID, seats int64) error {
+	var capacity int64
+	err := tx.QueryRowContext(ctx, "SELECT capacity FROM classes WHERE id = ? FOR UPDATE", classID).Scan(&capacity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrClassNotFound
+		}
+
+		return err
+	}
+
+	students, err := countStudents(ctx, tx, classID)
+	if err != nil {
+		return err
+	}
+
+	if students+seats > capacity {
+		return storage.ErrClassFull
+	}
+
+	return nil
+}
+
+// countStudents counts the students of classID within tx.
+func countStudents(ctx context.Context, tx *sql.Tx, classID int64) (int64, error) {
+	var students int64
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE class_id = ? AND "+isStudent, classID).Scan(&students)
+
+	return students, err
+}
+
+// TransferStudent moves a student into class, recording the change in the
+// membership history. It returns storage.ErrClassFull when class has no
+// free seat.
+func (s *StDb) TransferStudent(ctx context.Context, userID int64, class models.Class, at time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var classID sql.NullInt64
+	school := tenant.SchoolID(ctx)
+	err = tx.QueryRowContext(ctx, "SELECT class_id FROM users WHERE id = ? AND "+inSchool+" FOR UPDATE", userID, school, school).Scan(&classID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrUserNotFound
+		}
+
+		return err
+	}
+
+	if classID.Int64 == class.ID {
+		return tx.Commit()
+	}
+
+	if err = reserveSeat(ctx, tx, class.ID); err != nil {
+		return err
+	}
+
+	if err = changeClass(ctx, tx, userID, class.ID, at); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE users SET class_id = ?, classname = ? WHERE id = ?", class.ID, class.Name, userID); err != nil {
+		return fmt.Errorf("failed to transfer student due to error: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListClassMembers returns the ID and the names of the students in a class.
+func (s *StDb) ListClassMembers(ctx context.Context, classID int64) ([]models.UserInfo, error) {
+	stmt, err := s.db.Prepare("SELECT id, name, lastname FROM users WHERE class_id = ? AND " + isStudent + " AND " + inSchool + " ORDER BY lastname, name, id")
+	if err != nil {
+		return nil, err
+	}
+
+	school := tenant.SchoolID(ctx)
+	rows, err := stmt.QueryContext(ctx, classID, school, school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list class members due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var students []models.UserInfo
+	for rows.Next() {
+		var (
+			student        models.UserInfo
+			name, lastname sql.NullString
+		)
+		if err = rows.Scan(&student.ID, &name, &lastname); err != nil {
+			return nil, err
+		}
+		student.Name = name.String
+		student.Lastname = lastname.String
+		student.ClassID = classID
+		students = append(students, student)
+	}
+
+	return students, rows.Err()
+}
+
+// GetClassHistory returns the class memberships of a student, oldest first.
+func (s *StDb) GetClassHistory(ctx context.Context, userID int64) ([]models.ClassMembership, error) {
+	stmt, err := s.db.Prepare("SELECT m.user_id, m.class_id, c.name, c.academic_year, m.joined_at, m.left_at " +
+		"FROM class_memberships m JOIN classes c ON c.id = m.class_id " +
+		"WHERE m.user_id = ? AND (? = 0 OR c.school_id = ?) ORDER BY m.joined_at, m.id")
+	if err != nil {
+		return nil, err
+	}
+
+	school := tenant.SchoolID(ctx)
+	rows, err := stmt.QueryContext(ctx, userID, school, school)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class history due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var history []models.ClassMembership
+	for rows.Next() {
+		var (
+			m      models.ClassMembership
+			leftAt sql.NullTime
+		)
+		if err = rows.Scan(&m.UserID, &m.ClassID, &m.ClassName, &m.AcademicYear, &m.JoinedAt, &leftAt); err != nil {
+			return nil, err
+		}
+		m.LeftAt = leftAt.Time
+		history = append(history, m)
+	}
+
+	return history, rows.Err()
+}
+
+// PromoteClasses applies a year-end promotion in one transaction: students
+// move to their next class or leave school, and the old classes are
+// archived. Graduates are also deactivated when deactivate is set. Users
+// without the student role keep their class. A next class without seats
+// for every student moving in gives storage.ErrClassFull.
+func (s *StDb) PromoteClasses(ctx context.Context, promotions []models.ClassPromotion, deactivate bool, at time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	at = at.UTC()
+
+	for _, p := range promotions {
+		if _, err = tx.ExecContext(ctx, "UPDATE class_memberships SET left_at = ? WHERE class_id = ? AND left_at IS NULL "+
+			"AND user_id IN (SELECT user_id FROM user_roles WHERE role = 'student')", at, p.From.ID); err != nil {
+			return fmt.Errorf("failed to close class memberships due to error: %w", err)
+		}
+
+		if p.Graduate {
+			query := "UPDATE users SET class_id = NULL, classname = NULL WHERE class_id = ? AND " + isStudent
+			if deactivate {
+				query = "UPDATE users SET class_id = NULL, classname = NULL, is_active = 0 WHERE class_id = ? AND " + isStudent
+			}
+			if _, err = tx.ExecContext(ctx, query, p.From.ID); err != nil {
+				return fmt.Errorf("failed to graduate students due to error: %w", err)
+			}
+		} else {
+			toID := p.To.ID
+			if toID == 0 {
+				res, err := tx.ExecContext(ctx,
+					"INSERT INTO classes(school_id, name, grade, letter, academic_year, homeroom_teacher_id, capacity) VALUES(?, ?, ?, ?, ?, ?, ?) "+
+						"ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)",
+					p.From.SchoolID, p.To.Name, p.To.Grade, p.To.Letter, p.To.AcademicYear, nullID(p.To.HomeroomTeacherID), p.To.Capacity)
+				if err != nil {
+					return classError(err, "create class")
+				}
+				if toID, err = res.LastInsertId(); err != nil {
+					return fmt.Errorf("failed to get ID due to error: %w", err)
+				}
+			}
+
+			// The next class may exist already and have students.
+			moving, err := countStudents(ctx, tx, p.From.ID)
+			if err != nil {
+				return err
+			}
+			if err = reserveSeats(ctx, tx, toID, moving); err != nil {
+				if errors.Is(err, storage.ErrClassFull) {
+					return fmt.Errorf("%w: %s", err, p.To.Name)
+				}
+
+				return err
+			}
+
+			if _, err = tx.ExecContext(ctx,
+				"INSERT INTO class_memberships(user_id, class_id, joined_at) SELECT id, ?, ? FROM users WHERE class_id = ? AND "+isStudent,
+				toID, at, p.From.ID); err != nil {
+				return fmt.Errorf("failed to open class memberships due to error: %w", err)
+			}
+
+			if _, err = tx.ExecContext(ctx, "UPDATE users SET class_id = ?, classname = ? WHERE class_id = ? AND "+isStudent, toID, p.To.Name, p.From.ID); err != nil {
+				return fmt.Errorf("failed to promote students due to error: %w", err)
+			}
+		}
+
+		if _, err = tx.ExecContext(ctx, "UPDATE classes SET archived_at = COALESCE(archived_at, ?) WHERE id = ?", at, p.From.ID); err != nil {
+			return fmt.Errorf("failed to archive class due to error: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,236 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SaveTOTP stores a new, unconfirmed secret for the user, replacing a
+// previous enrollment that was never confirmed.
+func (s *StDb) SaveTOTP(ctx context.Context, userID int64, secret string) error {
+	stmt, err := s.db.Prepare("INSERT INTO user_totp(user_id, secret) VALUES(?, ?) ON DUPLICATE KEY UPDATE `secret` = VALUES(`secret`), `confirmed_at` = NULL, `last_step` = 0")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement due to error: %w", err)
+	}
+
+	if _, err = stmt.ExecContext(ctx, userID, secret); err != nil {
+		return fmt.Errorf("failed to save totp due to error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StDb) GetTOTP(ctx context.Context, userID int64) (models.TOTP, error) {
+	stmt, err := s.db.Prepare("SELECT user_id, secret, confirmed_at, last_step FROM user_totp WHERE user_id = ?")
+	if err != nil {
+		return models.TOTP{}, err
+	}
+
+	var (
+		totp        models.TOTP
+		confirmedAt sql.NullTime
+	)
+	err = stmt.QueryRowContext(ctx, userID).Scan(&totp.UserID, &totp.Secret, &confirmedAt, &totp.LastStep)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TOTP{}, storage.ErrTOTPNotFound
+		}
+
+		return models.TOTP{}, err
+	}
+	totp.ConfirmedAt = confirmedAt.Time
+
+	return totp, nil
+}
+
+// ConfirmTOTP enables the enrolled secret and replaces the recovery codes of
+// the user.
+func (s *StDb) ConfirmTOTP(ctx context.Context, userID, step int64, codeHashes []string, at time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE user_totp SET `confirmed_at` = ?, `last_step` = ? WHERE user_id = ? AND confirmed_at IS NULL",
+		at.UTC(), step, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to confirm totp due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrTOTPNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to drop recovery codes due to error: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		if _, err = tx.ExecContext(ctx, "INSERT INTO recovery_codes(user_id, code_hash) VALUES(?, ?)", userID, hash); err != nil {
+			return fmt.Errorf("failed to save recovery code due to error: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep records step as the last accepted one. It fails with
+// ErrCodeAlreadyUsed when a code of the same or a later step was accepted
+// before.
+func (s *StDb) UseTOTPStep(ctx context.Context, userID, step int64) error {
+	stmt, err := s.db.Prepare("UPDATE user_totp SET `last_step` = ? WHERE user_id = ? AND last_step < ?")
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, step, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to use totp step due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrCodeAlreadyUsed
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of the user as used.
+func (s *StDb) UseRecoveryCode(ctx context.Context, userID int64, codeHash string, at time.Time) error {
+	stmt, err := s.db.Prepare("UPDATE recovery_codes SET `used_at` = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL")
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, at.UTC(), userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrCodeAlreadyUsed
+	}
+
+	return nil
+}
+
+func (s *StDb) CreateMFAChallenge(ctx context.Context, idHash string, challenge models.MFAChallenge) error {
+	stmt, err := s.db.Prepare("INSERT INTO mfa_challenges(id_hash, user_id, device_label, user_agent, ip, expires_at) VALUES(?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement due to error: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		idHash, challenge.UserID,
+		challenge.Device.Label, challenge.Device.UserAgent, challenge.Device.IP,
+		challenge.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create mfa challenge due to error: %w", err)
+	}
+
+	return nil
+}
+
+// GetMFAChallenge returns an unexpired challenge.
+func (s *StDb) GetMFAChallenge(ctx context.Context, idHash string, now time.Time) (models.MFAChallenge, error) {
+	stmt, err := s.db.Prepare("SELECT user_id, device_label, user_agent, ip, attempts, expires_at FROM mfa_challenges WHERE id_hash = ? AND expires_at > ?")
+	if err != nil {
+		return models.MFAChallenge{}, err
+	}
+
+	var c models.MFAChallenge
+	err = stmt.QueryRowContext(ctx, idHash, now.UTC()).Scan(
+		&c.UserID, &c.Device.Label, &c.Device.UserAgent, &c.Device.IP, &c.Attempts, &c.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.MFAChallenge{}, storage.ErrTokenNotFound
+		}
+
+		return models.MFAChallenge{}, err
+	}
+
+	return c, nil
+}
+
+// UseMFAAttempt counts an attempt at a challenge in a single statement. It
+// fails with ErrTokenNotFound once limit attempts were made or the challenge
+// is gone.
+func (s *StDb) UseMFAAttempt(ctx context.Context, idHash string, limit int) error {
+	stmt, err := s.db.Prepare("UPDATE mfa_challenges SET `attempts` = `attempts` + 1 WHERE id_hash = ? AND attempts < ?")
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, idHash, limit)
+	if err != nil {
+		return fmt.Errorf("failed to count mfa attempt due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteMFAChallenge removes a challenge. It fails with ErrTokenNotFound when
+// the challenge was already deleted, so only one caller can complete it.
+func (s *StDb) DeleteMFAChallenge(ctx context.Context, idHash string) error {
+	stmt, err := s.db.Prepare("DELETE FROM mfa_challenges WHERE id_hash = ?")
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, idHash)
+	if err != nil {
+		return fmt.Errorf("failed to delete mfa challenge due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+func (s *StDb) IsMFARequired(ctx context.Context, permissionLevel int64) (bool, error) {
+	stmt, err := s.db.Prepare("SELECT COUNT(*) FROM mfa_required_levels WHERE permission_level = ?")
+	if err != nil {
+		return false, err
+	}
+
+	var n int
+	if err = stmt.QueryRowContext(ctx, permissionLevel).Scan(&n); err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (s *StDb) SetMFARequired(ctx context.Context, permissionLevel int64, required bool) error {
+	query := "DELETE FROM mfa_required_levels WHERE permission_level = ?"
+	if required {
+		query = "INSERT IGNORE INTO mfa_required_levels(permission_level) VALUES(?)"
+	}
+
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement due to error: %w", err)
+	}
+
+	if _, err = stmt.ExecContext(ctx, permissionLevel); err != nil {
+		return fmt.Errorf("failed to set mfa requirement due to error: %w", err)
+	}
+
+	return nil
+}
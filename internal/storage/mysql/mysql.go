@@ -3,10 +3,12 @@ package mysql
 import (
 	"AuthService/internal/models"
 	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 )
@@ -16,7 +18,16 @@ type StDb struct {
 }
 
 func New(path string) (*StDb, error) {
-	db, err := sql.Open("mysql", path)
+	cfg, err := mysql.ParseDSN(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dsn due to error: %w", err)
+	}
+
+	// Token tables keep their timestamps as DATETIME in UTC.
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db due to error: %w", err)
 	}
@@ -24,18 +35,24 @@ func New(path string) (*StDb, error) {
 	return &StDb{db: db}, nil
 }
 
+// CreateUser creates an account with the student role, which stands for the
+// default permission level, in the school of ctx.
 func (s *StDb) CreateUser(ctx context.Context, email string, hash []byte) (int64, error) {
-	stmt, err := s.db.Prepare("INSERT INTO users(email, pass_hash) VALUES(?, ?)")
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, fmt.Errorf("failed to prepare statement due to error: %w", err)
+		return 0, fmt.Errorf("failed to begin transaction due to error: %w", err)
 	}
+	defer tx.Rollback()
 
-	res, err := stmt.ExecContext(ctx, email, hash)
+	res, err := tx.ExecContext(ctx, "INSERT INTO users(email, pass_hash, school_id) VALUES(?, ?, ?)", email, hash, tenant.SchoolOrDefault(ctx))
 	if err != nil {
 		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
 			if mysqlErr.Number == 1062 {
 				return 0, fmt.Errorf("failed to create user: %w", storage.ErrUserExists)
 			}
+			if mysqlErr.Number == 1452 {
+				return 0, fmt.Errorf("failed to create user: %w", storage.ErrSchoolNotFound)
+			}
 		}
 
 		return 0, fmt.Errorf("failed to create user due to error: %w", err)
@@ -46,19 +63,47 @@ func (s *StDb) CreateUser(ctx context.Context, email string, hash []byte) (int64
 		return 0, fmt.Errorf("failed to get ID due to error: %w", err)
 	}
 
-	return id, nil
+	if _, err = tx.ExecContext(ctx, "INSERT INTO user_roles(user_id, role) SELECT ?, name FROM roles WHERE legacy_level = 1", id); err != nil {
+		return 0, fmt.Errorf("failed to assign default role due to error: %w", err)
+	}
+
+	return id, tx.Commit()
 }
 
+// GetUser finds a user by email. Emails are unique within a school only, so
+// a context that reaches every school looks in the default one.
 func (s *StDb) GetUser(ctx context.Context, email string) (models.User, error) {
-	stmt, err := s.db.Prepare("SELECT id, email, pass_hash, permission_level FROM users WHERE email = ?")
+	stmt, err := s.db.Prepare("SELECT id, email, pass_hash, permission_level, email_verified, school_id FROM users WHERE email = ? AND school_id = ?")
 	if err != nil {
 		return models.User{}, err
 	}
 
-	row := stmt.QueryRowContext(ctx, email)
+	row := stmt.QueryRowContext(ctx, email, tenant.SchoolOrDefault(ctx))
 
 	var user models.User
-	err = row.Scan(&user.ID, &user.Email, &user.PassHash, &user.PermissionLevel)
+	err = row.Scan(&user.ID, &user.Email, &user.PassHash, &user.PermissionLevel, &user.EmailVerified, &user.SchoolID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, storage.ErrUserNotFound
+		}
+
+		return models.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *StDb) GetUserByID(ctx context.Context, userID int64) (models.User, error) {
+	stmt, err := s.db.Prepare("SELECT id, email, pass_hash, permission_level, email_verified, school_id FROM users WHERE id = ? AND " + inSchool)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	school := tenant.SchoolID(ctx)
+	row := stmt.QueryRowContext(ctx, userID, school, school)
+
+	var user models.User
+	err = row.Scan(&user.ID, &user.Email, &user.PassHash, &user.PermissionLevel, &user.EmailVerified, &user.SchoolID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.User{}, storage.ErrUserNotFound
@@ -71,12 +116,13 @@ func (s *StDb) GetUser(ctx context.Context, email string) (models.User, error) {
 }
 
 func (s *StDb) UpdatePassword(ctx context.Context, userID int64, passHash []byte) error {
-	stmt, err := s.db.Prepare("UPDATE users SET `pass_hash` = ? WHERE id = ?")
+	stmt, err := s.db.Prepare("UPDATE users SET `pass_hash` = ? WHERE id = ? AND " + inSchool)
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.ExecContext(ctx, passHash, userID)
+	school := tenant.SchoolID(ctx)
+	_, err = stmt.ExecContext(ctx, passHash, userID, school, school)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return storage.ErrUserNotFound
@@ -87,33 +133,56 @@ func (s *StDb) UpdatePassword(ctx context.Context, userID int64, passHash []byte
 	return nil
 }
 
+// SetPermission sets the legacy permission level of a user by replacing their
+// roles with the role that stands for the level.
 func (s *StDb) SetPermission(ctx context.Context, userID int64, permissionLevel int64) error {
-	count := 0
-	stmt, err := s.db.Prepare("UPDATE users SET `permission_level` = ? WHERE id = ?")
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
 	}
-	q := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", userID)
-	q.Scan(&count)
+	defer tx.Rollback()
 
-	_, err = stmt.ExecContext(ctx, permissionLevel, userID)
-	if err != nil {
+	var count int
+	if err = userExists(ctx, tx, userID, &count); err != nil {
 		return err
 	}
 	if count == 0 {
 		return storage.ErrUserNotFound
 	}
 
-	return nil
+	if _, err = tx.ExecContext(ctx, "UPDATE users SET `permission_level` = ? WHERE id = ?", permissionLevel, userID); err != nil {
+		return err
+	}
+
+	var role string
+	err = tx.QueryRowContext(ctx, "SELECT name FROM roles WHERE legacy_level = ?", permissionLevel).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrRoleNotFound
+		}
+
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM user_roles WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "INSERT INTO user_roles(user_id, role) VALUES(?, ?)", userID, role); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (s *StDb) GetPermission(ctx context.Context, userID int64) (int64, error) {
-	stmt, err := s.db.Prepare("SELECT permission_level FROM users WHERE id = ?")
+	stmt, err := s.db.Prepare("SELECT permission_level FROM users WHERE id = ? AND " + inSchool)
 	if err != nil {
 		return 0, err
 	}
 
-	row := stmt.QueryRowContext(ctx, userID)
+	school := tenant.SchoolID(ctx)
+	row := stmt.QueryRowContext(ctx, userID, school, school)
 	var permissionLevel int64
 	err = row.Scan(&permissionLevel)
 	if err != nil {
@@ -127,38 +196,101 @@ func (s *StDb) GetPermission(ctx context.Context, userID int64) (int64, error) {
 	return permissionLevel, nil
 }
 
+// FillUserInfo fills the profile of an account that is not active yet and
+// records a class change in the membership history. It returns
+// ErrUserActive once the account is active.
 func (s *StDb) FillUserInfo(ctx context.Context, user models.UserInfo) error {
-	count := 0
-	stmt, err := s.db.Prepare("UPDATE users SET `name` = ?, `lastname` = ?, `middlename` = ?, `date_of_birth` = ?, `classname` = ? WHERE id = ? AND is_active = 0")
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		classID  sql.NullInt64
+		isActive bool
+	)
+	school := tenant.SchoolID(ctx)
+	err = tx.QueryRowContext(ctx, "SELECT class_id, is_active FROM users WHERE id = ? AND "+inSchool+" FOR UPDATE", user.ID, school, school).Scan(&classID, &isActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrUserNotFound
+		}
+
 		return err
 	}
 
-	q := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", user.ID)
-	q.Scan(&count)
+	if isActive {
+		return storage.ErrUserActive
+	}
+
+	if user.ClassID != 0 && classID.Int64 != user.ClassID {
+		if err = reserveSeat(ctx, tx, user.ClassID); err != nil {
+			return err
+		}
+	}
 
-	_, err = stmt.ExecContext(ctx, user.Name, user.Lastname, user.Middlename, user.DateOfBirth, user.Classname, user.ID)
+	_, err = tx.ExecContext(ctx, "UPDATE users SET `name` = ?, `lastname` = ?, `middlename` = ?, `date_of_birth` = ?, `classname` = ?, `class_id` = ? WHERE id = ?",
+		user.Name, user.Lastname, user.Middlename, nullDate(user.DateOfBirth), user.Classname, nullID(user.ClassID), user.ID)
 	if err != nil {
 		return err
 	}
-	if count == 0 {
-		return storage.ErrUserNotFound
+
+	if classID.Int64 != user.ClassID {
+		if err = changeClass(ctx, tx, user.ID, user.ClassID, time.Now()); err != nil {
+			return err
+		}
 	}
-	return nil
+
+	return tx.Commit()
+}
+
+func (s *StDb) GetUserInfo(ctx context.Context, userID int64) (models.UserInfo, error) {
+	stmt, err := s.db.Prepare("SELECT id, name, lastname, middlename, date_of_birth, classname, class_id, profile_locked FROM users WHERE id = ? AND " + inSchool)
+	if err != nil {
+		return models.UserInfo{}, err
+	}
+
+	school := tenant.SchoolID(ctx)
+	row := stmt.QueryRowContext(ctx, userID, school, school)
+
+	var (
+		user                                  models.UserInfo
+		name, lastname, middlename, classname sql.NullString
+		dateOfBirth                           sql.NullTime
+		classID                               sql.NullInt64
+	)
+	err = row.Scan(&user.ID, &name, &lastname, &middlename, &dateOfBirth, &classname, &classID, &user.ProfileLocked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UserInfo{}, storage.ErrUserNotFound
+		}
+
+		return models.UserInfo{}, err
+	}
+
+	user.Name = name.String
+	user.Lastname = lastname.String
+	user.Middlename = middlename.String
+	user.DateOfBirth = dateOfBirth.Time
+	user.Classname = classname.String
+	user.ClassID = classID.Int64
+
+	return user, nil
 }
 
 func (s *StDb) ChangeStatus(ctx context.Context, userID int64, isActive bool) error {
 	count := 0
 
-	stmt, err := s.db.Prepare("UPDATE users SET `is_active` = ? WHERE id = ?")
+	stmt, err := s.db.Prepare("UPDATE users SET `is_active` = ? WHERE id = ? AND " + inSchool)
 	if err != nil {
 		return err
 	}
 
-	q := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", userID)
-	q.Scan(&count)
+	userExists(ctx, s.db, userID, &count)
 
-	_, err = stmt.ExecContext(ctx, isActive, userID)
+	school := tenant.SchoolID(ctx)
+	_, err = stmt.ExecContext(ctx, isActive, userID, school, school)
 	if err != nil {
 		return err
 	}
@@ -169,13 +301,40 @@ func (s *StDb) ChangeStatus(ctx context.Context, userID int64, isActive bool) er
 	return nil
 }
 
+func (s *StDb) SetProfileLocked(ctx context.Context, userID int64, locked bool) error {
+	stmt, err := s.db.Prepare("UPDATE users SET `profile_locked` = ? WHERE id = ? AND " + inSchool)
+	if err != nil {
+		return err
+	}
+
+	school := tenant.SchoolID(ctx)
+	res, err := stmt.ExecContext(ctx, locked, userID, school, school)
+	if err != nil {
+		return fmt.Errorf("failed to lock profile due to error: %w", err)
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		// MySQL reports no affected rows when the value does not change.
+		count := 0
+		if err = userExists(ctx, s.db, userID, &count); err != nil {
+			return fmt.Errorf("failed to lock profile due to error: %w", err)
+		}
+		if count == 0 {
+			return storage.ErrUserNotFound
+		}
+	}
+
+	return nil
+}
+
 func (s *StDb) IsActive(ctx context.Context, userID int64) (bool, error) {
-	stmt, err := s.db.Prepare("SELECT is_active FROM users WHERE id = ?")
+	stmt, err := s.db.Prepare("SELECT is_active FROM users WHERE id = ? AND " + inSchool)
 	if err != nil {
 		return false, err
 	}
 
-	row := stmt.QueryRowContext(ctx, userID)
+	school := tenant.SchoolID(ctx)
+	row := stmt.QueryRowContext(ctx, userID, school, school)
 	var isActive bool
 	err = row.Scan(&isActive)
 	if err != nil {
@@ -189,13 +348,14 @@ func (s *StDb) IsActive(ctx context.Context, userID int64) (bool, error) {
 	return isActive, nil
 }
 
-func (s *StDb) GetStudentsByClass(ctx context.Context, classname string) ([]*models.UserDTO, error) {
-	stmt, err := s.db.Prepare("SELECT name, lastname FROM users WHERE classname = ?")
+func (s *StDb) GetStudentsByClass(ctx context.Context, classID int64) ([]*models.UserDTO, error) {
+	stmt, err := s.db.Prepare("SELECT name, lastname FROM users WHERE class_id = ? AND " + inSchool)
 	if err != nil {
 		return nil, err
 	}
 
-	rows, err := stmt.QueryContext(ctx, classname)
+	school := tenant.SchoolID(ctx)
+	rows, err := stmt.QueryContext(ctx, classID, school, school)
 	if err != nil {
 		return nil, err
 	}
@@ -217,15 +377,15 @@ func (s *StDb) GetStudentsByClass(ctx context.Context, classname string) ([]*mod
 
 func (s *StDb) DelUser(ctx context.Context, userID int64) error {
 	count := 0
-	stmt, err := s.db.Prepare("DELETE FROM users WHERE id = ?")
+	stmt, err := s.db.Prepare("DELETE FROM users WHERE id = ? AND " + inSchool)
 	if err != nil {
 		return err
 	}
 
-	q := s.db.QueryRow("SELECT COUNT(*) FROM users WHERE id = ?", userID)
-	q.Scan(&count)
+	userExists(ctx, s.db, userID, &count)
 
-	_, err = stmt.ExecContext(ctx, userID)
+	school := tenant.SchoolID(ctx)
+	_, err = stmt.ExecContext(ctx, userID, school, school)
 	if err != nil {
 		return err
 	}
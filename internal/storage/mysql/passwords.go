@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetPasswordHistory returns the last limit password hashes of the user,
+// newest first.
+func (s *StDb) GetPasswordHistory(ctx context.Context, userID int64, limit int) ([][]byte, error) {
+	stmt, err := s.db.Prepare("SELECT pass_hash FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list password history due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	for rows.Next() {
+		var hash []byte
+		if err = rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+// AddPasswordHistory remembers a password hash and forgets all but the keep
+// newest ones of the user.
+func (s *StDb) AddPasswordHistory(ctx context.Context, userID int64, passHash []byte, keep int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "INSERT INTO password_history(user_id, pass_hash) VALUES(?, ?)", userID, passHash); err != nil {
+		return fmt.Errorf("failed to save password history due to error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM password_history WHERE user_id = ? AND id NOT IN "+
+			"(SELECT id FROM (SELECT id FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?) AS recent)",
+		userID, userID, keep,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to prune password history due to error: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,226 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// syncPermissionLevel keeps users.permission_level, which old clients and the
+// permissions JWT claim still read, equal to the highest role level.
+const syncPermissionLevel = "UPDATE users SET `permission_level` = COALESCE(" +
+	"(SELECT MAX(r.level) FROM user_roles ur JOIN roles r ON r.name = ur.role WHERE ur.user_id = ?), 1) " +
+	"WHERE id = ?"
+
+// The roles of a user are read regardless of the school of the context:
+// permission checks answer for the caller, and a super admin acting in
+// another school keeps their roles. Reading the roles of someone else goes
+// through UserExists first.
+
+// UserExists reports whether userID is a user of the school of ctx.
+func (s *StDb) UserExists(ctx context.Context, userID int64) (bool, error) {
+	var count int
+	if err := userExists(ctx, s.db, userID, &count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *StDb) HasPermission(ctx context.Context, userID int64, permission string) (bool, error) {
+	stmt, err := s.db.Prepare("SELECT COUNT(*) FROM user_roles ur JOIN role_permissions rp ON rp.role = ur.role WHERE ur.user_id = ? AND rp.permission = ?")
+	if err != nil {
+		return false, err
+	}
+
+	var n int
+	if err = stmt.QueryRowContext(ctx, userID, permission).Scan(&n); err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (s *StDb) GetRole(ctx context.Context, name string) (models.Role, error) {
+	stmt, err := s.db.Prepare("SELECT name, level, description FROM roles WHERE name = ?")
+	if err != nil {
+		return models.Role{}, err
+	}
+
+	var role models.Role
+	if err = stmt.QueryRowContext(ctx, name).Scan(&role.Name, &role.Level, &role.Description); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Role{}, storage.ErrRoleNotFound
+		}
+
+		return models.Role{}, err
+	}
+
+	return role, nil
+}
+
+// ListRoles returns every role with its permissions.
+func (s *StDb) ListRoles(ctx context.Context) ([]models.Role, error) {
+	return s.listRoles(ctx,
+		"SELECT r.name, r.level, r.description, rp.permission FROM roles r "+
+			"LEFT JOIN role_permissions rp ON rp.role = r.name ORDER BY r.level, r.name, rp.permission",
+	)
+}
+
+// ListUserRoles returns the roles of a user with their permissions.
+func (s *StDb) ListUserRoles(ctx context.Context, userID int64) ([]models.Role, error) {
+	return s.listRoles(ctx,
+		"SELECT r.name, r.level, r.description, rp.permission FROM user_roles ur "+
+			"JOIN roles r ON r.name = ur.role "+
+			"LEFT JOIN role_permissions rp ON rp.role = r.name "+
+			"WHERE ur.user_id = ? ORDER BY r.level, r.name, rp.permission",
+		userID,
+	)
+}
+
+func (s *StDb) listRoles(ctx context.Context, query string, args ...any) ([]models.Role, error) {
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		var (
+			role       models.Role
+			permission sql.NullString
+		)
+		if err = rows.Scan(&role.Name, &role.Level, &role.Description, &permission); err != nil {
+			return nil, err
+		}
+
+		if n := len(roles); n == 0 || roles[n-1].Name != role.Name {
+			roles = append(roles, role)
+		}
+		if permission.Valid {
+			last := &roles[len(roles)-1]
+			last.Permissions = append(last.Permissions, permission.String)
+		}
+	}
+
+	return roles, rows.Err()
+}
+
+func (s *StDb) AssignRole(ctx context.Context, userID int64, role string) error {
+	return s.changeRoles(ctx, userID, "INSERT IGNORE INTO user_roles(user_id, role) VALUES(?, ?)", role)
+}
+
+func (s *StDb) RevokeRole(ctx context.Context, userID int64, role string) error {
+	return s.changeRoles(ctx, userID, "DELETE FROM user_roles WHERE user_id = ? AND role = ?", role)
+}
+
+func (s *StDb) changeRoles(ctx context.Context, userID int64, query, role string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	school := tenant.SchoolID(ctx)
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE id = ? AND "+inSchool+" FOR UPDATE", userID, school, school).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, query, userID, role); err != nil {
+		return fmt.Errorf("failed to change roles due to error: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, syncPermissionLevel, userID, userID); err != nil {
+		return fmt.Errorf("failed to sync permission level due to error: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// HasScopedPermission reports whether a scoped role of the user grants
+// permission over classname, a class of grade. Scoped roles only cover the
+// school of ctx, and only when the user belongs to it: class names repeat
+// across schools, and a school scope names no school of its own.
+func (s *StDb) HasScopedPermission(ctx context.Context, userID int64, permission, classname, grade string) (bool, error) {
+	stmt, err := s.db.Prepare("SELECT COUNT(*) FROM scoped_roles sr JOIN role_permissions rp ON rp.role = sr.role " +
+		"JOIN users u ON u.id = sr.user_id " +
+		"WHERE sr.user_id = ? AND rp.permission = ? AND u.school_id = ? AND (" +
+		"(sr.scope_type = 'class' AND sr.scope_value = ?) OR " +
+		"(sr.scope_type = 'grade' AND sr.scope_value = ?) OR " +
+		"sr.scope_type = 'school')")
+	if err != nil {
+		return false, err
+	}
+
+	var n int
+	if err = stmt.QueryRowContext(ctx, userID, permission, tenant.SchoolOrDefault(ctx), classname, grade).Scan(&n); err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (s *StDb) ListScopedRoles(ctx context.Context, userID int64) ([]models.ScopedRole, error) {
+	stmt, err := s.db.Prepare("SELECT role, scope_type, scope_value FROM scoped_roles WHERE user_id = ? ORDER BY role, scope_type, scope_value")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scoped roles due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []models.ScopedRole
+	for rows.Next() {
+		var role models.ScopedRole
+		if err = rows.Scan(&role.Role, &role.ScopeType, &role.ScopeValue); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (s *StDb) GrantScopedRole(ctx context.Context, userID int64, role models.ScopedRole) error {
+	return s.changeScopedRoles(ctx, userID,
+		"INSERT IGNORE INTO scoped_roles(user_id, role, scope_type, scope_value) VALUES(?, ?, ?, ?)", role)
+}
+
+func (s *StDb) RevokeScopedRole(ctx context.Context, userID int64, role models.ScopedRole) error {
+	return s.changeScopedRoles(ctx, userID,
+		"DELETE FROM scoped_roles WHERE user_id = ? AND role = ? AND scope_type = ? AND scope_value = ?", role)
+}
+
+func (s *StDb) changeScopedRoles(ctx context.Context, userID int64, query string, role models.ScopedRole) error {
+	var exists int
+	if err := userExists(ctx, s.db, userID, &exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	if _, err := s.db.ExecContext(ctx, query, userID, role.Role, role.ScopeType, role.ScopeValue); err != nil {
+		return fmt.Errorf("failed to change scoped roles due to error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"AuthService/internal/storage/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SavePasswordReset stores a reset token and invalidates the earlier ones of
+// the same user, so only the latest emailed link works.
+func (s *StDb) SavePasswordReset(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM password_resets WHERE user_id = ? AND used_at IS NULL", userID); err != nil {
+		return fmt.Errorf("failed to drop previous resets due to error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO password_resets(token_hash, user_id, expires_at) VALUES(?, ?, ?)",
+		tokenHash, userID, expiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save password reset due to error: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetPasswordReset returns the user an unused, unexpired reset token was
+// issued to without consuming it.
+func (s *StDb) GetPasswordReset(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	stmt, err := s.db.Prepare("SELECT user_id FROM password_resets WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?")
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int64
+	if err = stmt.QueryRowContext(ctx, tokenHash, now.UTC()).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrTokenNotFound
+		}
+
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// ConsumePasswordReset marks an unused, unexpired reset token as used and
+// returns the user it was issued to.
+func (s *StDb) ConsumePasswordReset(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT user_id FROM password_resets WHERE token_hash = ? AND used_at IS NULL AND expires_at > ? FOR UPDATE",
+		tokenHash, now.UTC(),
+	).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrTokenNotFound
+		}
+
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE password_resets SET `used_at` = ? WHERE token_hash = ?", now.UTC(), tokenHash); err != nil {
+		return 0, fmt.Errorf("failed to consume password reset due to error: %w", err)
+	}
+
+	return userID, tx.Commit()
+}
@@ -0,0 +1,89 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+func (s *StDb) RevokeToken(ctx context.Context, token models.RevokedToken) error {
+	stmt, err := s.db.Prepare("INSERT IGNORE INTO revoked_tokens(jti, user_id, expires_at) VALUES(?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement due to error: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx, token.TokenID, token.UserID, token.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to revoke token due to error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StDb) RevokeUserTokens(ctx context.Context, revocation models.UserRevocation) error {
+	stmt, err := s.db.Prepare("INSERT INTO user_revocations(user_id, revoked_before, expires_at) VALUES(?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE `revoked_before` = VALUES(`revoked_before`), `expires_at` = VALUES(`expires_at`)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement due to error: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx, revocation.UserID, revocation.RevokedBefore.UTC(), revocation.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to revoke user tokens due to error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StDb) ListRevokedTokens(ctx context.Context) ([]models.RevokedToken, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT jti, user_id, expires_at FROM revoked_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tokens []models.RevokedToken
+	for rows.Next() {
+		var token models.RevokedToken
+		if err = rows.Scan(&token.TokenID, &token.UserID, &token.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scanning rows due to error: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (s *StDb) ListUserRevocations(ctx context.Context) ([]models.UserRevocation, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT user_id, revoked_before, expires_at FROM user_revocations")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var revocations []models.UserRevocation
+	for rows.Next() {
+		var revocation models.UserRevocation
+		if err = rows.Scan(&revocation.UserID, &revocation.RevokedBefore, &revocation.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scanning rows due to error: %w", err)
+		}
+		revocations = append(revocations, revocation)
+	}
+
+	return revocations, rows.Err()
+}
+
+func (s *StDb) PruneRevocations(ctx context.Context, now time.Time) error {
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < ?", now.UTC()); err != nil {
+		return fmt.Errorf("failed to prune revoked tokens due to error: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, "DELETE FROM user_revocations WHERE expires_at < ?", now.UTC()); err != nil {
+		return fmt.Errorf("failed to prune user revocations due to error: %w", err)
+	}
+
+	return nil
+}
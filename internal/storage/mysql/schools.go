@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// inSchool limits a query on users or classes to the school of the
+// context. It takes the school twice, so that the school 0 of a context
+// reaching every school matches every row.
+const inSchool = "(? = 0 OR school_id = ?)"
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// userExists counts the users with userID in the school of ctx into count.
+func userExists(ctx context.Context, q rowQuerier, userID int64, count *int) error {
+	school := tenant.SchoolID(ctx)
+
+	return q.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE id = ? AND "+inSchool, userID, school, school).Scan(count)
+}
+
+func (s *StDb) CreateSchool(ctx context.Context, name string) (int64, error) {
+	stmt, err := s.db.Prepare("INSERT INTO schools(name) VALUES(?)")
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := stmt.ExecContext(ctx, name)
+	if err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+			return 0, storage.ErrSchoolExists
+		}
+
+		return 0, fmt.Errorf("failed to create school due to error: %w", err)
+	}
+
+	return res.LastInsertId()
+}
+
+func (s *StDb) ListSchools(ctx context.Context) ([]models.School, error) {
+	stmt, err := s.db.Prepare("SELECT id, name, created_at FROM schools ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schools due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var schools []models.School
+	for rows.Next() {
+		var school models.School
+		if err = rows.Scan(&school.ID, &school.Name, &school.CreatedAt); err != nil {
+			return nil, err
+		}
+		schools = append(schools, school)
+	}
+
+	return schools, rows.Err()
+}
@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const sessionColumns = "id, user_id, device_label, user_agent, ip, created_at, last_seen_at, expires_at, terminated_at"
+
+func (s *StDb) CreateSession(ctx context.Context, session models.Session) error {
+	stmt, err := s.db.Prepare("INSERT INTO sessions(id, user_id, device_label, user_agent, ip, created_at, last_seen_at, expires_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement due to error: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx,
+		session.ID, session.UserID,
+		session.Device.Label, session.Device.UserAgent, session.Device.IP,
+		session.CreatedAt.UTC(), session.LastSeenAt.UTC(), session.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session due to error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StDb) TouchSession(ctx context.Context, sessionID string, lastSeenAt, expiresAt time.Time) error {
+	stmt, err := s.db.Prepare("UPDATE sessions SET `last_seen_at` = ?, `expires_at` = ? WHERE id = ?")
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, lastSeenAt.UTC(), expiresAt.UTC(), sessionID)
+
+	return err
+}
+
+func (s *StDb) GetSession(ctx context.Context, sessionID string) (models.Session, error) {
+	stmt, err := s.db.Prepare("SELECT " + sessionColumns + " FROM sessions WHERE id = ?")
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	session, err := scanSession(stmt.QueryRowContext(ctx, sessionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Session{}, storage.ErrSessionNotFound
+		}
+
+		return models.Session{}, err
+	}
+
+	return session, nil
+}
+
+// ListSessions returns the sessions of a user that are neither terminated
+// nor expired, most recently used first.
+func (s *StDb) ListSessions(ctx context.Context, userID int64) ([]models.Session, error) {
+	stmt, err := s.db.Prepare("SELECT " + sessionColumns + " FROM sessions " +
+		"WHERE user_id = ? AND terminated_at IS NULL AND expires_at > ? ORDER BY last_seen_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scanning rows due to error: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+func (s *StDb) TerminateSession(ctx context.Context, sessionID string, terminatedAt time.Time) error {
+	stmt, err := s.db.Prepare("UPDATE sessions SET `terminated_at` = ? WHERE id = ? AND terminated_at IS NULL")
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, terminatedAt.UTC(), sessionID)
+
+	return err
+}
+
+func (s *StDb) TerminateUserSessions(ctx context.Context, userID int64) error {
+	stmt, err := s.db.Prepare("UPDATE sessions SET `terminated_at` = ? WHERE user_id = ? AND terminated_at IS NULL")
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, time.Now().UTC(), userID)
+
+	return err
+}
+
+// ListTerminatedSessions returns the sessions terminated after since.
+func (s *StDb) ListTerminatedSessions(ctx context.Context, since time.Time) ([]models.Session, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+sessionColumns+" FROM sessions WHERE terminated_at >= ?", since.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scanning rows due to error: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row rowScanner) (models.Session, error) {
+	var (
+		session      models.Session
+		terminatedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&session.ID, &session.UserID,
+		&session.Device.Label, &session.Device.UserAgent, &session.Device.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &terminatedAt,
+	)
+	if err != nil {
+		return models.Session{}, err
+	}
+
+	session.TerminatedAt = terminatedAt.Time
+
+	return session, nil
+}
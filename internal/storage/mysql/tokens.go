@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *StDb) SaveRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	stmt, err := s.db.Prepare("INSERT INTO refresh_tokens(user_id, token_hash, family_id, expires_at) VALUES(?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement due to error: %w", err)
+	}
+
+	_, err = stmt.ExecContext(ctx, token.UserID, token.TokenHash, token.FamilyID, token.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save refresh token due to error: %w", err)
+	}
+
+	return nil
+}
+
+func (s *StDb) GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	stmt, err := s.db.Prepare("SELECT id, user_id, token_hash, family_id, expires_at, rotated_at, revoked_at FROM refresh_tokens WHERE token_hash = ?")
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	row := stmt.QueryRowContext(ctx, tokenHash)
+
+	var (
+		token     models.RefreshToken
+		rotatedAt sql.NullTime
+		revokedAt sql.NullTime
+	)
+	err = row.Scan(&token.ID, &token.UserID, &token.TokenHash, &token.FamilyID, &token.ExpiresAt, &rotatedAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RefreshToken{}, storage.ErrTokenNotFound
+		}
+
+		return models.RefreshToken{}, err
+	}
+
+	token.RotatedAt = rotatedAt.Time
+	token.RevokedAt = revokedAt.Time
+
+	return token, nil
+}
+
+// RotateRefreshToken marks the old token as used and stores its successor in
+// one transaction. If the old token was already rotated by a concurrent call
+// it returns storage.ErrTokenAlreadyRotated and stores nothing.
+func (s *StDb) RotateRefreshToken(ctx context.Context, oldID int64, next models.RefreshToken) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"UPDATE refresh_tokens SET `rotated_at` = ? WHERE id = ? AND rotated_at IS NULL AND revoked_at IS NULL",
+		time.Now().UTC(), oldID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token due to error: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return storage.ErrTokenAlreadyRotated
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO refresh_tokens(user_id, token_hash, family_id, expires_at) VALUES(?, ?, ?, ?)",
+		next.UserID, next.TokenHash, next.FamilyID, next.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save refresh token due to error: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *StDb) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	stmt, err := s.db.Prepare("UPDATE refresh_tokens SET `revoked_at` = ? WHERE family_id = ? AND revoked_at IS NULL")
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, time.Now().UTC(), familyID)
+
+	return err
+}
+
+func (s *StDb) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	stmt, err := s.db.Prepare("UPDATE refresh_tokens SET `revoked_at` = ? WHERE user_id = ? AND revoked_at IS NULL")
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, time.Now().UTC(), userID)
+
+	return err
+}
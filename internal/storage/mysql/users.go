@@ -0,0 +1,284 @@
+package mysql
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/storage/storage"
+	"AuthService/internal/tenant"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// selectUserRecords ends with the inSchool condition; queries add their own
+// conditions with AND.
+const selectUserRecords = "SELECT u.id, u.email, u.name, u.lastname, u.middlename, u.date_of_birth, u.classname, u.class_id, u.profile_locked, " +
+	"u.permission_level, u.is_active, u.email_verified, u.school_id, u.created_at, " +
+	"(u.parental_consent_at IS NOT NULL OR EXISTS (SELECT 1 FROM guardians g WHERE g.student_id = u.id AND g.approved_at IS NOT NULL)), " +
+	"(SELECT GROUP_CONCAT(r.role ORDER BY r.role) FROM user_roles r WHERE r.user_id = u.id) " +
+	"FROM users u WHERE " + inSchool
+
+// sortColumns are the columns of each sort order of ListUsers, before the
+// user ID that breaks ties. They match models.UserRecord.Cursor.
+var sortColumns = map[string][]string{
+	models.SortUsersByLastname:  {"COALESCE(u.lastname, '')", "COALESCE(u.name, '')"},
+	models.SortUsersByEmail:     {"u.email"},
+	models.SortUsersByCreatedAt: {"u.created_at"},
+	models.SortUsersByID:        nil,
+}
+
+// ListUsers returns a page of the users of the school of ctx that match
+// filter. Pages are found by the position of the last user of the previous
+// one, so they stay consistent while users are added.
+func (s *StDb) ListUsers(ctx context.Context, filter models.UserFilter, page models.UserPage) ([]models.UserRecord, error) {
+	sortBy, ok := sortColumns[page.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort order %q", page.SortBy)
+	}
+	keys := append(append([]string{}, sortBy...), "u.id")
+
+	where, args := userFilterWhere(ctx, filter)
+
+	if page.After != nil {
+		if len(page.After.Values) != len(sortBy) {
+			return nil, fmt.Errorf("cursor does not match sort order %q", page.SortBy)
+		}
+
+		op := ">"
+		if page.Descending {
+			op = "<"
+		}
+		where = append(where, "("+strings.Join(keys, ", ")+") "+op+" ("+strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")+")")
+		for _, v := range page.After.Values {
+			args = append(args, v)
+		}
+		args = append(args, page.After.ID)
+	}
+
+	query := selectUserRecords
+	if len(where) > 0 {
+		query += " AND " + strings.Join(where, " AND ")
+	}
+
+	dir := ""
+	if page.Descending {
+		dir = " DESC"
+	}
+	query += " ORDER BY " + strings.Join(keys, dir+", ") + dir + " LIMIT ?"
+	args = append(args, page.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due to error: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.UserRecord
+	for rows.Next() {
+		user, err := scanUserRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user due to error: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// CountUsers counts the users of the school of ctx that match filter.
+func (s *StDb) CountUsers(ctx context.Context, filter models.UserFilter) (int64, error) {
+	where, args := userFilterWhere(ctx, filter)
+
+	query := "SELECT COUNT(*) FROM users u WHERE " + inSchool
+	if len(where) > 0 {
+		query += " AND " + strings.Join(where, " AND ")
+	}
+
+	var count int64
+	if err := s.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count users due to error: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetUserRecord returns userID with its account details.
+func (s *StDb) GetUserRecord(ctx context.Context, userID int64) (models.UserRecord, error) {
+	school := tenant.SchoolID(ctx)
+
+	user, err := scanUserRecord(s.db.QueryRowContext(ctx, selectUserRecords+" AND u.id = ?", school, school, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.UserRecord{}, storage.ErrUserNotFound
+		}
+
+		return models.UserRecord{}, fmt.Errorf("failed to get user due to error: %w", err)
+	}
+
+	return user, nil
+}
+
+// profileColumns are the columns of the profile fields of models.UserInfo.
+var profileColumns = map[string]func(models.UserInfo) (string, any){
+	models.ProfileName:        func(u models.UserInfo) (string, any) { return "name", u.Name },
+	models.ProfileLastname:    func(u models.UserInfo) (string, any) { return "lastname", u.Lastname },
+	models.ProfileMiddlename:  func(u models.UserInfo) (string, any) { return "middlename", u.Middlename },
+	models.ProfileDateOfBirth: func(u models.UserInfo) (string, any) { return "date_of_birth", nullDate(u.DateOfBirth) },
+	models.ProfileClassname:   func(u models.UserInfo) (string, any) { return "classname", u.Classname },
+}
+
+// UpdateUserInfo sets the named profile fields of user.ID, whether or not
+// the account is active. A new class also sets class_id and is recorded in
+// the membership history; it returns storage.ErrClassFull when the class
+// has no free seat.
+func (s *StDb) UpdateUserInfo(ctx context.Context, user models.UserInfo, fields []string) error {
+	var (
+		set  []string
+		args []any
+	)
+	for _, field := range fields {
+		column, ok := profileColumns[field]
+		if !ok {
+			return fmt.Errorf("unknown profile field %q", field)
+		}
+		name, value := column(user)
+		set = append(set, "`"+name+"` = ?")
+		args = append(args, value)
+		if field == models.ProfileClassname {
+			set = append(set, "`class_id` = ?")
+			args = append(args, nullID(user.ClassID))
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var classID sql.NullInt64
+	school := tenant.SchoolID(ctx)
+	err = tx.QueryRowContext(ctx, "SELECT class_id FROM users WHERE id = ? AND "+inSchool+" FOR UPDATE", user.ID, school, school).Scan(&classID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrUserNotFound
+		}
+
+		return err
+	}
+
+	moves := slices.Contains(fields, models.ProfileClassname) && classID.Int64 != user.ClassID
+	if moves && user.ClassID != 0 {
+		if err = reserveSeat(ctx, tx, user.ClassID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE users SET "+strings.Join(set, ", ")+" WHERE id = ?", append(args, user.ID)...)
+	if err != nil {
+		return fmt.Errorf("failed to update user due to error: %w", err)
+	}
+
+	if moves {
+		if err = changeClass(ctx, tx, user.ID, user.ClassID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// userFilterWhere returns the conditions of filter on the users table u and
+// the arguments of the query, starting with those of inSchool.
+func userFilterWhere(ctx context.Context, filter models.UserFilter) ([]string, []any) {
+	school := tenant.SchoolID(ctx)
+
+	var (
+		where []string
+		args  = []any{school, school}
+	)
+	if filter.EmailPrefix != "" {
+		where = append(where, "u.email LIKE ?")
+		args = append(args, escapeLike(filter.EmailPrefix)+"%")
+	}
+	if filter.Name != "" {
+		where = append(where, "(u.name LIKE ? OR u.lastname LIKE ? OR u.middlename LIKE ?)")
+		pattern := "%" + escapeLike(filter.Name) + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+	if filter.ClassID != 0 {
+		where = append(where, "u.class_id = ?")
+		args = append(args, filter.ClassID)
+	}
+	if filter.Role != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM user_roles r WHERE r.user_id = u.id AND r.role = ?)")
+		args = append(args, filter.Role)
+	}
+	if filter.PermissionLevel != 0 {
+		where = append(where, "u.permission_level = ?")
+		args = append(args, filter.PermissionLevel)
+	}
+	if filter.Active != nil {
+		where = append(where, "u.is_active = ?")
+		args = append(args, *filter.Active)
+	}
+	if filter.SchoolID != 0 {
+		where = append(where, "u.school_id = ?")
+		args = append(args, filter.SchoolID)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		where = append(where, "u.created_at >= ?")
+		args = append(args, filter.CreatedAfter.UTC())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		where = append(where, "u.created_at < ?")
+		args = append(args, filter.CreatedBefore.UTC())
+	}
+
+	return where, args
+}
+
+// nullDate stores the zero time as NULL.
+func nullDate(d time.Time) sql.NullTime {
+	return sql.NullTime{Time: d, Valid: !d.IsZero()}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// scanUserRecord reads the columns of selectUserRecords.
+func scanUserRecord(row scanner) (models.UserRecord, error) {
+	var (
+		user                                  models.UserRecord
+		name, lastname, middlename, classname sql.NullString
+		dateOfBirth                           sql.NullTime
+		classID                               sql.NullInt64
+		roles                                 sql.NullString
+	)
+	err := row.Scan(&user.Profile.ID, &user.Email, &name, &lastname, &middlename, &dateOfBirth, &classname, &classID,
+		&user.Profile.ProfileLocked, &user.PermissionLevel, &user.Active, &user.EmailVerified, &user.SchoolID, &user.CreatedAt,
+		&user.GuardianConsent, &roles)
+	if err != nil {
+		return models.UserRecord{}, err
+	}
+
+	user.Profile.Name = name.String
+	user.Profile.Lastname = lastname.String
+	user.Profile.Middlename = middlename.String
+	user.Profile.DateOfBirth = dateOfBirth.Time
+	user.Profile.Classname = classname.String
+	user.Profile.ClassID = classID.Int64
+	if roles.String != "" {
+		user.Roles = strings.Split(roles.String, ",")
+	}
+
+	return user, nil
+}
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"AuthService/internal/storage/storage"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SaveEmailVerification stores a verification token and invalidates the
+// earlier ones of the same user.
+func (s *StDb) SaveEmailVerification(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM email_verifications WHERE user_id = ? AND used_at IS NULL", userID); err != nil {
+		return fmt.Errorf("failed to drop previous verifications due to error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO email_verifications(token_hash, user_id, expires_at) VALUES(?, ?, ?)",
+		tokenHash, userID, expiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save email verification due to error: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ConsumeEmailVerification marks an unused, unexpired verification token as
+// used and the email of its user as verified.
+func (s *StDb) ConsumeEmailVerification(ctx context.Context, tokenHash string, now time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction due to error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT user_id FROM email_verifications WHERE token_hash = ? AND used_at IS NULL AND expires_at > ? FOR UPDATE",
+		tokenHash, now.UTC(),
+	).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, storage.ErrTokenNotFound
+		}
+
+		return 0, err
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE email_verifications SET `used_at` = ? WHERE token_hash = ?", now.UTC(), tokenHash); err != nil {
+		return 0, fmt.Errorf("failed to consume email verification due to error: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE users SET `email_verified` = 1 WHERE id = ?", userID); err != nil {
+		return 0, fmt.Errorf("failed to verify email due to error: %w", err)
+	}
+
+	return userID, tx.Commit()
+}
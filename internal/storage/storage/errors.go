@@ -3,6 +3,20 @@ package storage
 import "errors"
 
 var (
-	ErrUserExists   = errors.New("user already exists")
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists          = errors.New("user already exists")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrTokenNotFound       = errors.New("token not found")
+	ErrTokenAlreadyRotated = errors.New("token already rotated")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrTOTPNotFound        = errors.New("totp not found")
+	ErrCodeAlreadyUsed     = errors.New("code already used")
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrClassNotFound       = errors.New("class not found")
+	ErrClassExists         = errors.New("class already exists")
+	ErrClassFull           = errors.New("class is full")
+	ErrGuardianNotFound    = errors.New("guardian link not found")
+	ErrSelfGuardian        = errors.New("user cannot be their own guardian")
+	ErrSchoolNotFound      = errors.New("school not found")
+	ErrSchoolExists        = errors.New("school already exists")
+	ErrUserActive          = errors.New("user is active")
 )
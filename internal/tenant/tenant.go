@@ -0,0 +1,43 @@
+// Package tenant carries the school a call acts in through its context.
+// The storage limits the users and classes it reads and writes to that
+// school; data owned by a user, such as sessions, is reached through the
+// user and needs no filter of its own.
+package tenant
+
+import "context"
+
+// DefaultSchoolID is the school created by the tenancy migration. Calls
+// that name no school register and look up users by email in it.
+const DefaultSchoolID int64 = 1
+
+type schoolKey struct{}
+
+// WithSchool returns a copy of ctx limited to schoolID.
+func WithSchool(ctx context.Context, schoolID int64) context.Context {
+	return context.WithValue(ctx, schoolKey{}, schoolID)
+}
+
+// WithAllSchools returns a copy of ctx that reaches every school, as super
+// admins do.
+func WithAllSchools(ctx context.Context) context.Context {
+	return context.WithValue(ctx, schoolKey{}, int64(0))
+}
+
+// SchoolID returns the school ctx is limited to, or 0 when it reaches every
+// school. Contexts that never went through WithSchool, such as those of the
+// background jobs of the service, reach every school.
+func SchoolID(ctx context.Context) int64 {
+	id, _ := ctx.Value(schoolKey{}).(int64)
+
+	return id
+}
+
+// SchoolOrDefault returns the school new users and classes of ctx belong
+// to.
+func SchoolOrDefault(ctx context.Context) int64 {
+	if id := SchoolID(ctx); id != 0 {
+		return id
+	}
+
+	return DefaultSchoolID
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+)
+
+func ConvertClass(class models.Class) *pb.Class {
+	pbClass := &pb.Class{
+		Id:                class.ID,
+		Name:              class.Name,
+		Grade:             class.Grade,
+		Letter:            class.Letter,
+		AcademicYear:      class.AcademicYear,
+		HomeroomTeacherId: class.HomeroomTeacherID,
+		Capacity:          class.Capacity,
+		Students:          class.Students,
+	}
+	if class.Archived() {
+		pbClass.ArchivedAt = class.ArchivedAt.Unix()
+	}
+	return pbClass
+}
+
+func ConvertClasses(classes []models.Class) []*pb.Class {
+	pbClasses := make([]*pb.Class, 0, len(classes))
+	for _, class := range classes {
+		pbClasses = append(pbClasses, ConvertClass(class))
+	}
+	return pbClasses
+}
+
+func ConvertStudentPromotions(report []models.StudentPromotion) []*pb.StudentPromotion {
+	pbReport := make([]*pb.StudentPromotion, 0, len(report))
+	for _, line := range report {
+		pbReport = append(pbReport, &pb.StudentPromotion{
+			UserId:    line.UserID,
+			Name:      line.Name,
+			Lastname:  line.Lastname,
+			FromClass: line.FromClass,
+			ToClass:   line.ToClass,
+			Outcome:   line.Outcome,
+		})
+	}
+	return pbReport
+}
+
+func ConvertClassHistory(history []models.ClassMembership) []*pb.ClassMembership {
+	pbHistory := make([]*pb.ClassMembership, 0, len(history))
+	for _, m := range history {
+		pbMembership := &pb.ClassMembership{
+			ClassId:      m.ClassID,
+			ClassName:    m.ClassName,
+			AcademicYear: m.AcademicYear,
+			JoinedAt:     m.JoinedAt.Unix(),
+		}
+		if !m.LeftAt.IsZero() {
+			pbMembership.LeftAt = m.LeftAt.Unix()
+		}
+		pbHistory = append(pbHistory, pbMembership)
+	}
+	return pbHistory
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+)
+
+func ConvertGuardianLinks(links []models.GuardianLink) []*pb.GuardianLink {
+	pbLinks := make([]*pb.GuardianLink, 0, len(links))
+	for _, link := range links {
+		pbLink := &pb.GuardianLink{
+			GuardianId:   link.GuardianID,
+			StudentId:    link.StudentID,
+			Relationship: link.Relationship,
+			IsPrimary:    link.Primary,
+			CreatedAt:    link.CreatedAt.Unix(),
+			Name:         link.Other.Name,
+			Lastname:     link.Other.Lastname,
+			Classname:    link.Other.Classname,
+		}
+		if !link.ApprovedAt.IsZero() {
+			pbLink.ApprovedAt = link.ApprovedAt.Unix()
+		}
+		pbLinks = append(pbLinks, pbLink)
+	}
+	return pbLinks
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+)
+
+func ConvertImportRows(pbRows []*pb.ImportRow) []models.ImportRow {
+	rows := make([]models.ImportRow, 0, len(pbRows))
+	for _, row := range pbRows {
+		rows = append(rows, models.ImportRow{
+			Line:        row.Line,
+			Email:       row.Email,
+			Name:        row.Name,
+			Lastname:    row.Lastname,
+			Middlename:  row.Middlename,
+			DateOfBirth: row.DateOfBirth,
+			Classname:   row.Classname,
+			Role:        row.Role,
+		})
+	}
+	return rows
+}
+
+func ConvertImportResults(results []models.ImportResult) []*pb.ImportRowResult {
+	pbResults := make([]*pb.ImportRowResult, 0, len(results))
+	for _, result := range results {
+		pbResults = append(pbResults, &pb.ImportRowResult{
+			Line:                   result.Line,
+			Email:                  result.Email,
+			UserId:                 result.UserID,
+			Status:                 result.Status,
+			Errors:                 result.Errors,
+			ActivationRequirements: result.ActivationRequirements,
+		})
+	}
+	return pbResults
+}
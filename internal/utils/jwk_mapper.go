@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"AuthService/internal/pb"
+	"AuthService/pkg/tools/jwt"
+)
+
+func ConvertJWKs(keys []jwt.JWK) []*pb.JWK {
+	pbKeys := make([]*pb.JWK, 0, len(keys))
+	for _, key := range keys {
+		pbKeys = append(pbKeys, &pb.JWK{
+			Kid: key.Kid,
+			Kty: key.Kty,
+			Alg: key.Alg,
+			Use: key.Use,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+			Y:   key.Y,
+		})
+	}
+	return pbKeys
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+)
+
+func ConvertRoles(roles []models.Role) []*pb.Role {
+	pbRoles := make([]*pb.Role, 0, len(roles))
+	for _, role := range roles {
+		pbRoles = append(pbRoles, &pb.Role{
+			Name:        role.Name,
+			Level:       role.Level,
+			Description: role.Description,
+			Permissions: role.Permissions,
+		})
+	}
+	return pbRoles
+}
+
+func ConvertScopedRoles(roles []models.ScopedRole) []*pb.ScopedRole {
+	pbRoles := make([]*pb.ScopedRole, 0, len(roles))
+	for _, role := range roles {
+		pbRoles = append(pbRoles, &pb.ScopedRole{
+			Role:       role.Role,
+			ScopeType:  role.ScopeType,
+			ScopeValue: role.ScopeValue,
+		})
+	}
+	return pbRoles
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+)
+
+func ConvertSchool(school models.School) *pb.School {
+	return &pb.School{
+		Id:        school.ID,
+		Name:      school.Name,
+		CreatedAt: school.CreatedAt.Unix(),
+	}
+}
+
+func ConvertSchools(schools []models.School) []*pb.School {
+	pbSchools := make([]*pb.School, 0, len(schools))
+	for _, school := range schools {
+		pbSchools = append(pbSchools, ConvertSchool(school))
+	}
+	return pbSchools
+}
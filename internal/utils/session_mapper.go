@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"AuthService/internal/models"
+	"AuthService/internal/pb"
+)
+
+func ConvertSessions(sessions []models.Session, currentID string) []*pb.Session {
+	pbSessions := make([]*pb.Session, 0, len(sessions))
+	for _, session := range sessions {
+		pbSessions = append(pbSessions, &pb.Session{
+			SessionId:   session.ID,
+			UserId:      session.UserID,
+			DeviceLabel: session.Device.Label,
+			UserAgent:   session.Device.UserAgent,
+			Ip:          session.Device.IP,
+			CreatedAt:   session.CreatedAt.Unix(),
+			LastSeenAt:  session.LastSeenAt.Unix(),
+			ExpiresAt:   session.ExpiresAt.Unix(),
+			Current:     session.ID == currentID,
+		})
+	}
+	return pbSessions
+}
@@ -3,6 +3,10 @@ package utils
 import (
 	"AuthService/internal/models"
 	"AuthService/internal/pb"
+	"AuthService/pkg/tools/birthdate"
+	"time"
+
+	"google.golang.org/genproto/googleapis/type/date"
 )
 
 func ConvertUsers(users []*models.UserDTO) []*pb.Student {
@@ -15,3 +19,43 @@ func ConvertUsers(users []*models.UserDTO) []*pb.Student {
 	}
 	return pbStudents
 }
+
+func ConvertUserRecord(user models.UserRecord) *pb.UserProfile {
+	return &pb.UserProfile{
+		UserId:                 user.Profile.ID,
+		Email:                  user.Email,
+		Name:                   user.Profile.Name,
+		Lastname:               user.Profile.Lastname,
+		Middlename:             user.Profile.Middlename,
+		DateOfBirth:            birthdate.Format(user.Profile.DateOfBirth),
+		BirthDate:              ConvertDate(user.Profile.DateOfBirth),
+		Classname:              user.Profile.Classname,
+		ClassId:                user.Profile.ClassID,
+		Roles:                  user.Roles,
+		PermissionLevel:        user.PermissionLevel,
+		Active:                 user.Active,
+		EmailVerified:          user.EmailVerified,
+		ProfileLocked:          user.Profile.ProfileLocked,
+		SchoolId:               user.SchoolID,
+		CreatedAt:              user.CreatedAt.Unix(),
+		GuardianConsent:        user.GuardianConsent,
+		ActivationRequirements: user.ActivationRequirements,
+	}
+}
+
+// ConvertDate returns nil for the zero time.
+func ConvertDate(d time.Time) *date.Date {
+	if d.IsZero() {
+		return nil
+	}
+
+	return &date.Date{Year: int32(d.Year()), Month: int32(d.Month()), Day: int32(d.Day())}
+}
+
+func ConvertUserRecords(users []models.UserRecord) []*pb.UserProfile {
+	pbUsers := make([]*pb.UserProfile, 0, len(users))
+	for _, user := range users {
+		pbUsers = append(pbUsers, ConvertUserRecord(user))
+	}
+	return pbUsers
+}
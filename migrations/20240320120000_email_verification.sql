@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE `users` ADD COLUMN `email_verified` tinyint(1) NOT NULL DEFAULT '0';
+-- +goose StatementEnd
+
+-- Accounts created before verification existed are trusted as they are.
+-- +goose StatementBegin
+UPDATE `users` SET `email_verified` = 1;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE `email_verifications` (
+  `token_hash` char(64) NOT NULL,
+  `user_id` int NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`token_hash`),
+  KEY `user_id` (`user_id`),
+  CONSTRAINT `email_verifications_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_verifications;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users` DROP COLUMN `email_verified`;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE `login_attempts` (
+  `key` varchar(320) NOT NULL,
+  `failures` int NOT NULL DEFAULT '0',
+  `last_failure` datetime NOT NULL,
+  PRIMARY KEY (`key`),
+  KEY `last_failure` (`last_failure`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,120 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE `roles` (
+  `name` varchar(50) NOT NULL,
+  `level` int NOT NULL,
+  `legacy_level` int DEFAULT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  PRIMARY KEY (`name`),
+  UNIQUE KEY `legacy_level` (`legacy_level`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE `permissions` (
+  `name` varchar(100) NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE `role_permissions` (
+  `role` varchar(50) NOT NULL,
+  `permission` varchar(100) NOT NULL,
+  PRIMARY KEY (`role`, `permission`),
+  KEY `permission` (`permission`),
+  CONSTRAINT `role_permissions_role_fk` FOREIGN KEY (`role`) REFERENCES `roles` (`name`) ON DELETE CASCADE,
+  CONSTRAINT `role_permissions_permission_fk` FOREIGN KEY (`permission`) REFERENCES `permissions` (`name`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE `user_roles` (
+  `user_id` int NOT NULL,
+  `role` varchar(50) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`user_id`, `role`),
+  KEY `role` (`role`),
+  CONSTRAINT `user_roles_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `user_roles_role_fk` FOREIGN KEY (`role`) REFERENCES `roles` (`name`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- level orders the roles; legacy_level is the permission_level each role
+-- stands for in the old API.
+-- +goose StatementBegin
+INSERT INTO `roles` (`name`, `level`, `legacy_level`, `description`) VALUES
+  ('student', 1, 1, 'Student'),
+  ('parent', 1, NULL, 'Parent or guardian of a student'),
+  ('teacher', 2, 2, 'Teacher'),
+  ('head_teacher', 2, NULL, 'Head teacher of a class'),
+  ('school_admin', 3, 3, 'School administrator'),
+  ('system_admin', 4, 4, 'Administrator of the whole system');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('users.status.change', 'Activate and deactivate accounts'),
+  ('users.permission.set', 'Set the legacy permission level of accounts'),
+  ('users.delete', 'Delete accounts'),
+  ('users.profile.edit', 'Edit the profiles of other users'),
+  ('students.list', 'List the students of a class'),
+  ('roles.assign', 'Assign and revoke roles'),
+  ('roles.read', 'Read the roles and permissions of other users'),
+  ('sessions.manage', 'List and terminate the sessions of other users'),
+  ('accounts.unlock', 'Lift brute-force lockouts'),
+  ('mfa.manage', 'Require two-factor authentication by permission level');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('teacher', 'students.list'),
+  ('head_teacher', 'students.list'),
+  ('school_admin', 'users.status.change'),
+  ('school_admin', 'users.permission.set'),
+  ('school_admin', 'users.delete'),
+  ('school_admin', 'users.profile.edit'),
+  ('school_admin', 'students.list'),
+  ('school_admin', 'roles.assign'),
+  ('school_admin', 'roles.read'),
+  ('school_admin', 'sessions.manage'),
+  ('school_admin', 'accounts.unlock'),
+  ('school_admin', 'mfa.manage'),
+  ('system_admin', 'users.status.change'),
+  ('system_admin', 'users.permission.set'),
+  ('system_admin', 'users.delete'),
+  ('system_admin', 'users.profile.edit'),
+  ('system_admin', 'students.list'),
+  ('system_admin', 'roles.assign'),
+  ('system_admin', 'roles.read'),
+  ('system_admin', 'sessions.manage'),
+  ('system_admin', 'accounts.unlock'),
+  ('system_admin', 'mfa.manage');
+-- +goose StatementEnd
+
+-- Every existing account gets the role its permission level stood for.
+-- Levels above 4 were only used for superusers.
+-- +goose StatementBegin
+INSERT INTO `user_roles` (`user_id`, `role`)
+SELECT u.`id`, r.`name`
+FROM `users` u
+JOIN `roles` r ON r.`legacy_level` = LEAST(GREATEST(u.`permission_level`, 1), 4);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_roles;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_permissions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS roles;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE `users` ADD COLUMN `profile_locked` tinyint(1) NOT NULL DEFAULT '0';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('students.list.all', 'List the students of any class');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('school_admin', 'students.list.all'),
+  ('system_admin', 'students.list.all');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM `permissions` WHERE `name` = 'students.list.all';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users` DROP COLUMN `profile_locked`;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE `scoped_roles` (
+  `user_id` int NOT NULL,
+  `role` varchar(50) NOT NULL,
+  `scope_type` enum('class','grade','school') NOT NULL,
+  `scope_value` varchar(100) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`user_id`, `role`, `scope_type`, `scope_value`),
+  KEY `role` (`role`),
+  CONSTRAINT `scoped_roles_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `scoped_roles_role_fk` FOREIGN KEY (`role`) REFERENCES `roles` (`name`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- class_teacher is meant to be granted with a scope: held globally it would
+-- let a teacher manage every student.
+-- +goose StatementBegin
+INSERT INTO `roles` (`name`, `level`, `legacy_level`, `description`) VALUES
+  ('class_teacher', 2, NULL, 'Teacher managing the students of the classes in scope');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('class_teacher', 'students.list'),
+  ('class_teacher', 'users.status.change'),
+  ('class_teacher', 'users.profile.edit'),
+  ('class_teacher', 'sessions.manage'),
+  ('class_teacher', 'accounts.unlock');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS scoped_roles;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DELETE FROM `roles` WHERE `name` = 'class_teacher';
+-- +goose StatementEnd
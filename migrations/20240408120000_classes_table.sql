@@ -0,0 +1,79 @@
+-- +goose Up
+-- academic_year is the calendar year the school year starts in: 2023 for
+-- 2023/24.
+-- +goose StatementBegin
+CREATE TABLE `classes` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(3) NOT NULL,
+  `grade` tinyint NOT NULL,
+  `letter` varchar(1) NOT NULL,
+  `academic_year` smallint NOT NULL,
+  `homeroom_teacher_id` int DEFAULT NULL,
+  `capacity` int NOT NULL DEFAULT '30',
+  `archived_at` datetime DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `academic_year_grade_letter` (`academic_year`, `grade`, `letter`),
+  KEY `name` (`name`),
+  KEY `homeroom_teacher_id` (`homeroom_teacher_id`),
+  CONSTRAINT `classes_homeroom_teacher_fk` FOREIGN KEY (`homeroom_teacher_id`) REFERENCES `users` (`id`) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users`
+  ADD COLUMN `class_id` int DEFAULT NULL,
+  ADD KEY `class_id` (`class_id`),
+  ADD CONSTRAINT `users_class_fk` FOREIGN KEY (`class_id`) REFERENCES `classes` (`id`) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- Every class name already in use becomes a class of the current school
+-- year. Names are compared case-insensitively, so "9a" and "9A" end up in
+-- the same class; names that are not a grade and a letter are left alone.
+-- +goose StatementBegin
+INSERT INTO `classes` (`name`, `grade`, `letter`, `academic_year`)
+SELECT DISTINCT
+  UPPER(TRIM(`classname`)),
+  CAST(REGEXP_SUBSTR(TRIM(`classname`), '^[0-9]+') AS UNSIGNED),
+  UPPER(RIGHT(TRIM(`classname`), 1)),
+  IF(MONTH(CURDATE()) >= 9, YEAR(CURDATE()), YEAR(CURDATE()) - 1)
+FROM `users`
+WHERE TRIM(`classname`) REGEXP '^(1[01]|[1-9])[^0-9 ]$';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE `users` u
+JOIN `classes` c ON c.`name` = UPPER(TRIM(u.`classname`))
+SET u.`class_id` = c.`id`, u.`classname` = c.`name`;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE `classes` c
+JOIN (SELECT `class_id`, COUNT(*) AS `students` FROM `users` WHERE `class_id` IS NOT NULL GROUP BY `class_id`) s
+  ON s.`class_id` = c.`id`
+SET c.`capacity` = GREATEST(c.`capacity`, s.`students`);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('classes.manage', 'Create, update and archive classes');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('school_admin', 'classes.manage'),
+  ('system_admin', 'classes.manage');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM `permissions` WHERE `name` = 'classes.manage';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users` DROP FOREIGN KEY `users_class_fk`, DROP COLUMN `class_id`;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS classes;
+-- +goose StatementEnd
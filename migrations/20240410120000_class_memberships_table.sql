@@ -0,0 +1,27 @@
+-- +goose Up
+-- A row per stay of a student in a class; left_at is NULL for the class the
+-- student is in now.
+-- +goose StatementBegin
+CREATE TABLE `class_memberships` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `user_id` int NOT NULL,
+  `class_id` int NOT NULL,
+  `joined_at` datetime NOT NULL,
+  `left_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `user_id` (`user_id`, `joined_at`),
+  KEY `class_id` (`class_id`, `left_at`),
+  CONSTRAINT `class_memberships_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `class_memberships_class_fk` FOREIGN KEY (`class_id`) REFERENCES `classes` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `class_memberships` (`user_id`, `class_id`, `joined_at`)
+SELECT `id`, `class_id`, UTC_TIMESTAMP() FROM `users` WHERE `class_id` IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS class_memberships;
+-- +goose StatementEnd
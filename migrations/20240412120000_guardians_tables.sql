@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE `guardians` (
+  `guardian_id` int NOT NULL,
+  `student_id` int NOT NULL,
+  `relationship` enum('mother','father','guardian','other') NOT NULL DEFAULT 'guardian',
+  `is_primary` tinyint(1) NOT NULL DEFAULT '0',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`guardian_id`, `student_id`),
+  KEY `student_id` (`student_id`),
+  CONSTRAINT `guardians_guardian_fk` FOREIGN KEY (`guardian_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `guardians_student_fk` FOREIGN KEY (`student_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE `guardian_invites` (
+  `code_hash` char(64) NOT NULL,
+  `student_id` int NOT NULL,
+  `created_by` int NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `used_at` datetime DEFAULT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`code_hash`),
+  KEY `student_id` (`student_id`),
+  CONSTRAINT `guardian_invites_student_fk` FOREIGN KEY (`student_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `guardian_invites_created_by_fk` FOREIGN KEY (`created_by`) REFERENCES `users` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('guardians.manage', 'Link and unlink guardians of students');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('school_admin', 'guardians.manage'),
+  ('system_admin', 'guardians.manage');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM `permissions` WHERE `name` = 'guardians.manage';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS guardian_invites;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS guardians;
+-- +goose StatementEnd
@@ -0,0 +1,89 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE `schools` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `name` varchar(255) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb3;
+-- +goose StatementEnd
+
+-- The school every existing user and class belongs to. The service falls
+-- back to it when a call names no school.
+-- +goose StatementBegin
+INSERT INTO `schools` (`id`, `name`) VALUES (1, 'Default school');
+-- +goose StatementEnd
+
+-- Emails and class names are unique within a school only.
+-- +goose StatementBegin
+ALTER TABLE `users`
+  ADD COLUMN `school_id` int NOT NULL DEFAULT '1',
+  DROP INDEX `email`,
+  ADD UNIQUE KEY `school_id_email` (`school_id`, `email`),
+  ADD KEY `email` (`email`),
+  ADD CONSTRAINT `users_school_fk` FOREIGN KEY (`school_id`) REFERENCES `schools` (`id`);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `classes`
+  ADD COLUMN `school_id` int NOT NULL DEFAULT '1',
+  DROP INDEX `academic_year_grade_letter`,
+  ADD UNIQUE KEY `school_id_academic_year_grade_letter` (`school_id`, `academic_year`, `grade`, `letter`),
+  ADD CONSTRAINT `classes_school_fk` FOREIGN KEY (`school_id`) REFERENCES `schools` (`id`);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('schools.manage', 'Create schools and act in every school');
+-- +goose StatementEnd
+
+-- system_admin administers one school; super_admin works across schools
+-- and holds every permission.
+-- +goose StatementBegin
+INSERT INTO `roles` (`name`, `level`, `legacy_level`, `description`) VALUES
+  ('super_admin', 5, NULL, 'Administrator of every school of the deployment');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE `roles` SET `description` = 'Administrator of a school' WHERE `name` = 'system_admin';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`)
+SELECT 'super_admin', `name` FROM `permissions`;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM `roles` WHERE `name` = 'super_admin';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DELETE FROM `permissions` WHERE `name` = 'schools.manage';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE `roles` SET `description` = 'Administrator of the whole system' WHERE `name` = 'system_admin';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `classes`
+  DROP FOREIGN KEY `classes_school_fk`,
+  DROP INDEX `school_id_academic_year_grade_letter`,
+  ADD UNIQUE KEY `academic_year_grade_letter` (`academic_year`, `grade`, `letter`),
+  DROP COLUMN `school_id`;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users`
+  DROP FOREIGN KEY `users_school_fk`,
+  DROP INDEX `school_id_email`,
+  DROP INDEX `email`,
+  ADD UNIQUE KEY `email` (`email`),
+  DROP COLUMN `school_id`;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS schools;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('users.import', 'Create accounts in bulk from CSV and XLSX files');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('school_admin', 'users.import'),
+  ('system_admin', 'users.import'),
+  ('super_admin', 'users.import');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM `permissions` WHERE `name` = 'users.import';
+-- +goose StatementEnd
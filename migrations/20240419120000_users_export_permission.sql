@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('users.export', 'Export users of any class to CSV, XLSX and JSON Lines');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('school_admin', 'users.export'),
+  ('system_admin', 'users.export'),
+  ('super_admin', 'users.export');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM `permissions` WHERE `name` = 'users.export';
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Accounts created before the column existed get the time of the migration.
+-- +goose StatementBegin
+ALTER TABLE `users`
+  ADD COLUMN `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  ADD KEY `school_id_created_at` (`school_id`, `created_at`);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `permissions` (`name`, `description`) VALUES
+  ('users.read', 'List and search the users of the school');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO `role_permissions` (`role`, `permission`) VALUES
+  ('school_admin', 'users.read'),
+  ('system_admin', 'users.read'),
+  ('super_admin', 'users.read');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM `permissions` WHERE `name` = 'users.read';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users` DROP KEY `school_id_created_at`, DROP COLUMN `created_at`;
+-- +goose StatementEnd
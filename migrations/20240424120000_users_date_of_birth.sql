@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE `users`
+  ADD COLUMN `birth_date` date DEFAULT NULL AFTER `date_of_birth`,
+  ADD COLUMN `date_of_birth_raw` varchar(10) DEFAULT NULL AFTER `birth_date`;
+-- +goose StatementEnd
+
+-- Dates were stored as 2010-02-01 or 01.02.2010. IGNORE turns impossible
+-- dates such as 2010-02-30 into NULL instead of failing.
+-- +goose StatementBegin
+UPDATE IGNORE `users` SET `birth_date` = CASE
+  WHEN `date_of_birth` REGEXP '^[0-9]{4}-[0-9]{2}-[0-9]{2}$' THEN STR_TO_DATE(`date_of_birth`, '%Y-%m-%d')
+  WHEN `date_of_birth` REGEXP '^[0-9]{1,2}[.][0-9]{1,2}[.][0-9]{4}$' THEN STR_TO_DATE(`date_of_birth`, '%d.%m.%Y')
+END;
+-- +goose StatementEnd
+
+-- Values that are no date are kept as they were in date_of_birth_raw, so
+-- that they can be fixed by hand and the Down migration restores them.
+-- +goose StatementBegin
+UPDATE `users` SET `date_of_birth_raw` = `date_of_birth`
+WHERE `birth_date` IS NULL AND TRIM(COALESCE(`date_of_birth`, '')) <> '';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users`
+  DROP COLUMN `date_of_birth`,
+  CHANGE COLUMN `birth_date` `date_of_birth` date DEFAULT NULL;
+-- +goose StatementEnd
+
+-- Consent of a parent given outside the service, such as on paper, and who
+-- recorded it.
+-- +goose StatementBegin
+ALTER TABLE `users`
+  ADD COLUMN `parental_consent_at` datetime DEFAULT NULL,
+  ADD COLUMN `parental_consent_by` int DEFAULT NULL,
+  ADD CONSTRAINT `users_parental_consent_by_fk` FOREIGN KEY (`parental_consent_by`) REFERENCES `users` (`id`) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- A linked guardian counts as consent once a holder of guardians.manage
+-- approved the link. Links made before cannot tell who made them and start
+-- unapproved.
+-- +goose StatementBegin
+ALTER TABLE `guardians`
+  ADD COLUMN `approved_by` int DEFAULT NULL,
+  ADD COLUMN `approved_at` datetime DEFAULT NULL,
+  ADD CONSTRAINT `guardians_approved_by_fk` FOREIGN KEY (`approved_by`) REFERENCES `users` (`id`) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE `guardians`
+  DROP FOREIGN KEY `guardians_approved_by_fk`,
+  DROP COLUMN `approved_at`,
+  DROP COLUMN `approved_by`;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users`
+  DROP FOREIGN KEY `users_parental_consent_by_fk`,
+  DROP COLUMN `parental_consent_by`,
+  DROP COLUMN `parental_consent_at`;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users` MODIFY COLUMN `date_of_birth` varchar(10) DEFAULT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE `users` SET `date_of_birth` = `date_of_birth_raw` WHERE `date_of_birth_raw` IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE `users` DROP COLUMN `date_of_birth_raw`;
+-- +goose StatementEnd
@@ -0,0 +1,84 @@
+// Package birthdate parses and checks dates of birth. Dates are midnight
+// UTC of the day; the zero time means that the date is not known.
+package birthdate
+
+import (
+	"errors"
+	"time"
+)
+
+// Layout is the ISO 8601 calendar date, 2010-09-01.
+const Layout = "2006-01-02"
+
+// MaxAge is the age above which a date of birth is taken for a typo.
+const MaxAge = 120
+
+var (
+	ErrMalformed = errors.New("must be a date such as 2010-09-01")
+	ErrNotADate  = errors.New("is not a calendar date")
+	ErrFuture    = errors.New("is in the future")
+	ErrTooOld    = errors.New("is too far in the past")
+)
+
+// Parse parses an ISO 8601 calendar date. Impossible dates such as
+// 2010-02-30 are rejected with ErrNotADate.
+func Parse(s string) (time.Time, error) {
+	t, err := time.Parse(Layout, s)
+	if err != nil {
+		var parseErr *time.ParseError
+		if errors.As(err, &parseErr) && parseErr.Message != "" {
+			// The layout matched, but a day or month is out of range.
+			return time.Time{}, ErrNotADate
+		}
+
+		return time.Time{}, ErrMalformed
+	}
+
+	return t, nil
+}
+
+// FromParts builds a date from its year, month and day, which google.type.Date
+// carries separately. Days and months that do not exist are rejected rather
+// than normalized into the next month.
+func FromParts(year, month, day int) (time.Time, error) {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if year < 1 || t.Year() != year || int(t.Month()) != month || t.Day() != day {
+		return time.Time{}, ErrNotADate
+	}
+
+	return t, nil
+}
+
+// Check returns an error unless d is a plausible date of birth on the day
+// of now.
+func Check(d, now time.Time) error {
+	if d.After(now) {
+		return ErrFuture
+	}
+
+	if Age(d, now) > MaxAge {
+		return ErrTooOld
+	}
+
+	return nil
+}
+
+// Age returns the age in full years on the day of now of someone born on d.
+func Age(d, now time.Time) int {
+	age := now.Year() - d.Year()
+	if now.Month() < d.Month() || now.Month() == d.Month() && now.Day() < d.Day() {
+		age--
+	}
+
+	return age
+}
+
+// Format returns d in the ISO 8601 format, or an empty string for the zero
+// time.
+func Format(d time.Time) string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return d.Format(Layout)
+}
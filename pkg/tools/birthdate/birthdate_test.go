@@ -0,0 +1,91 @@
+package birthdate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAge(t *testing.T) {
+	tests := []struct {
+		name string
+		born time.Time
+		now  time.Time
+		want int
+	}{
+		{name: "day of birth", born: day(2010, time.May, 10), now: day(2010, time.May, 10), want: 0},
+		{name: "day before the birthday", born: day(2010, time.May, 10), now: day(2024, time.May, 9), want: 13},
+		{name: "birthday", born: day(2010, time.May, 10), now: day(2024, time.May, 10), want: 14},
+		{name: "month before the birthday", born: day(2010, time.May, 10), now: day(2024, time.April, 30), want: 13},
+		{name: "month after the birthday", born: day(2010, time.May, 10), now: day(2024, time.June, 1), want: 14},
+		{name: "end of year", born: day(2010, time.December, 31), now: day(2024, time.December, 30), want: 13},
+		{name: "time of day is ignored", born: day(2010, time.May, 10), now: day(2024, time.May, 10).Add(23 * time.Hour), want: 14},
+		// Those born on February 29 have their birthday on March 1 in
+		// common years and on February 29 in leap years.
+		{name: "Feb 29, Feb 28 of a common year", born: day(2012, time.February, 29), now: day(2025, time.February, 28), want: 12},
+		{name: "Feb 29, Mar 1 of a common year", born: day(2012, time.February, 29), now: day(2025, time.March, 1), want: 13},
+		{name: "Feb 29, Feb 28 of a leap year", born: day(2012, time.February, 29), now: day(2024, time.February, 28), want: 11},
+		{name: "Feb 29, Feb 29 of a leap year", born: day(2012, time.February, 29), now: day(2024, time.February, 29), want: 12},
+		{name: "Feb 28 in a leap year", born: day(2011, time.February, 28), now: day(2024, time.February, 28), want: 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Age(tt.born, tt.now))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr error
+	}{
+		{in: "2010-09-01", want: day(2010, time.September, 1)},
+		{in: "2012-02-29", want: day(2012, time.February, 29)},
+		{in: "2011-02-29", wantErr: ErrNotADate},
+		{in: "2010-13-01", wantErr: ErrNotADate},
+		{in: "01.09.2010", wantErr: ErrMalformed},
+		{in: "2010-9-1", wantErr: ErrMalformed},
+		{in: "", wantErr: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFromParts(t *testing.T) {
+	got, err := FromParts(2012, 2, 29)
+	require.NoError(t, err)
+	assert.Equal(t, day(2012, time.February, 29), got)
+
+	for _, parts := range [][3]int{{2011, 2, 29}, {2010, 4, 31}, {2010, 0, 1}, {2010, 1, 0}, {0, 1, 1}} {
+		_, err = FromParts(parts[0], parts[1], parts[2])
+		assert.ErrorIs(t, err, ErrNotADate, parts)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := day(2024, time.May, 10)
+
+	assert.NoError(t, Check(now, now))
+	assert.NoError(t, Check(day(1903, time.May, 11), now))
+	assert.ErrorIs(t, Check(day(2024, time.May, 11), now), ErrFuture)
+	assert.ErrorIs(t, Check(day(1903, time.May, 10), now), ErrTooOld)
+}
@@ -1,19 +1,158 @@
 package hash
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
-func HashPass(pass string) ([]byte, error) {
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
 
-	bytes, err := bcrypt.GenerateFromPassword([]byte(pass), 5)
-	if err != nil {
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+var ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+
+// Params is the hashing policy for new passwords.
+type Params struct {
+	Algorithm  string
+	BcryptCost int
+	// Argon2Memory is in KiB.
+	Argon2Memory      uint32
+	Argon2Time        uint32
+	Argon2Parallelism uint8
+}
+
+// Hasher hashes passwords with the configured algorithm. The hashes are
+// self-describing (modular crypt format for bcrypt, PHC string format for
+// argon2id), so hashes of older policies keep verifying and NeedsRehash can
+// tell when one should be upgraded.
+type Hasher struct {
+	params Params
+}
+
+func New(params Params) (*Hasher, error) {
+	switch params.Algorithm {
+	case Bcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case Argon2id:
+		if params.Argon2Memory == 0 || params.Argon2Time == 0 || params.Argon2Parallelism == 0 {
+			return nil, errors.New("argon2id memory, time and parallelism must be positive")
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, params.Algorithm)
+	}
+
+	return &Hasher{params: params}, nil
+}
+
+func (h *Hasher) Hash(pass string) ([]byte, error) {
+	if h.params.Algorithm == Bcrypt {
+		return bcrypt.GenerateFromPassword([]byte(pass), h.params.BcryptCost)
+	}
+
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
 		return nil, err
 	}
 
-	return bytes, nil
+	p := argon2Params{
+		memory:      h.params.Argon2Memory,
+		time:        h.params.Argon2Time,
+		parallelism: h.params.Argon2Parallelism,
+	}
+	key := argon2.IDKey([]byte(pass), salt, p.time, p.memory, p.parallelism, argon2KeyLen)
+
+	return []byte(fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.time, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)), nil
+}
+
+// Check reports whether pass matches hash, whatever policy produced it.
+func (h *Hasher) Check(pass string, hash []byte) bool {
+	return CheckPass(pass, hash)
 }
 
+// NeedsRehash reports whether hash was produced by another algorithm or
+// with other parameters than the current policy.
+func (h *Hasher) NeedsRehash(hash []byte) bool {
+	switch h.params.Algorithm {
+	case Bcrypt:
+		cost, err := bcrypt.Cost(hash)
+
+		return err != nil || cost != h.params.BcryptCost
+	case Argon2id:
+		p, _, _, err := parseArgon2(string(hash))
+
+		return err != nil ||
+			p.memory != h.params.Argon2Memory ||
+			p.time != h.params.Argon2Time ||
+			p.parallelism != h.params.Argon2Parallelism
+	}
+
+	return true
+}
+
+// CheckPass reports whether pass matches a bcrypt or argon2id hash.
 func CheckPass(pass string, hash []byte) bool {
-	err := bcrypt.CompareHashAndPassword(hash, []byte(pass))
+	if !strings.HasPrefix(string(hash), "$argon2id$") {
+		return bcrypt.CompareHashAndPassword(hash, []byte(pass)) == nil
+	}
+
+	p, salt, key, err := parseArgon2(string(hash))
+	if err != nil {
+		return false
+	}
+
+	other := argon2.IDKey([]byte(pass), salt, p.time, p.memory, p.parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, other) == 1
+}
+
+type argon2Params struct {
+	memory      uint32
+	time        uint32
+	parallelism uint8
+}
+
+func parseArgon2(hash string) (argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != Argon2id {
+		return argon2Params{}, nil, nil, errors.New("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, errors.New("unsupported argon2id version")
+	}
+
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.parallelism); err != nil {
+		return argon2Params{}, nil, nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return argon2Params{}, nil, nil, err
+	}
 
-	return err == nil
+	return p, salt, key, nil
 }
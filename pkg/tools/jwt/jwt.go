@@ -3,64 +3,215 @@ package jwt
 import (
 	"AuthService/internal/models"
 	"errors"
+	"fmt"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// Every validation error wraps ErrBadJWT, so callers that do not care about
+// the reason can keep checking for it alone.
 var (
-	ErrBadJWT     = errors.New("incorrect JWT")
-	ErrJWTExpired = errors.New("JWT is expired")
+	ErrBadJWT          = errors.New("incorrect JWT")
+	ErrJWTMalformed    = fmt.Errorf("%w: token is malformed", ErrBadJWT)
+	ErrJWTBadSignature = fmt.Errorf("%w: signature is invalid", ErrBadJWT)
+	ErrJWTBadAlgorithm = fmt.Errorf("%w: signing algorithm is not allowed", ErrBadJWT)
+	ErrJWTExpired      = fmt.Errorf("%w: token is expired", ErrBadJWT)
+	ErrJWTNotValidYet  = fmt.Errorf("%w: token is not valid yet", ErrBadJWT)
+	ErrJWTBadIssuer    = fmt.Errorf("%w: issuer is not trusted", ErrBadJWT)
+	ErrJWTBadAudience  = fmt.Errorf("%w: audience is not allowed", ErrBadJWT)
+	ErrJWTMissingClaim = fmt.Errorf("%w: required claim is missing", ErrBadJWT)
 )
 
+// JwtWrapper signs tokens with the current key of Keys, or with SecretKey
+// using HS256 when no key ring is configured.
+//
+// Tokens are issued for Issuer and Audience. ValidateToken accepts only
+// tokens from Issuer that name one of AllowedAudiences and are signed with
+// one of AllowedAlgorithms, tolerating Leeway of clock skew. When
+// AllowedAudiences or AllowedAlgorithms are empty, Audience and the
+// algorithms of the configured keys are used instead.
 type JwtWrapper struct {
-	SecretKey       string
-	Issuer          string
-	ExpirationHours int64
+	SecretKey         string
+	Keys              *KeyRing
+	Issuer            string
+	Audience          []string
+	AllowedAudiences  []string
+	AllowedAlgorithms []string
+	Leeway            time.Duration
+	TokenTTL          time.Duration
 }
 
-type jwtClaims struct {
+type Claims struct {
 	jwt.RegisteredClaims
-	Uid         int64
-	Email       string
-	Permissions int64
+	Uid         int64  `json:"uid"`
+	Email       string `json:"email"`
+	Permissions int64  `json:"permissions"`
+	SessionID   string `json:"sid,omitempty"`
+	SchoolID    int64  `json:"school_id,omitempty"`
 }
 
-func (w *JwtWrapper) NewToken(user models.User) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+// NewToken issues an access token for the user. sessionID ties the token to
+// the login session it was issued for.
+func (w *JwtWrapper) NewToken(user models.User, sessionID string) (string, error) {
+	now := time.Now()
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["uid"] = user.ID
-	claims["email"] = user.Email
-	claims["permissions"] = user.PermissionLevel
-	claims["exp"] = time.Now().Local().Add(time.Hour * time.Duration(w.ExpirationHours)).Unix()
-	claims["issuer"] = w.Issuer
-	tokenString, err := token.SignedString([]byte(w.SecretKey))
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    w.Issuer,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			Audience:  w.Audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(w.TokenTTL)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+		Uid:         user.ID,
+		Email:       user.Email,
+		Permissions: user.PermissionLevel,
+		SessionID:   sessionID,
+		SchoolID:    user.SchoolID,
+	}
+
+	if w.Keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(w.SecretKey))
+	}
+
+	key, err := w.Keys.SigningKey(now)
 	if err != nil {
 		return "", err
 	}
 
-	return tokenString, nil
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.Private)
 }
 
-func (w *JwtWrapper) ValidateToken(signedToken string) (*jwtClaims, error) {
+func (w *JwtWrapper) ValidateToken(signedToken string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
-		&jwtClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(w.SecretKey), nil
-		},
+		&Claims{},
+		w.verificationKey,
+		jwt.WithIssuer(w.Issuer),
+		jwt.WithLeeway(w.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
 	)
 
 	if err != nil {
-		return nil, ErrBadJWT
+		return nil, validationError(err)
 	}
 
-	claims, ok := token.Claims.(*jwtClaims)
+	claims, ok := token.Claims.(*Claims)
 
 	if !ok {
 		return nil, errors.New("Couldn't parse claims")
 	}
 
+	if claims.Subject != strconv.FormatInt(claims.Uid, 10) || claims.ID == "" {
+		return nil, ErrJWTMissingClaim
+	}
+
+	if !w.audienceAllowed(claims.Audience) {
+		return nil, ErrJWTBadAudience
+	}
+
 	return claims, nil
 }
+
+// verificationKey picks the key named by the kid header and makes sure the
+// token algorithm is allowed and is the one that key signs with.
+func (w *JwtWrapper) verificationKey(token *jwt.Token) (interface{}, error) {
+	if !slices.Contains(w.allowedAlgorithms(), token.Method.Alg()) {
+		return nil, ErrJWTBadAlgorithm
+	}
+
+	if w.Keys == nil {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, ErrJWTBadAlgorithm
+		}
+		return []byte(w.SecretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := w.Keys.VerificationKey(kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJWTBadSignature, err)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrJWTBadAlgorithm
+	}
+
+	return key.Public, nil
+}
+
+func (w *JwtWrapper) allowedAlgorithms() []string {
+	if len(w.AllowedAlgorithms) > 0 {
+		return w.AllowedAlgorithms
+	}
+
+	if w.Keys == nil {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+
+	var algs []string
+	for _, key := range w.Keys.Keys() {
+		algs = append(algs, key.Method.Alg())
+	}
+
+	return algs
+}
+
+func (w *JwtWrapper) audienceAllowed(audience jwt.ClaimStrings) bool {
+	allowed := w.AllowedAudiences
+	if len(allowed) == 0 {
+		allowed = w.Audience
+	}
+
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, aud := range audience {
+		if slices.Contains(allowed, aud) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validationError maps the errors of the jwt library to the errors of this
+// package, so that callers do not depend on the library.
+func validationError(err error) error {
+	switch {
+	case errors.Is(err, ErrBadJWT):
+		// Returned from verificationKey; the library wraps it.
+		for _, known := range []error{ErrJWTBadAlgorithm, ErrJWTBadSignature} {
+			if errors.Is(err, known) {
+				return known
+			}
+		}
+		return ErrBadJWT
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrJWTMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrJWTBadSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrJWTExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrJWTNotValidYet
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrJWTBadIssuer
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return ErrJWTMissingClaim
+	}
+
+	return ErrBadJWT
+}
@@ -0,0 +1,267 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoSigningKey   = errors.New("no signing key available")
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+// Key is a private signing key loaded from a PEM file. The key ID is the
+// file name without its extension.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+	CreatedAt time.Time
+
+	retiredAt time.Time
+}
+
+// KeyRing holds the keys found in a directory of PEM files and decides
+// which one signs new tokens.
+//
+// A key becomes the signing key once it has been published in the JWKS for
+// the overlap window, so that verifiers get a chance to fetch it first; the
+// key creation time is the file modification time, which every replica sees
+// the same way. A key whose file disappears keeps verifying tokens for the
+// overlap window, which should be at least as long as the token TTL.
+type KeyRing struct {
+	dir     string
+	overlap time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]*Key
+}
+
+func LoadKeyRing(dir string, overlap time.Duration) (*KeyRing, error) {
+	r := &KeyRing{
+		dir:     dir,
+		overlap: overlap,
+		keys:    make(map[string]*Key),
+	}
+
+	if err := r.Reload(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload re-reads the key directory, adding new keys and retiring the keys
+// whose files were removed.
+func (r *KeyRing) Reload(now time.Time) error {
+	paths, err := filepath.Glob(filepath.Join(r.dir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("failed to list keys due to error: %w", err)
+	}
+
+	loaded := make(map[string]*Key, len(paths))
+	for _, path := range paths {
+		key, err := loadKey(path)
+		if err != nil {
+			return fmt.Errorf("failed to load key %s due to error: %w", path, err)
+		}
+		loaded[key.ID] = key
+	}
+
+	if len(loaded) == 0 {
+		return fmt.Errorf("failed to load keys from %s: %w", r.dir, ErrNoSigningKey)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, key := range r.keys {
+		if _, ok := loaded[id]; ok {
+			continue
+		}
+		if key.retiredAt.IsZero() {
+			key.retiredAt = now
+		}
+		if now.Sub(key.retiredAt) < r.overlap {
+			loaded[id] = key
+		}
+	}
+
+	r.keys = loaded
+
+	return nil
+}
+
+// SigningKey returns the newest key that has been published for at least the
+// overlap window, or the oldest key if none has been published that long.
+func (r *KeyRing) SigningKey(now time.Time) (*Key, error) {
+	keys := r.active()
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		if !keys[i].CreatedAt.Add(r.overlap).After(now) {
+			return keys[i], nil
+		}
+	}
+
+	return keys[0], nil
+}
+
+// VerificationKey returns the key with the given ID, including retired keys
+// that are still inside the overlap window.
+func (r *KeyRing) VerificationKey(kid string) (*Key, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// Keys returns every key that can verify tokens, oldest first.
+func (r *KeyRing) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+
+	return keys
+}
+
+// Run reloads the key directory every interval until ctx is cancelled.
+func (r *KeyRing) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.Reload(now); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (r *KeyRing) active() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		if key.retiredAt.IsZero() {
+			keys = append(keys, key)
+		}
+	}
+	sortKeys(keys)
+
+	return keys
+}
+
+func sortKeys(keys []*Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+}
+
+func loadKey(path string) (*Key, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var private any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	method, err := signingMethod(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		ID:        strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Method:    method,
+		Private:   signer,
+		Public:    signer.Public(),
+		CreatedAt: info.ModTime(),
+	}, nil
+}
+
+func signingMethod(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
@@ -0,0 +1,43 @@
+// Package names checks the names of people: first names, last names and
+// patronymics.
+package names
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength is the size of the name columns of the users table.
+const MaxLength = 20
+
+var (
+	ErrRequired   = errors.New("is required")
+	ErrTooLong    = fmt.Errorf("is longer than %d characters", MaxLength)
+	ErrCharacters = errors.New("may only hold letters, spaces, hyphens and apostrophes")
+)
+
+// Check returns why name is not valid, or nil. An empty name is only
+// rejected when it is required.
+func Check(name string, required bool) error {
+	if name == "" {
+		if required {
+			return ErrRequired
+		}
+
+		return nil
+	}
+
+	if utf8.RuneCountInString(name) > MaxLength {
+		return ErrTooLong
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' && r != '\'' {
+			return ErrCharacters
+		}
+	}
+
+	return nil
+}
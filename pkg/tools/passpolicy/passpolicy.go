@@ -0,0 +1,165 @@
+package passpolicy
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Rule names a password requirement. Rules are reported to clients, so the
+// values must stay stable.
+type Rule string
+
+const (
+	RuleMinLength      Rule = "MIN_LENGTH"
+	RuleCharClasses    Rule = "CHARACTER_CLASSES"
+	RulePersonalInfo   Rule = "PERSONAL_INFO"
+	RuleCommonPassword Rule = "COMMON_PASSWORD"
+	RuleReused         Rule = "PASSWORD_REUSED"
+)
+
+// minPersonalLen is the shortest email local part or name that passwords
+// may not contain; shorter ones match too many passwords by accident.
+const minPersonalLen = 3
+
+//go:embed common-passwords.txt
+var bundled []byte
+
+// Violation is one broken rule.
+type Violation struct {
+	Rule    Rule
+	Message string
+}
+
+// Policy checks new passwords. MinClasses counts lowercase, uppercase,
+// digits and other characters.
+type Policy struct {
+	MinLength  int
+	MinClasses int
+
+	common map[string]struct{}
+}
+
+func New(minLength, minClasses int, common []string) *Policy {
+	p := &Policy{
+		MinLength:  minLength,
+		MinClasses: minClasses,
+		common:     make(map[string]struct{}, len(common)),
+	}
+
+	for _, pass := range common {
+		p.common[strings.ToLower(pass)] = struct{}{}
+	}
+
+	return p
+}
+
+// LoadCommonPasswords reads a list of common passwords, one per line; lines
+// starting with # are comments. An empty path loads the bundled list.
+func LoadCommonPasswords(path string) ([]string, error) {
+	if path == "" {
+		return readList(bytes.NewReader(bundled))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open password list due to error: %w", err)
+	}
+	defer f.Close()
+
+	return readList(f)
+}
+
+func readList(r io.Reader) ([]string, error) {
+	var list []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list = append(list, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read password list due to error: %w", err)
+	}
+
+	return list, nil
+}
+
+// Check returns every rule the password breaks. personal holds the email
+// and names of the user, which the password must not contain.
+func (p *Policy) Check(password string, personal ...string) []Violation {
+	var violations []Violation
+
+	if n := len([]rune(password)); n < p.MinLength {
+		violations = append(violations, Violation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength),
+		})
+	}
+
+	if classes(password) < p.MinClasses {
+		violations = append(violations, Violation{
+			Rule:    RuleCharClasses,
+			Message: fmt.Sprintf("password must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", p.MinClasses),
+		})
+	}
+
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if at := strings.IndexByte(value, '@'); at >= 0 {
+			value = value[:at]
+		}
+
+		if len([]rune(value)) >= minPersonalLen && strings.Contains(lower, value) {
+			violations = append(violations, Violation{
+				Rule:    RulePersonalInfo,
+				Message: "password must not contain your email or name",
+			})
+			break
+		}
+	}
+
+	if _, ok := p.common[lower]; ok {
+		violations = append(violations, Violation{
+			Rule:    RuleCommonPassword,
+			Message: "password is too common",
+		})
+	}
+
+	return violations
+}
+
+func classes(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			n++
+		}
+	}
+
+	return n
+}
@@ -2,7 +2,13 @@ package test
 
 import (
 	"AuthService/internal/pb"
+	"AuthService/internal/services/imports"
+	"AuthService/pkg/tools/totp"
 	"AuthService/test/testsuite"
+	"context"
+	"errors"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +16,11 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/type/date"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 func TestRegisterLogin_Login_HappyPath(t *testing.T) {
@@ -33,6 +44,7 @@ func TestRegisterLogin_Login_HappyPath(t *testing.T) {
 
 	token := respLogin.GetToken()
 	require.NotEmpty(t, token)
+	require.NotEmpty(t, respLogin.GetRefreshToken())
 
 	loginTime := time.Now()
 
@@ -46,10 +58,80 @@ func TestRegisterLogin_Login_HappyPath(t *testing.T) {
 
 	assert.Equal(t, respReg.UserId, int64(claims["uid"].(float64)))
 	assert.Equal(t, email, claims["email"].(string))
+	assert.Equal(t, ts.Cfg.JWTIssuer, claims["iss"].(string))
+	assert.NotEmpty(t, claims["jti"])
 
 	const deltaSeconds = 1
 
-	assert.InDelta(t, loginTime.Add(24*180*time.Hour).Unix(), claims["exp"].(float64), deltaSeconds)
+	assert.InDelta(t, loginTime.Add(ts.Cfg.AccessTokenTTL).Unix(), claims["exp"].(float64), deltaSeconds)
+	assert.InDelta(t, loginTime.Add(ts.Cfg.AccessTokenTTL).Unix(), respLogin.GetExpiresAt(), deltaSeconds)
+}
+
+func TestRefreshToken_Rotation(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respRefresh, err := ts.AuthClient.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: respLogin.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, respRefresh.GetToken())
+	assert.NotEqual(t, respLogin.GetRefreshToken(), respRefresh.GetRefreshToken())
+
+	_, err = ts.AuthClient.Validate(ctx, &pb.ValidateRequest{
+		Token: respRefresh.GetToken(),
+	})
+	require.NoError(t, err)
+}
+
+func TestRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respRefresh, err := ts.AuthClient.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: respLogin.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: respLogin.GetRefreshToken(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "refresh token was already used")
+
+	_, err = ts.AuthClient.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: respRefresh.GetRefreshToken(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid refresh token")
 }
 
 func TestRegisterLogin_DuplicatedRegistration(t *testing.T) {
@@ -116,6 +198,30 @@ func TestRegister_FailCases(t *testing.T) {
 	}
 }
 
+func TestRegister_WeakPassword(t *testing.T) {
+	ctx, st := testsuite.New(t)
+
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{name: "Too short", password: "aB1!"},
+		{name: "Single character class", password: "abcdefghijkl"},
+		{name: "Common password", password: "Password1!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := st.AuthClient.Register(ctx, &pb.RegisterRequest{
+				Email:    gofakeit.Email(),
+				Password: tt.password,
+			})
+			require.Error(t, err)
+			assert.ErrorContains(t, err, "password does not meet the policy")
+		})
+	}
+}
+
 func TestLogin_FailCases(t *testing.T) {
 	ctx, st := testsuite.New(t)
 
@@ -170,50 +276,1603 @@ func TestLogin_FailCases(t *testing.T) {
 	}
 }
 
-func TestValidate_FailCases(t *testing.T) {
+func TestLogout_RevokesTokens(t *testing.T) {
 	ctx, ts := testsuite.New(t)
 
-	token := jwt.New(jwt.SigningMethodHS256)
+	email := gofakeit.Email()
+	pass := randomFakePassword()
 
-	claims := token.Claims.(jwt.MapClaims)
-	claims["uid"] = gofakeit.Uint64()
-	claims["email"] = gofakeit.Email()
-	claims["exp"] = time.Now().Local().Add(-1 * time.Hour).Unix()
-	claims["issuer"] = "go-grpc-auth-svc"
-	tokenString, _ := token.SignedString([]byte(ts.Cfg.JWTSecretKey))
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
 
-	tests := []struct {
-		name        string
-		token       string
-		expectedErr string
-	}{
-		{
-			name:        "Login with Empty token",
-			token:       "",
-			expectedErr: "token is required",
-		},
-		{
-			name:        "Login with Wrong token",
-			token:       "example wrong jwt",
-			expectedErr: "invalid JWT",
-		},
-		{
-			name:        "Login with Expired token",
-			token:       tokenString,
-			expectedErr: "invalid JWT",
-		},
-	}
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			_, err := ts.AuthClient.Validate(ctx, &pb.ValidateRequest{
-				Token: tt.token,
-			})
-			require.Error(t, err)
-			require.Contains(t, err.Error(), tt.expectedErr)
-		})
+	_, err = ts.AuthClient.Logout(ctx, &pb.LogoutRequest{
+		Token:        respLogin.GetToken(),
+		RefreshToken: respLogin.GetRefreshToken(),
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.Validate(ctx, &pb.ValidateRequest{
+		Token: respLogin.GetToken(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "token has been revoked")
+
+	_, err = ts.AuthClient.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: respLogin.GetRefreshToken(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid refresh token")
+
+	// A revoked token cannot log out again.
+	_, err = ts.AuthClient.Logout(ctx, &pb.LogoutRequest{
+		Token: respLogin.GetToken(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestSessions_ListAndTerminate(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respPhone, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:       email,
+		Password:    pass,
+		DeviceLabel: "phone",
+	})
+	require.NoError(t, err)
+
+	respTablet, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:       email,
+		Password:    pass,
+		DeviceLabel: "tablet",
+	})
+	require.NoError(t, err)
+
+	respList, err := ts.AuthClient.ListSessions(ctx, &pb.ListSessionsRequest{
+		Token: respPhone.GetToken(),
+	})
+	require.NoError(t, err)
+	require.Len(t, respList.GetSessions(), 2)
+
+	var tabletSession string
+	for _, session := range respList.GetSessions() {
+		if session.GetDeviceLabel() == "tablet" {
+			tabletSession = session.GetSessionId()
+			assert.False(t, session.GetCurrent())
+		} else {
+			assert.True(t, session.GetCurrent())
+		}
 	}
+	require.NotEmpty(t, tabletSession)
+
+	_, err = ts.AuthClient.TerminateSession(ctx, &pb.TerminateSessionRequest{
+		Token:     respPhone.GetToken(),
+		SessionId: tabletSession,
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.Validate(ctx, &pb.ValidateRequest{
+		Token: respTablet.GetToken(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "token has been revoked")
+
+	_, err = ts.AuthClient.Validate(ctx, &pb.ValidateRequest{
+		Token: respPhone.GetToken(),
+	})
+	require.NoError(t, err)
 }
-func randomFakePassword() string {
-	return gofakeit.Password(true, true, true, true, false, 10)
+
+func TestIntrospect(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	respReg, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respActive, err := ts.AuthClient.Introspect(ctx, &pb.IntrospectRequest{
+		Token: respLogin.GetToken(),
+	})
+	require.NoError(t, err)
+	assert.True(t, respActive.GetActive())
+	assert.Equal(t, respReg.GetUserId(), respActive.GetUserId())
+	assert.Equal(t, email, respActive.GetEmail())
+	assert.NotEmpty(t, respActive.GetJti())
+	assert.NotEmpty(t, respActive.GetSessionId())
+	assert.InDelta(t, respLogin.GetExpiresAt(), respActive.GetExp(), 1)
+
+	respInactive, err := ts.AuthClient.Introspect(ctx, &pb.IntrospectRequest{
+		Token: "example wrong jwt",
+	})
+	require.NoError(t, err)
+	assert.False(t, respInactive.GetActive())
+	assert.Empty(t, respInactive.GetUserId())
+}
+
+func TestPasswordReset_DoesNotRevealAccounts(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	respKnown, err := ts.AuthClient.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{
+		Email: email,
+	})
+	require.NoError(t, err)
+
+	respUnknown, err := ts.AuthClient.RequestPasswordReset(ctx, &pb.RequestPasswordResetRequest{
+		Email: gofakeit.Email(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, respKnown.GetStatus(), respUnknown.GetStatus())
+
+	_, err = ts.AuthClient.ConfirmPasswordReset(ctx, &pb.ConfirmPasswordResetRequest{
+		Token:       "example wrong token",
+		NewPassword: randomFakePassword(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "reset token is invalid or expired")
+}
+
+func TestLogin_BacksOffAfterFailure(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "incorrect email or password")
+
+	_, err = ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "too many login attempts")
+}
+
+func TestMFA_EnrollAndLoginWithRecoveryCode(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respBegin, err := ts.AuthClient.BeginTOTPEnrollment(ctx, &pb.BeginTOTPEnrollmentRequest{
+		Token: respLogin.GetToken(),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, respBegin.GetOtpauthUri(), "otpauth://totp/")
+
+	code, err := totp.Code(respBegin.GetSecret(), totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	respConfirm, err := ts.AuthClient.ConfirmTOTPEnrollment(ctx, &pb.ConfirmTOTPEnrollmentRequest{
+		Token: respLogin.GetToken(),
+		Code:  code,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, respConfirm.GetRecoveryCodes())
+
+	respLogin, err = ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, respLogin.GetToken())
+	require.NotEmpty(t, respLogin.GetMfaChallengeId())
+
+	respMFA, err := ts.AuthClient.CompleteMFALogin(ctx, &pb.CompleteMFALoginRequest{
+		MfaChallengeId: respLogin.GetMfaChallengeId(),
+		Code:           respConfirm.GetRecoveryCodes()[0],
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, respMFA.GetToken())
+
+	_, err = ts.AuthClient.CompleteMFALogin(ctx, &pb.CompleteMFALoginRequest{
+		MfaChallengeId: respLogin.GetMfaChallengeId(),
+		Code:           respConfirm.GetRecoveryCodes()[1],
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "mfa challenge is invalid or expired")
+}
+
+func TestEmailVerification_BadTokenAndResend(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+
+	_, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	respKnown, err := ts.AuthClient.ResendVerification(ctx, &pb.ResendVerificationRequest{
+		Email: email,
+	})
+	require.NoError(t, err)
+
+	respUnknown, err := ts.AuthClient.ResendVerification(ctx, &pb.ResendVerificationRequest{
+		Email: gofakeit.Email(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, respKnown.GetStatus(), respUnknown.GetStatus())
+
+	_, err = ts.AuthClient.VerifyEmail(ctx, &pb.VerifyEmailRequest{
+		Token: "example wrong token",
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "verification token is invalid or expired")
+}
+
+func TestRBAC_NewUserIsStudent(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	user := loginAs(ctx, ts)
+
+	respRoles, err := ts.AuthClient.ListRoles(ctx, &pb.ListRolesRequest{
+		Token:  user.Token,
+		UserId: user.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, respRoles.GetRoles(), 1)
+	assert.Equal(t, "student", respRoles.GetRoles()[0].GetName())
+
+	respCheck, err := ts.AuthClient.CheckPermission(ctx, &pb.CheckPermissionRequest{
+		Token:      user.Token,
+		Permission: "users.delete",
+	})
+	require.NoError(t, err)
+	assert.False(t, respCheck.GetAllowed())
+
+	_, err = ts.AuthClient.AssignRole(ctx, &pb.AssignRoleRequest{
+		Token:  user.Token,
+		UserId: user.ID,
+		Role:   "system_admin",
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+func TestSetPermissionLevel_InitiatorFromToken(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	respReg, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.SetPermissionLevel(ctx, &pb.SetPermissionLevelRequest{
+		UserId:          respReg.GetUserId(),
+		PermissionLevel: 4,
+		InitiatorId:     respReg.GetUserId(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "authentication is required")
+
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+respLogin.GetToken())
+
+	_, err = ts.AuthClient.SetPermissionLevel(authCtx, &pb.SetPermissionLevelRequest{
+		UserId:          respReg.GetUserId(),
+		PermissionLevel: 4,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+func TestSetPermissionLevel_CappedAtOwnRank(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	admin := loginAs(ctx, ts, "school_admin")
+	user := loginAs(ctx, ts)
+	sysadmin := loginAs(ctx, ts, "system_admin")
+
+	_, err := ts.AuthClient.SetPermissionLevel(admin.Ctx, &pb.SetPermissionLevelRequest{
+		UserId:          user.ID,
+		PermissionLevel: 4,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.SetPermissionLevel(admin.Ctx, &pb.SetPermissionLevelRequest{
+		UserId:          admin.ID,
+		PermissionLevel: 4,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.SetPermissionLevel(admin.Ctx, &pb.SetPermissionLevelRequest{
+		UserId:          user.ID,
+		PermissionLevel: 2,
+	})
+	require.NoError(t, err)
+
+	respLevel, err := ts.AuthClient.GetPermissionLevel(admin.Ctx, &pb.GetPermissionLevelRequest{
+		UserId: user.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), respLevel.GetPermissionLevel())
+
+	_, err = ts.AuthClient.SetPermissionLevel(admin.Ctx, &pb.SetPermissionLevelRequest{
+		UserId:          sysadmin.ID,
+		PermissionLevel: 1,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Roles of those above the admin are not changed either, even roles
+	// the admin could hand out.
+	_, err = ts.AuthClient.AssignRole(admin.Ctx, &pb.AssignRoleRequest{
+		UserId: sysadmin.ID,
+		Role:   "teacher",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.RevokeRole(admin.Ctx, &pb.RevokeRoleRequest{
+		UserId: sysadmin.ID,
+		Role:   "student",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.AssignRole(admin.Ctx, &pb.AssignRoleRequest{
+		UserId: user.ID,
+		Role:   "teacher",
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.ChangeUserStatus(admin.Ctx, &pb.ChangeUserStatusRequest{
+		UserId: sysadmin.ID,
+		Active: false,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.DeleteUser(admin.Ctx, &pb.DeleteUserRequest{
+		UserId: sysadmin.ID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestUserService_DeniesOtherUsers(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	user := loginAs(ctx, ts)
+
+	respOther, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.FillUserProfile(user.Ctx, &pb.FillUserProfileRequest{
+		Name:        gofakeit.FirstName(),
+		Lastname:    gofakeit.LastName(),
+		Middlename:  gofakeit.FirstName(),
+		DateOfBirth: "2010-01-01",
+		Classname:   "5A",
+		UserId:      respOther.GetUserId(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+
+	_, err = ts.AuthClient.GetStudentsByClassname(user.Ctx, &pb.GetStudentsByClassnameRequest{
+		Classname: "5A",
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+
+	_, err = ts.AuthClient.DeleteUser(user.Ctx, &pb.DeleteUserRequest{
+		UserId: respOther.GetUserId(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+func TestScopedRoles_ClassTeacher(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	teacher := loginAs(school, ts, "teacher")
+	own := loginAs(school, ts)
+	other := loginAs(school, ts)
+	staff := loginAs(school, ts)
+
+	createClass(t, ts, admin, 9, "A")
+	createClass(t, ts, admin, 9, "B")
+	fillProfile(t, ts, own, "9A")
+	fillProfile(t, ts, other, "9B")
+	fillProfile(t, ts, staff, "9A")
+
+	_, err := ts.AuthClient.SetPermissionLevel(admin.Ctx, &pb.SetPermissionLevelRequest{
+		UserId:          staff.ID,
+		PermissionLevel: 2,
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.GrantScopedRole(own.Ctx, &pb.GrantScopedRoleRequest{
+		UserId:     own.ID,
+		Role:       "class_teacher",
+		ScopeType:  "class",
+		ScopeValue: "9A",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.AssignRole(admin.Ctx, &pb.AssignRoleRequest{
+		UserId: teacher.ID,
+		Role:   "class_teacher",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = ts.AuthClient.GrantScopedRole(admin.Ctx, &pb.GrantScopedRoleRequest{
+		UserId:     teacher.ID,
+		Role:       "class_teacher",
+		ScopeType:  "class",
+		ScopeValue: "9a",
+	})
+	require.NoError(t, err)
+
+	respRoles, err := ts.AuthClient.ListScopedRoles(teacher.Ctx, &pb.ListScopedRolesRequest{})
+	require.NoError(t, err)
+	require.Len(t, respRoles.GetRoles(), 1)
+	assert.Equal(t, "9A", respRoles.GetRoles()[0].GetScopeValue())
+
+	rename := func(user account) error {
+		_, err := ts.AuthClient.UpdateUserProfile(teacher.Ctx, &pb.UpdateUserProfileRequest{
+			UserId:     user.ID,
+			Profile:    &pb.UserProfile{Name: "Renamed"},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		})
+
+		return err
+	}
+
+	require.NoError(t, rename(own))
+
+	err = rename(other)
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Staff are not covered by scoped roles, even with a class in their
+	// profile.
+	err = rename(staff)
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestClasses_ManageAndEnrol(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	teacher := loginAs(school, ts, "teacher")
+	first := loginAs(school, ts)
+	second := loginAs(school, ts)
+	late := loginAs(school, ts)
+
+	_, err := ts.AuthClient.CreateClass(first.Ctx, &pb.CreateClassRequest{
+		Grade:  9,
+		Letter: "A",
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+
+	respCreate, err := ts.AuthClient.CreateClass(admin.Ctx, &pb.CreateClassRequest{
+		Grade:    9,
+		Letter:   "a",
+		Capacity: 2,
+	})
+	require.NoError(t, err)
+	class := respCreate.GetClass()
+	assert.Equal(t, "9A", class.GetName())
+
+	respUpdate, err := ts.AuthClient.UpdateClass(admin.Ctx, &pb.UpdateClassRequest{
+		ClassId:           class.GetId(),
+		HomeroomTeacherId: teacher.ID,
+		UpdateMask:        &fieldmaskpb.FieldMask{Paths: []string{"homeroom_teacher_id"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, teacher.ID, respUpdate.GetClass().GetHomeroomTeacherId())
+	assert.Equal(t, int64(2), respUpdate.GetClass().GetCapacity())
+
+	// Fields left out keep their values.
+	respUpdate, err = ts.AuthClient.UpdateClass(admin.Ctx, &pb.UpdateClassRequest{
+		ClassId:  class.GetId(),
+		Capacity: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, teacher.ID, respUpdate.GetClass().GetHomeroomTeacherId())
+	assert.Equal(t, int64(3), respUpdate.GetClass().GetCapacity())
+
+	_, err = ts.AuthClient.UpdateClass(admin.Ctx, &pb.UpdateClassRequest{
+		ClassId:  class.GetId(),
+		Capacity: 2,
+	})
+	require.NoError(t, err)
+
+	fillProfile(t, ts, first, "9A")
+	fillProfile(t, ts, second, "9a")
+
+	_, err = ts.AuthClient.FillUserProfile(late.Ctx, &pb.FillUserProfileRequest{
+		Name:        gofakeit.FirstName(),
+		Lastname:    gofakeit.LastName(),
+		Middlename:  gofakeit.FirstName(),
+		DateOfBirth: "2010-01-01",
+		Classname:   "9A",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = ts.AuthClient.UpdateClass(admin.Ctx, &pb.UpdateClassRequest{
+		ClassId:  class.GetId(),
+		Capacity: 1,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	respStudents, err := ts.AuthClient.GetStudentsByClassname(teacher.Ctx, &pb.GetStudentsByClassnameRequest{
+		Classname: "9a",
+	})
+	require.NoError(t, err)
+	assert.Len(t, respStudents.GetStudents(), 2)
+
+	// Students learn nothing about classes, whether they exist or not.
+	for _, classname := range []string{"9A", "0Z"} {
+		_, err = ts.AuthClient.GetStudentsByClassname(first.Ctx, &pb.GetStudentsByClassnameRequest{
+			Classname: classname,
+		})
+		require.Error(t, err)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	respUpdate, err = ts.AuthClient.UpdateClass(admin.Ctx, &pb.UpdateClassRequest{
+		ClassId:    class.GetId(),
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"homeroom_teacher_id"}},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, respUpdate.GetClass().GetHomeroomTeacherId())
+	assert.Equal(t, int64(2), respUpdate.GetClass().GetCapacity())
+}
+
+func TestPromotion_TransferAndPromote(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	teacher := loginAs(school, ts)
+	moved := loginAs(school, ts)
+	stayed := loginAs(school, ts)
+	graduate := loginAs(school, ts)
+
+	createClass(t, ts, admin, 9, "A")
+	createClass(t, ts, admin, 11, "A")
+	respCreate, err := ts.AuthClient.CreateClass(admin.Ctx, &pb.CreateClassRequest{
+		Grade:    9,
+		Letter:   "B",
+		Capacity: 1,
+	})
+	require.NoError(t, err)
+	small := respCreate.GetClass()
+
+	fillProfile(t, ts, moved, "9A")
+	fillProfile(t, ts, stayed, "9A")
+	fillProfile(t, ts, graduate, "11A")
+
+	_, err = ts.AuthClient.ChangeUserStatus(admin.Ctx, &pb.ChangeUserStatusRequest{
+		UserId: graduate.ID,
+		Active: true,
+	})
+	require.NoError(t, err)
+
+	// Level 2 makes the account a teacher instead of a student.
+	_, err = ts.AuthClient.SetPermissionLevel(admin.Ctx, &pb.SetPermissionLevelRequest{
+		UserId:          teacher.ID,
+		PermissionLevel: 2,
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.TransferStudent(admin.Ctx, &pb.TransferStudentRequest{
+		UserId:  teacher.ID,
+		ClassId: small.GetId(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = ts.AuthClient.TransferStudent(moved.Ctx, &pb.TransferStudentRequest{
+		UserId:  moved.ID,
+		ClassId: small.GetId(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.TransferStudent(admin.Ctx, &pb.TransferStudentRequest{
+		UserId:  moved.ID,
+		ClassId: small.GetId(),
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.TransferStudent(admin.Ctx, &pb.TransferStudentRequest{
+		UserId:  stayed.ID,
+		ClassId: small.GetId(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	classNames := func(user account) []string {
+		respHistory, err := ts.AuthClient.GetClassHistory(admin.Ctx, &pb.GetClassHistoryRequest{
+			UserId: user.ID,
+		})
+		require.NoError(t, err)
+
+		var names []string
+		for _, m := range respHistory.GetMemberships() {
+			names = append(names, m.GetClassName())
+		}
+
+		return names
+	}
+
+	assert.Equal(t, []string{"9A", "9B"}, classNames(moved))
+
+	_, err = ts.AuthClient.PromoteAcademicYear(moved.Ctx, &pb.PromoteAcademicYearRequest{
+		DryRun: true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	want := map[int64][2]string{
+		stayed.ID:   {"10A", "promoted"},
+		moved.ID:    {"10B", "promoted"},
+		graduate.ID: {"", "deactivated"},
+	}
+	outcomes := func(resp *pb.PromoteAcademicYearResponse) map[int64][2]string {
+		got := make(map[int64][2]string, len(resp.GetStudents()))
+		for _, st := range resp.GetStudents() {
+			got[st.GetUserId()] = [2]string{st.GetToClass(), st.GetOutcome()}
+		}
+
+		return got
+	}
+
+	respDry, err := ts.AuthClient.PromoteAcademicYear(admin.Ctx, &pb.PromoteAcademicYearRequest{
+		DeactivateGraduates: true,
+		DryRun:              true,
+	})
+	require.NoError(t, err)
+	assert.True(t, respDry.GetDryRun())
+	assert.Equal(t, want, outcomes(respDry))
+
+	// A dry run changes nothing.
+	assert.Equal(t, []string{"9A"}, classNames(stayed))
+
+	respPromote, err := ts.AuthClient.PromoteAcademicYear(admin.Ctx, &pb.PromoteAcademicYearRequest{
+		DeactivateGraduates: true,
+	})
+	require.NoError(t, err)
+	assert.False(t, respPromote.GetDryRun())
+	assert.Equal(t, want, outcomes(respPromote))
+
+	assert.Equal(t, []string{"9A", "10A"}, classNames(stayed))
+	assert.Equal(t, []string{"9A", "9B", "10B"}, classNames(moved))
+	assert.Equal(t, []string{"11A"}, classNames(graduate))
+
+	respActive, err := ts.AuthClient.IsUserActive(admin.Ctx, &pb.IsUserActiveRequest{
+		UserId: graduate.ID,
+	})
+	require.NoError(t, err)
+	assert.False(t, respActive.GetActive())
+
+	respHistory, err := ts.AuthClient.GetClassHistory(admin.Ctx, &pb.GetClassHistoryRequest{
+		UserId: graduate.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, respHistory.GetMemberships(), 1)
+	assert.NotZero(t, respHistory.GetMemberships()[0].GetLeftAt())
+}
+
+func TestGuardians_LinkByInvite(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	student := loginAs(ctx, ts)
+	classmate := loginAs(ctx, ts)
+	guardian := loginAs(ctx, ts, "parent")
+	ts.RevokeRole(guardian.ID, "student")
+
+	_, err := ts.AuthClient.LinkGuardian(student.Ctx, &pb.LinkGuardianRequest{
+		GuardianId: guardian.ID,
+		StudentId:  student.ID,
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+
+	respInvite, err := ts.AuthClient.CreateGuardianInvite(student.Ctx, &pb.CreateGuardianInviteRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, respInvite.GetCode())
+
+	// Students cannot redeem invites, neither their own nor a classmate's.
+	for _, user := range []account{student, classmate} {
+		_, err = ts.AuthClient.LinkGuardian(user.Ctx, &pb.LinkGuardianRequest{
+			InviteCode: respInvite.GetCode(),
+		})
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.ErrorContains(t, err, "invalid guardian link")
+	}
+
+	respLink, err := ts.AuthClient.LinkGuardian(guardian.Ctx, &pb.LinkGuardianRequest{
+		InviteCode:   respInvite.GetCode(),
+		Relationship: "mother",
+		IsPrimary:    true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, student.ID, respLink.GetStudentId())
+
+	_, err = ts.AuthClient.LinkGuardian(guardian.Ctx, &pb.LinkGuardianRequest{
+		InviteCode: respInvite.GetCode(),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid or expired invite code")
+
+	respChildren, err := ts.AuthClient.ListChildren(guardian.Ctx, &pb.ListChildrenRequest{})
+	require.NoError(t, err)
+	require.Len(t, respChildren.GetChildren(), 1)
+	assert.Equal(t, student.ID, respChildren.GetChildren()[0].GetStudentId())
+
+	_, err = ts.AuthClient.ListChildren(classmate.Ctx, &pb.ListChildrenRequest{
+		GuardianId: guardian.ID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	respGuardians, err := ts.AuthClient.ListGuardians(student.Ctx, &pb.ListGuardiansRequest{})
+	require.NoError(t, err)
+	require.Len(t, respGuardians.GetGuardians(), 1)
+	assert.Equal(t, guardian.ID, respGuardians.GetGuardians()[0].GetGuardianId())
+	assert.True(t, respGuardians.GetGuardians()[0].GetIsPrimary())
+}
+
+func TestSchools_TenantInToken(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := ts.AuthClient.Register(metadata.AppendToOutgoingContext(ctx, "x-school-id", "999999999"), &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(respLogin.GetToken(), claims)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, claims["school_id"])
+
+	respIntrospect, err := ts.AuthClient.Introspect(ctx, &pb.IntrospectRequest{
+		Token: respLogin.GetToken(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), respIntrospect.GetSchoolId())
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+respLogin.GetToken())
+
+	_, err = ts.AuthClient.ListSchools(authCtx, &pb.ListSchoolsRequest{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "permission denied")
+}
+
+func TestSchools_AdminsStayInTheirSchool(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	schoolA := ts.NewSchool(ctx)
+	schoolB := ts.NewSchool(ctx)
+	adminA := loginAs(schoolA, ts, "school_admin")
+	adminB := loginAs(schoolB, ts, "school_admin")
+	userB := loginAs(schoolB, ts)
+	teacherB := loginAs(schoolB, ts, "teacher")
+
+	_, err := ts.AuthClient.GetUserProfile(adminB.Ctx, &pb.GetUserProfileRequest{
+		UserId: userB.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = ts.AuthClient.GetUserProfile(adminA.Ctx, &pb.GetUserProfileRequest{
+		UserId: userB.ID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ts.AuthClient.UpdateUserProfile(adminA.Ctx, &pb.UpdateUserProfileRequest{
+		UserId:     userB.ID,
+		Profile:    &pb.UserProfile{Name: "Renamed"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ts.AuthClient.ChangeUserStatus(adminA.Ctx, &pb.ChangeUserStatusRequest{
+		UserId: userB.ID,
+		Active: true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ts.AuthClient.DeleteUser(adminA.Ctx, &pb.DeleteUserRequest{
+		UserId: userB.ID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ts.AuthClient.CreateClass(adminA.Ctx, &pb.CreateClassRequest{
+		Grade:             9,
+		Letter:            "A",
+		HomeroomTeacherId: teacherB.ID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = ts.AuthClient.ListSessions(adminA.Ctx, &pb.ListSessionsRequest{
+		Token:  adminA.Token,
+		UserId: userB.ID,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	respSessions, err := ts.AuthClient.ListSessions(adminB.Ctx, &pb.ListSessionsRequest{
+		Token:  adminB.Token,
+		UserId: userB.ID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, respSessions.GetSessions())
+
+	_, err = ts.AuthClient.TerminateSession(adminA.Ctx, &pb.TerminateSessionRequest{
+		Token:     adminA.Token,
+		SessionId: respSessions.GetSessions()[0].GetSessionId(),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// The account of userB is untouched.
+	respProfile, err := ts.AuthClient.GetUserProfile(adminB.Ctx, &pb.GetUserProfileRequest{
+		UserId: userB.ID,
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, "Renamed", respProfile.GetProfile().GetName())
+
+	_, err = ts.AuthClient.Validate(ctx, &pb.ValidateRequest{
+		Token: userB.Token,
+	})
+	require.NoError(t, err)
+}
+
+func TestBulkImportUsers_DryRunAndCreate(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	user := loginAs(school, ts)
+	createClass(t, ts, admin, 5, "A")
+
+	importUsers := func(user account, requests ...*pb.BulkImportUsersRequest) (*pb.BulkImportUsersResponse, error) {
+		stream, err := ts.AuthClient.BulkImportUsers(user.Ctx)
+		require.NoError(t, err)
+
+		// The server may refuse the stream before reading it, so the error
+		// of Send is left to CloseAndRecv.
+		for _, req := range requests {
+			_ = stream.Send(req)
+		}
+
+		return stream.CloseAndRecv()
+	}
+
+	valid, invalid := gofakeit.Email(), gofakeit.Email()
+	file := &pb.BulkImportUsersRequest{
+		Format: "csv",
+		DryRun: true,
+		Data: []byte("email,name,lastname,class,date of birth\n" +
+			valid + ",Ann,Smith,5a,2010-09-01\n" +
+			invalid + ",Bob,Jones,5A,2.1.2006\n"),
+	}
+
+	_, err := importUsers(user, file)
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	respDry, err := importUsers(admin, file)
+	require.NoError(t, err)
+	assert.True(t, respDry.GetDryRun())
+	assert.Zero(t, respDry.GetCreated())
+	assert.Equal(t, int64(1), respDry.GetInvalid())
+	require.Len(t, respDry.GetResults(), 2)
+	assert.Equal(t, "valid", respDry.GetResults()[0].GetStatus())
+	assert.Equal(t, int64(3), respDry.GetResults()[1].GetLine())
+	assert.Equal(t, []string{"date of birth must be a date such as 2010-09-01"}, respDry.GetResults()[1].GetErrors())
+
+	respImport, err := importUsers(admin, &pb.BulkImportUsersRequest{
+		Rows: []*pb.ImportRow{
+			{Line: 1, Email: valid, Name: "Ann", Lastname: "Smith", Classname: "5A"},
+			{Line: 2, Email: gofakeit.Email(), Name: "Carl", Lastname: "Brown", Role: "class_teacher"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), respImport.GetCreated())
+	require.Len(t, respImport.GetResults(), 2)
+	assert.Equal(t, "created", respImport.GetResults()[0].GetStatus())
+	assert.Equal(t, "invalid", respImport.GetResults()[1].GetStatus())
+
+	respProfile, err := ts.AuthClient.GetUserProfile(admin.Ctx, &pb.GetUserProfileRequest{
+		UserId: respImport.GetResults()[0].GetUserId(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "5A", respProfile.GetProfile().GetClassname())
+
+	// Rows are counted as they arrive, across messages.
+	chunk := make([]*pb.ImportRow, imports.MaxRows/2+1)
+	for i := range chunk {
+		chunk[i] = &pb.ImportRow{Email: gofakeit.Email()}
+	}
+	_, err = importUsers(admin,
+		&pb.BulkImportUsersRequest{DryRun: true, Rows: chunk},
+		&pb.BulkImportUsersRequest{Rows: chunk},
+	)
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListUsers_PagesThroughEverySortOrder(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	user := loginAs(school, ts)
+
+	_, err := ts.AuthClient.ListUsers(user.Ctx, &pb.ListUsersRequest{EmailPrefix: user.Email})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.ListUsers(admin.Ctx, &pb.ListUsersRequest{Status: "pending"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Repeated and missing lastnames, and accounts created within the same
+	// second, make the sort keys tie; pages must still neither repeat nor
+	// skip users.
+	want := map[int64]bool{admin.ID: true, user.ID: true}
+	for i, lastname := range []string{"Smith", "Smith", "Smith", "Adams", "Adams", "", ""} {
+		u := loginAs(school, ts)
+		want[u.ID] = true
+		if lastname == "" {
+			continue
+		}
+
+		_, err = ts.AuthClient.UpdateUserProfile(admin.Ctx, &pb.UpdateUserProfileRequest{
+			UserId:     u.ID,
+			Profile:    &pb.UserProfile{Lastname: lastname},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"lastname"}},
+		})
+		require.NoError(t, err, "user %d", i)
+	}
+
+	for _, order := range []string{"lastname", "email", "created_at", "id"} {
+		for _, orderBy := range []string{order, "-" + order} {
+			respAll, err := ts.AuthClient.ListUsers(admin.Ctx, &pb.ListUsersRequest{
+				OrderBy:  orderBy,
+				PageSize: 500,
+			})
+			require.NoError(t, err, orderBy)
+			require.Empty(t, respAll.GetNextPageToken(), orderBy)
+			assert.Equal(t, int64(len(want)), respAll.GetTotalCount(), orderBy)
+
+			var all []int64
+			for _, u := range respAll.GetUsers() {
+				all = append(all, u.GetUserId())
+			}
+
+			var (
+				paged []int64
+				seen  = make(map[int64]bool)
+				token string
+			)
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, len(want), "%s: too many pages", orderBy)
+
+				resp, err := ts.AuthClient.ListUsers(admin.Ctx, &pb.ListUsersRequest{
+					OrderBy:   orderBy,
+					PageSize:  2,
+					PageToken: token,
+				})
+				require.NoError(t, err, orderBy)
+				assert.Equal(t, int64(len(want)), resp.GetTotalCount(), orderBy)
+
+				for _, u := range resp.GetUsers() {
+					assert.False(t, seen[u.GetUserId()], "%s: user %d repeats", orderBy, u.GetUserId())
+					seen[u.GetUserId()] = true
+					paged = append(paged, u.GetUserId())
+				}
+
+				token = resp.GetNextPageToken()
+				if token == "" {
+					break
+				}
+			}
+
+			assert.Equal(t, all, paged, orderBy)
+			assert.Len(t, seen, len(want), orderBy)
+			for id := range want {
+				assert.True(t, seen[id], "%s: user %d is missing", orderBy, id)
+			}
+		}
+	}
+}
+
+func TestExportUsers_ClassList(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	student := loginAs(school, ts)
+	classmate := loginAs(school, ts)
+	createClass(t, ts, admin, 5, "A")
+	fillProfile(t, ts, student, "5A")
+	fillProfile(t, ts, classmate, "5A")
+
+	export := func(user account, req *pb.ExportUsersRequest) (string, int64, error) {
+		stream, err := ts.AuthClient.ExportUsers(user.Ctx, req)
+		require.NoError(t, err)
+
+		var (
+			data strings.Builder
+			rows int64
+		)
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return data.String(), rows, nil
+			}
+			if err != nil {
+				return "", 0, err
+			}
+			data.Write(resp.GetData())
+			rows = resp.GetRows()
+		}
+	}
+
+	_, _, err := export(student, &pb.ExportUsersRequest{Format: "csv", Classname: "5A"})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	data, rows, err := export(admin, &pb.ExportUsersRequest{Format: "csv", Classname: "5a"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "\ufeffid,lastname,name,middlename,date_of_birth,class,email", lines[0])
+	assert.Contains(t, data, student.Email)
+	assert.Contains(t, data, classmate.Email)
+
+	data, rows, err = export(admin, &pb.ExportUsersRequest{
+		Format:    "jsonl",
+		Classname: "5A",
+		Columns:   []string{"email", "class"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rows)
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		assert.Regexp(t, `^\{"email":"[^"]+","class":"5A"\}$`, line)
+	}
+}
+
+func TestUpdateUserProfile_PartialUpdate(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	user := loginAs(ctx, ts)
+
+	respOther, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	respGet, err := ts.AuthClient.GetUserProfile(user.Ctx, &pb.GetUserProfileRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, respGet.GetProfile().GetEmail())
+
+	_, err = ts.AuthClient.GetUserProfile(user.Ctx, &pb.GetUserProfileRequest{UserId: respOther.GetUserId()})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	respUpdate, err := ts.AuthClient.UpdateUserProfile(user.Ctx, &pb.UpdateUserProfileRequest{
+		Profile:    &pb.UserProfile{Name: "Anna", Lastname: "ignored"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Anna", respUpdate.GetProfile().GetName())
+	assert.Equal(t, respGet.GetProfile().GetLastname(), respUpdate.GetProfile().GetLastname())
+
+	_, err = ts.AuthClient.UpdateUserProfile(user.Ctx, &pb.UpdateUserProfileRequest{
+		Profile:    &pb.UserProfile{DateOfBirth: "2010-02-30"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"date_of_birth"}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = ts.AuthClient.UpdateUserProfile(user.Ctx, &pb.UpdateUserProfileRequest{
+		Profile:      &pb.UserProfile{Name: "Anna"},
+		UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		OverrideLock: true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestUpdateUserProfile_ReadLockAndMove(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	teacher := loginAs(school, ts, "teacher")
+	student := loginAs(school, ts)
+	classmate := loginAs(school, ts)
+
+	own := createClass(t, ts, admin, 7, "A")
+	other := createClass(t, ts, admin, 7, "B")
+	_, err := ts.AuthClient.CreateClass(admin.Ctx, &pb.CreateClassRequest{
+		Grade:    7,
+		Letter:   "C",
+		Capacity: 1,
+	})
+	require.NoError(t, err)
+	fillProfile(t, ts, student, "7A")
+	fillProfile(t, ts, classmate, "7C")
+
+	_, err = ts.AuthClient.GrantScopedRole(admin.Ctx, &pb.GrantScopedRoleRequest{
+		UserId:     teacher.ID,
+		Role:       "class_teacher",
+		ScopeType:  "class",
+		ScopeValue: "7A",
+	})
+	require.NoError(t, err)
+
+	respGet, err := ts.AuthClient.GetUserProfile(admin.Ctx, &pb.GetUserProfileRequest{UserId: student.ID})
+	require.NoError(t, err)
+	assert.Equal(t, student.Email, respGet.GetProfile().GetEmail())
+	assert.Equal(t, own.GetId(), respGet.GetProfile().GetClassId())
+
+	// Whether a user exists is only told to those who may read it.
+	const unknownID = 1 << 40
+	_, err = ts.AuthClient.GetUserProfile(student.Ctx, &pb.GetUserProfileRequest{UserId: unknownID})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.AuthClient.GetUserProfile(admin.Ctx, &pb.GetUserProfileRequest{UserId: unknownID})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	move := func(initiator account, classname string) (*pb.UpdateUserProfileResponse, error) {
+		return ts.AuthClient.UpdateUserProfile(initiator.Ctx, &pb.UpdateUserProfileRequest{
+			UserId:     student.ID,
+			Profile:    &pb.UserProfile{Classname: classname},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"classname"}},
+		})
+	}
+
+	// The class teacher edits the profile but may not move the student out
+	// of their class, and neither may the student.
+	_, err = ts.AuthClient.UpdateUserProfile(teacher.Ctx, &pb.UpdateUserProfileRequest{
+		UserId:     student.ID,
+		Profile:    &pb.UserProfile{Middlename: "Petrovna"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"middlename"}},
+	})
+	require.NoError(t, err)
+
+	for _, initiator := range []account{teacher, student} {
+		_, err = move(initiator, "7B")
+		require.Error(t, err)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	_, err = move(admin, "7C")
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	respMove, err := move(admin, "7b")
+	require.NoError(t, err)
+	assert.Equal(t, "7B", respMove.GetProfile().GetClassname())
+	assert.Equal(t, other.GetId(), respMove.GetProfile().GetClassId())
+	assert.Equal(t, "Petrovna", respMove.GetProfile().GetMiddlename())
+
+	respHistory, err := ts.AuthClient.GetClassHistory(admin.Ctx, &pb.GetClassHistoryRequest{UserId: student.ID})
+	require.NoError(t, err)
+	require.Len(t, respHistory.GetMemberships(), 2)
+
+	// A locked profile is only changed with override_lock.
+	_, err = ts.AuthClient.LockUserProfile(admin.Ctx, &pb.LockUserProfileRequest{UserId: student.ID, Locked: true})
+	require.NoError(t, err)
+
+	rename := &pb.UpdateUserProfileRequest{
+		UserId:     student.ID,
+		Profile:    &pb.UserProfile{Name: "Renamed"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	}
+	_, err = ts.AuthClient.UpdateUserProfile(student.Ctx, rename)
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	rename.OverrideLock = true
+	respRename, err := ts.AuthClient.UpdateUserProfile(admin.Ctx, rename)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", respRename.GetProfile().GetName())
+	assert.True(t, respRename.GetProfile().GetProfileLocked())
+
+	// override_lock is neither for one's own profile nor for a higher rank.
+	sysadmin := loginAs(school, ts, "system_admin")
+	for _, target := range []account{admin, sysadmin} {
+		rename.UserId = target.ID
+		_, err = ts.AuthClient.UpdateUserProfile(admin.Ctx, rename)
+		require.Error(t, err)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	// Without users.read, unknown users look like those one may not edit.
+	_, err = ts.AuthClient.UpdateUserProfile(student.Ctx, &pb.UpdateUserProfileRequest{
+		UserId:     student.ID + 1_000_000,
+		Profile:    &pb.UserProfile{Name: "Renamed"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestBirthDate_TypedAndChecked(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	user := loginAs(ctx, ts)
+
+	respUpdate, err := ts.AuthClient.UpdateUserProfile(user.Ctx, &pb.UpdateUserProfileRequest{
+		Profile:    &pb.UserProfile{BirthDate: &date.Date{Year: 2012, Month: 2, Day: 29}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"birth_date"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2012-02-29", respUpdate.GetProfile().GetDateOfBirth())
+
+	for _, d := range []*date.Date{{Year: 2011, Month: 2, Day: 29}, {Year: 2010, Month: 13, Day: 1}, {Year: 3000, Month: 1, Day: 1}} {
+		_, err = ts.AuthClient.UpdateUserProfile(user.Ctx, &pb.UpdateUserProfileRequest{
+			Profile:    &pb.UserProfile{BirthDate: d},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"birth_date"}},
+		})
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = ts.AuthClient.SetParentalConsent(user.Ctx, &pb.SetParentalConsentRequest{
+		StudentId: user.ID,
+		Consent:   true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestActivation_YoungStudentNeedsConsent(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	age := ts.Cfg.GuardianConsentAge
+	if age <= 0 {
+		t.Skip("GUARDIAN_CONSENT_AGE is not set")
+	}
+
+	school := ts.NewSchool(ctx)
+	admin := loginAs(school, ts, "school_admin")
+	student := loginAs(school, ts)
+	older := loginAs(school, ts)
+	parent := loginAs(school, ts)
+	ts.RevokeRole(parent.ID, "student")
+
+	createClass(t, ts, admin, 5, "A")
+	young := time.Now().AddDate(-age+1, 0, 0).Format("2006-01-02")
+	profiles := []struct {
+		user        account
+		dateOfBirth string
+	}{
+		{user: student, dateOfBirth: young},
+		{user: older, dateOfBirth: time.Now().AddDate(-age-1, 0, 0).Format("2006-01-02")},
+	}
+	for _, p := range profiles {
+		_, err := ts.AuthClient.FillUserProfile(p.user.Ctx, &pb.FillUserProfileRequest{
+			Name:        gofakeit.FirstName(),
+			Lastname:    gofakeit.LastName(),
+			DateOfBirth: p.dateOfBirth,
+			Classname:   "5A",
+		})
+		require.NoError(t, err)
+	}
+
+	activate := func(user account, active bool) error {
+		_, err := ts.AuthClient.ChangeUserStatus(admin.Ctx, &pb.ChangeUserStatusRequest{
+			UserId: user.ID,
+			Active: active,
+		})
+
+		return err
+	}
+	isActive := func(user account) bool {
+		resp, err := ts.AuthClient.IsUserActive(admin.Ctx, &pb.IsUserActiveRequest{UserId: user.ID})
+		require.NoError(t, err)
+
+		return resp.GetActive()
+	}
+	requireBlocked := func(err error) {
+		t.Helper()
+
+		require.Error(t, err)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.ErrorContains(t, err, "guardian_consent")
+	}
+
+	requireBlocked(activate(student, true))
+
+	// A guardian linked through an invite of the student is not consent
+	// until an administrator approves the link.
+	respInvite, err := ts.AuthClient.CreateGuardianInvite(student.Ctx, &pb.CreateGuardianInviteRequest{})
+	require.NoError(t, err)
+	_, err = ts.AuthClient.LinkGuardian(parent.Ctx, &pb.LinkGuardianRequest{InviteCode: respInvite.GetCode()})
+	require.NoError(t, err)
+
+	requireBlocked(activate(student, true))
+
+	_, err = ts.AuthClient.LinkGuardian(admin.Ctx, &pb.LinkGuardianRequest{
+		GuardianId: parent.ID,
+		StudentId:  student.ID,
+	})
+	require.NoError(t, err)
+
+	respGuardians, err := ts.AuthClient.ListGuardians(admin.Ctx, &pb.ListGuardiansRequest{StudentId: student.ID})
+	require.NoError(t, err)
+	require.Len(t, respGuardians.GetGuardians(), 1)
+	assert.NotZero(t, respGuardians.GetGuardians()[0].GetApprovedAt())
+
+	require.NoError(t, activate(student, true))
+
+	// Removing the only consent deactivates the student.
+	_, err = ts.AuthClient.UnlinkGuardian(parent.Ctx, &pb.UnlinkGuardianRequest{StudentId: student.ID})
+	require.NoError(t, err)
+	assert.False(t, isActive(student))
+
+	_, err = ts.AuthClient.SetParentalConsent(admin.Ctx, &pb.SetParentalConsentRequest{StudentId: student.ID, Consent: true})
+	require.NoError(t, err)
+	require.NoError(t, activate(student, true))
+
+	_, err = ts.AuthClient.SetParentalConsent(admin.Ctx, &pb.SetParentalConsentRequest{StudentId: student.ID})
+	require.NoError(t, err)
+	assert.False(t, isActive(student))
+
+	_, err = ts.AuthClient.SetParentalConsent(admin.Ctx, &pb.SetParentalConsentRequest{StudentId: 1 << 40, Consent: true})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// An active account keeps meeting the requirements.
+	require.NoError(t, activate(older, true))
+	_, err = ts.AuthClient.UpdateUserProfile(admin.Ctx, &pb.UpdateUserProfileRequest{
+		UserId:     older.ID,
+		Profile:    &pb.UserProfile{DateOfBirth: young},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"date_of_birth"}},
+	})
+	requireBlocked(err)
+	assert.True(t, isActive(older))
+}
+
+func TestValidate_FailCases(t *testing.T) {
+	ctx, ts := testsuite.New(t)
+
+	token := jwt.New(jwt.SigningMethodHS256)
+
+	claims := token.Claims.(jwt.MapClaims)
+	claims["uid"] = gofakeit.Uint64()
+	claims["email"] = gofakeit.Email()
+	claims["jti"] = gofakeit.UUID()
+	claims["iat"] = time.Now().Local().Add(-2 * time.Hour).Unix()
+	claims["exp"] = time.Now().Local().Add(-1 * time.Hour).Unix()
+	claims["iss"] = ts.Cfg.JWTIssuer
+	claims["aud"] = ts.Cfg.JWTAudience
+	tokenString, _ := token.SignedString([]byte(ts.Cfg.JWTSecretKey))
+
+	foreign := jwt.New(jwt.SigningMethodHS256)
+	foreignClaims := foreign.Claims.(jwt.MapClaims)
+	foreignClaims["uid"] = gofakeit.Uint64()
+	foreignClaims["email"] = gofakeit.Email()
+	foreignClaims["exp"] = time.Now().Local().Add(time.Hour).Unix()
+	foreignClaims["iss"] = "someone-else"
+	foreignString, _ := foreign.SignedString([]byte(ts.Cfg.JWTSecretKey))
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr string
+	}{
+		{
+			name:        "Login with Empty token",
+			token:       "",
+			expectedErr: "token is required",
+		},
+		{
+			name:        "Login with Wrong token",
+			token:       "example wrong jwt",
+			expectedErr: "invalid JWT",
+		},
+		{
+			name:        "Login with Expired token",
+			token:       tokenString,
+			expectedErr: "invalid JWT: token is expired",
+		},
+		{
+			name:        "Login with Foreign Issuer token",
+			token:       foreignString,
+			expectedErr: "invalid JWT: issuer is not trusted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ts.AuthClient.Validate(ctx, &pb.ValidateRequest{
+				Token: tt.token,
+			})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.expectedErr)
+		})
+	}
+}
+func randomFakePassword() string {
+	return gofakeit.Password(true, true, true, true, false, 10)
+}
+
+// createClass creates a class of the current school year as admin.
+func createClass(t *testing.T, ts *testsuite.Suite, admin account, grade int64, letter string) *pb.Class {
+	t.Helper()
+
+	resp, err := ts.AuthClient.CreateClass(admin.Ctx, &pb.CreateClassRequest{
+		Grade:  grade,
+		Letter: letter,
+	})
+	require.NoError(t, err)
+
+	return resp.GetClass()
+}
+
+// fillProfile fills the profile of user, putting them in classname.
+func fillProfile(t *testing.T, ts *testsuite.Suite, user account, classname string) {
+	t.Helper()
+
+	_, err := ts.AuthClient.FillUserProfile(user.Ctx, &pb.FillUserProfileRequest{
+		Name:        gofakeit.FirstName(),
+		Lastname:    gofakeit.LastName(),
+		Middlename:  gofakeit.FirstName(),
+		DateOfBirth: "2010-01-01",
+		Classname:   classname,
+	})
+	require.NoError(t, err)
+}
+
+// account is a registered user logged in for a test.
+type account struct {
+	ID    int64
+	Email string
+	Token string
+	// Ctx carries the access token of the account.
+	Ctx context.Context
+}
+
+// loginAs registers a new account with the given roles on top of the
+// student role every account starts with, and logs it in.
+func loginAs(ctx context.Context, ts *testsuite.Suite, roles ...string) account {
+	ts.Helper()
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	respReg, err := ts.AuthClient.Register(ctx, &pb.RegisterRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(ts, err)
+
+	for _, role := range roles {
+		ts.GrantRole(respReg.GetUserId(), role)
+	}
+
+	respLogin, err := ts.AuthClient.Login(ctx, &pb.LoginRequest{
+		Email:    email,
+		Password: pass,
+	})
+	require.NoError(ts, err)
+
+	return account{
+		ID:    respReg.GetUserId(),
+		Email: email,
+		Token: respLogin.GetToken(),
+		Ctx:   metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+respLogin.GetToken()),
+	}
 }
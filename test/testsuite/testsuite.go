@@ -4,19 +4,27 @@ import (
 	"AuthService/internal/config"
 	"AuthService/internal/pb"
 	"context"
+	"database/sql"
 	"net"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/brianvoe/gofakeit"
+
+	_ "github.com/go-sql-driver/mysql"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
 type Suite struct {
 	*testing.T
 	Cfg        *config.Config
 	AuthClient pb.UserServiceClient
+	// DB is the database of the service, for setup the API cannot do
+	// itself, such as appointing the first administrator.
+	DB *sql.DB
 }
 
 func New(t *testing.T) (context.Context, *Suite) {
@@ -43,13 +51,79 @@ func New(t *testing.T) (context.Context, *Suite) {
 		t.Fatalf("grpc server connection failed: %v", err)
 	}
 
+	db, err := sql.Open("mysql", cfg.DBUrl)
+	if err != nil {
+		t.Fatalf("db connection failed: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
 	return ctx, &Suite{
 		T:          t,
 		Cfg:        cfg,
 		AuthClient: pb.NewUserServiceClient(client),
+		DB:         db,
+	}
+}
+
+// GrantRole gives userID a global role without going through the API, and
+// raises the legacy permission level as the service would.
+func (s *Suite) GrantRole(userID int64, role string) {
+	s.Helper()
+
+	if _, err := s.DB.Exec("INSERT IGNORE INTO user_roles(user_id, role) VALUES(?, ?)", userID, role); err != nil {
+		s.Fatalf("grant role failed: %v", err)
+	}
+
+	s.syncPermissionLevel(userID)
+}
+
+// RevokeRole takes a global role from userID without going through the API,
+// such as the student role of an account meant for a parent.
+func (s *Suite) RevokeRole(userID int64, role string) {
+	s.Helper()
+
+	if _, err := s.DB.Exec("DELETE FROM user_roles WHERE user_id = ? AND role = ?", userID, role); err != nil {
+		s.Fatalf("revoke role failed: %v", err)
+	}
+
+	s.syncPermissionLevel(userID)
+}
+
+// syncPermissionLevel sets the legacy permission level of userID to the
+// highest level of their roles.
+func (s *Suite) syncPermissionLevel(userID int64) {
+	s.Helper()
+
+	_, err := s.DB.Exec(
+		"UPDATE users SET permission_level = COALESCE((SELECT MAX(r.level) FROM user_roles ur JOIN roles r ON r.name = ur.role WHERE ur.user_id = ?), 1) WHERE id = ?",
+		userID, userID,
+	)
+	if err != nil {
+		s.Fatalf("update permission level failed: %v", err)
 	}
 }
 
 func grpcAddress(cfg *config.Config) string {
 	return net.JoinHostPort("localhost", strconv.Itoa(cfg.Port))
 }
+
+// NewSchool creates an empty school and returns ctx naming it in the
+// x-school-id metadata, so that accounts registered with it and the classes
+// they create do not meet those of other tests.
+func (s *Suite) NewSchool(ctx context.Context) context.Context {
+	s.Helper()
+
+	res, err := s.DB.Exec("INSERT INTO schools(name) VALUES(?)", "Test school "+gofakeit.UUID())
+	if err != nil {
+		s.Fatalf("create school failed: %v", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		s.Fatalf("create school failed: %v", err)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "x-school-id", strconv.FormatInt(id, 10))
+}